@@ -0,0 +1,104 @@
+package diff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/diff"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+	"github.com/DataDog/datadog-sbom-generator/pkg/reporter"
+	"github.com/DataDog/datadog-sbom-generator/pkg/scanner"
+	"github.com/urfave/cli/v2"
+)
+
+var formats = []string{"text", "json"}
+
+func Command(stdout, stderr io.Writer, r *reporter.Reporter) *cli.Command {
+	return &cli.Command{
+		Name:        "diff",
+		Usage:       "compares the components of two SBOMs produced by this tool, or of two git revisions",
+		Description: "reports the components added, removed, upgraded or downgraded between two CycloneDX or JSON outputs of this tool, as well as the changes of their direct/dev flags and manifest locations. With --repository, the given git revisions of the repository are scanned and compared instead.",
+		ArgsUsage:   "<before> <after>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Usage:   "sets the output format; value can be: " + strings.Join(formats, ", "),
+				Value:   "text",
+				Action: func(context *cli.Context, s string) error {
+					if slices.Contains(formats, s) {
+						return nil
+					}
+
+					return fmt.Errorf("unsupported output format \"%s\" - must be one of: %s", s, strings.Join(formats, ", "))
+				},
+			},
+			&cli.StringFlag{
+				Name:      "repository",
+				Usage:     "compares the given git revisions (commits, branches or tags) of this repository instead of SBOM files",
+				TakesFile: true,
+			},
+			&cli.StringSliceFlag{
+				Name:  "enable-parsers",
+				Usage: "Explicitly define which lockfile to parse when scanning git revisions. If set, any non-set parsers will be ignored.",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			*r = reporter.NewJSONReporter(stdout, stderr, reporter.ErrorLevel)
+
+			return action(c, stdout, *r)
+		},
+	}
+}
+
+func action(context *cli.Context, stdout io.Writer, r reporter.Reporter) error {
+	if context.NArg() != 2 {
+		return fmt.Errorf("expected two SBOMs or git revisions to compare, got %d arguments", context.NArg())
+	}
+
+	before, err := load(context, context.Args().Get(0), r)
+	if err != nil {
+		return err
+	}
+	after, err := load(context, context.Args().Get(1), r)
+	if err != nil {
+		return err
+	}
+
+	report := diff.Compare(before, after)
+
+	if context.String("format") == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(report)
+	}
+
+	_, err = fmt.Fprint(stdout, report.String())
+
+	return err
+}
+
+// load returns the packages of the given SBOM, or of the given git revision when a repository is set
+func load(context *cli.Context, source string, r reporter.Reporter) (map[string]models.PackageVulns, error) {
+	repository := context.String("repository")
+	if repository == "" {
+		return diff.Load(source)
+	}
+
+	results, err := scanner.DoScan(scanner.ScannerActions{
+		DirectoryPaths: []string{repository},
+		Recursive:      true,
+		GitRef:         source,
+		EnableParsers:  context.StringSlice("enable-parsers"),
+	}, r)
+	if err != nil && !errors.Is(err, scanner.NoPackagesFoundErr) {
+		return nil, err
+	}
+
+	return diff.FromResults(results), nil
+}
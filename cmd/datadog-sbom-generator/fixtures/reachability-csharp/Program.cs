@@ -0,0 +1,15 @@
+using System;
+using Newtonsoft.Json;
+
+namespace Example
+{
+    public static class Program
+    {
+        public static void Main(string[] args)
+        {
+            var settings = new JsonSerializerSettings { TypeNameHandling = TypeNameHandling.All };
+            var value = JsonConvert.DeserializeObject(args[0], settings);
+            Console.WriteLine(value);
+        }
+    }
+}
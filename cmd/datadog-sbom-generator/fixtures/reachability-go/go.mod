@@ -0,0 +1,8 @@
+module example.com/reachability
+
+go 1.21.0
+
+require (
+	golang.org/x/net v0.17.0
+	golang.org/x/text v0.13.0
+)
@@ -0,0 +1,10 @@
+package com.sample;
+
+import org.example.Greeter;
+
+public class ExampleApp {
+  public static void main(String[] args) {
+    Greeter greeter = new Greeter("Daniel");
+    greeter.sayHello();
+  }
+}
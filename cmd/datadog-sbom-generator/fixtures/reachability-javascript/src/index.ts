@@ -0,0 +1,5 @@
+import { template } from "lodash";
+
+export function render(source: string, data: object): string {
+  return template(source)(data);
+}
@@ -0,0 +1,10 @@
+package com.sample
+
+import java.io.File
+import org.yaml.snakeyaml.Yaml as SnakeYaml
+
+fun loadSettings(path: String): Map<String, Any> {
+    val yaml = SnakeYaml()
+
+    return yaml.load(File(path).readText())
+}
@@ -0,0 +1,8 @@
+package com.sample
+
+import org.apache.commons.text.StringSubstitutor
+
+object Report {
+  def render(template: String): String =
+    StringSubstitutor.createInterpolator().replace(template)
+}
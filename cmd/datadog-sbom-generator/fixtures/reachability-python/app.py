@@ -0,0 +1,11 @@
+import yaml
+import requests
+
+
+def load_config(path):
+    with open(path) as config:
+        return yaml.load(config, Loader=yaml.FullLoader)
+
+
+def fetch(url):
+    return requests.get(url, timeout=10)
@@ -0,0 +1,8 @@
+require "nokogiri"
+
+class Importer
+  def import(xml)
+    document = Nokogiri::XML::Document.parse(xml)
+    document.xpath("//item").map(&:text)
+  end
+end
@@ -0,0 +1,5 @@
+const qs = require("qs");
+
+module.exports = function parseQuery(search) {
+  return qs.parse(search);
+};
@@ -0,0 +1,6 @@
+const qs = require("qs");
+const parseQuery = require("./query");
+
+test("parses nested parameters", () => {
+  expect(parseQuery("a[b]=c")).toEqual(qs.parse("a[b]=c"));
+});
@@ -8,6 +8,7 @@ import (
 
 	"github.com/DataDog/datadog-sbom-generator/pkg/scanner"
 
+	"github.com/DataDog/datadog-sbom-generator/cmd/datadog-sbom-generator/diff"
 	"github.com/DataDog/datadog-sbom-generator/cmd/datadog-sbom-generator/scan"
 	"github.com/DataDog/datadog-sbom-generator/pkg/reporter"
 
@@ -38,6 +39,7 @@ func run(args []string, stdout, stderr io.Writer) int {
 		DefaultCommand: "scan",
 		Commands: []*cli.Command{
 			scan.Command(stdout, stderr, &r),
+			diff.Command(stdout, stderr, &r),
 		},
 	}
 
@@ -49,7 +51,7 @@ func run(args []string, stdout, stderr io.Writer) int {
 		}
 		switch {
 		case errors.Is(err, scanner.VulnerabilitiesFoundErr):
-			return 0
+			return 1
 		case errors.Is(err, scanner.NoPackagesFoundErr):
 			r.Errorf("No package sources found, --help for usage information.\n")
 			return 0
@@ -15,68 +15,139 @@ import (
 	"github.com/urfave/cli/v2"
 )
 
+// scanMode defines how the arguments of the command are scanned
+type scanMode int
+
+const (
+	// directoryMode scans the arguments as source code directories
+	directoryMode scanMode = iota
+	// rootFSMode scans the arguments as root filesystems of container images
+	rootFSMode
+	// imageMode scans the arguments as container images stored as archives or OCI image layouts
+	imageMode
+)
+
 func Command(stdout, stderr io.Writer, r *reporter.Reporter) *cli.Command {
 	return &cli.Command{
 		Name:        "scan",
 		Usage:       "scans various package managers for dependencies and produce an SBOM",
 		Description: "scans various package managers for dependencies and produce an SBOM",
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:    "format",
-				Aliases: []string{"f"},
-				Usage:   "sets the output format; value can be: " + strings.Join(reporter.Format(), ", "),
-				Value:   "cyclonedx-1-5",
-				Action: func(context *cli.Context, s string) error {
-					if slices.Contains(reporter.Format(), s) {
-						return nil
-					}
-
-					return fmt.Errorf("unsupported output format \"%s\" - must be one of: %s", s, strings.Join(reporter.Format(), ", "))
+		Flags:       flags(),
+		ArgsUsage:   "[directory1 directory2...]",
+		Action: func(c *cli.Context) error {
+			var err error
+			*r, err = action(c, stdout, stderr, directoryMode)
+
+			return err
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:        "rootfs",
+				Usage:       "scans the root filesystem of a container image, including the packages installed by the distribution",
+				Description: "scans a directory as the root filesystem of a container image: OS packages (dpkg, apk) are reported next to the language packages found in the same tree",
+				Flags:       flags(),
+				ArgsUsage:   "[directory1 directory2...]",
+				Action: func(c *cli.Context) error {
+					var err error
+					*r, err = action(c, stdout, stderr, rootFSMode)
+
+					return err
 				},
 			},
-			&cli.StringFlag{
-				Name:      "output",
-				Aliases:   []string{"o"},
-				Usage:     "saves the result to the given file path",
-				TakesFile: true,
-			},
-			&cli.BoolFlag{
-				Name:  "not-recursive",
-				Usage: "do not check subdirectories",
-				Value: false,
-			},
-			&cli.BoolFlag{
-				Name:  "no-ignore",
-				Usage: "also scan files that would be ignored by .gitignore",
-				Value: false,
-			},
-			&cli.BoolFlag{
-				Name:  "reachability",
-				Usage: "enable reachability analysis",
-				Value: false,
-			},
-			&cli.StringFlag{
-				Name:    "verbosity",
-				Aliases: []string{"v"},
-				Usage:   "specify the level of information that should be provided during runtime; value can be: " + strings.Join(reporter.VerbosityLevels(), ", "),
-				Value:   "error",
-			},
-			&cli.StringSliceFlag{
-				Name:  "enable-parsers",
-				Usage: fmt.Sprintf("Explicitly define which lockfile to parse. If set, any non-set parsers will be ignored. (Available parsers: %v)", lockfile.ListExtractors()),
+			{
+				Name:        "image",
+				Usage:       "scans a container image saved with `docker save` or stored as an OCI image layout",
+				Description: "scans a container image archive or OCI image layout directly: its layers are applied in order, and each package records the digest of the layer which introduced it",
+				Flags:       flags(),
+				ArgsUsage:   "[image1.tar image2...]",
+				Action: func(c *cli.Context) error {
+					var err error
+					*r, err = action(c, stdout, stderr, imageMode)
+
+					return err
+				},
 			},
 		},
-		ArgsUsage: "[directory1 directory2...]",
-		Action: func(c *cli.Context) error {
-			var err error
-			*r, err = action(c, stdout, stderr)
+	}
+}
 
-			return err
+func flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "format",
+			Aliases: []string{"f"},
+			Usage:   "sets the output format; value can be: " + strings.Join(reporter.Format(), ", "),
+			Value:   "cyclonedx-1-5",
+			Action: func(context *cli.Context, s string) error {
+				if slices.Contains(reporter.Format(), s) {
+					return nil
+				}
+
+				return fmt.Errorf("unsupported output format \"%s\" - must be one of: %s", s, strings.Join(reporter.Format(), ", "))
+			},
+		},
+		&cli.StringFlag{
+			Name:      "output",
+			Aliases:   []string{"o"},
+			Usage:     "saves the result to the given file path",
+			TakesFile: true,
+		},
+		&cli.BoolFlag{
+			Name:  "not-recursive",
+			Usage: "do not check subdirectories",
+			Value: false,
+		},
+		&cli.BoolFlag{
+			Name:  "no-ignore",
+			Usage: "also scan files that would be ignored by .gitignore",
+			Value: false,
+		},
+		&cli.BoolFlag{
+			Name:  "reachability",
+			Usage: "enable reachability analysis",
+			Value: false,
+		},
+		&cli.StringFlag{
+			Name:      "vulnerable-symbols",
+			Usage:     "reads the vulnerable symbols of the reachability analysis from the given file instead of the Datadog API; implies --reachability",
+			TakesFile: true,
+		},
+		&cli.StringFlag{
+			Name:      "reachability-cache",
+			Usage:     "caches the reachability analysis of each file in the given directory, so that later scans only analyze the files which changed",
+			TakesFile: true,
+		},
+		&cli.StringSliceFlag{
+			Name:  "test-sources",
+			Usage: "reports the reachable symbols of the files matching the given .gitignore patterns as used by tests, in addition to the conventional test locations of each ecosystem",
+		},
+		&cli.StringFlag{
+			Name:  "git-ref",
+			Usage: "scans the given git revision (commit, branch or tag) of the repositories, without checking it out",
+		},
+		&cli.StringFlag{
+			Name:      "osv-db",
+			Usage:     "matches packages against the OSV advisories (JSON files or zip archives) stored in the given directory",
+			TakesFile: true,
+		},
+		&cli.StringSliceFlag{
+			Name:  "fail-on",
+			Usage: "exits with code 1 when a vulnerability matches the given policy rule, made of conditions joined by \"&&\" among: severity>=<low|medium|high|critical>, reachable, reachable-in-production, direct, production",
+		},
+		&cli.StringFlag{
+			Name:    "verbosity",
+			Aliases: []string{"v"},
+			Usage:   "specify the level of information that should be provided during runtime; value can be: " + strings.Join(reporter.VerbosityLevels(), ", "),
+			Value:   "error",
+		},
+		&cli.StringSliceFlag{
+			Name:  "enable-parsers",
+			Usage: fmt.Sprintf("Explicitly define which lockfile to parse. If set, any non-set parsers will be ignored. (Available parsers: %v)", lockfile.ListExtractors()),
 		},
 	}
 }
 
-func action(context *cli.Context, stdout, stderr io.Writer) (reporter.Reporter, error) {
+func action(context *cli.Context, stdout, stderr io.Writer, mode scanMode) (reporter.Reporter, error) {
 	format := context.String("format")
 
 	outputPath := context.String("output")
@@ -98,13 +169,26 @@ func action(context *cli.Context, stdout, stderr io.Writer) (reporter.Reporter,
 		return r, err
 	}
 
-	vulnResult, err := scanner.DoScan(scanner.ScannerActions{
-		Recursive:      !context.Bool("not-recursive"),
-		NoIgnore:       context.Bool("no-ignore"),
-		Reachability:   context.Bool("reachability"),
-		DirectoryPaths: context.Args().Slice(),
-		EnableParsers:  context.StringSlice("enable-parsers"),
-	}, r)
+	actions := scanner.ScannerActions{
+		Recursive:             !context.Bool("not-recursive"),
+		NoIgnore:              context.Bool("no-ignore"),
+		Reachability:          context.Bool("reachability") || context.String("vulnerable-symbols") != "",
+		EnableParsers:         context.StringSlice("enable-parsers"),
+		OSVDatabasePath:       context.String("osv-db"),
+		FailOn:                context.StringSlice("fail-on"),
+		RootFS:                mode == rootFSMode,
+		GitRef:                context.String("git-ref"),
+		VulnerableSymbolsPath: context.String("vulnerable-symbols"),
+		ReachabilityCacheDir:  context.String("reachability-cache"),
+		TestSourcePatterns:    context.StringSlice("test-sources"),
+	}
+	if mode == imageMode {
+		actions.ImagePaths = context.Args().Slice()
+	} else {
+		actions.DirectoryPaths = context.Args().Slice()
+	}
+
+	vulnResult, err := scanner.DoScan(actions, r)
 
 	if err != nil && !errors.Is(err, scanner.NoPackagesFoundErr) && !errors.Is(err, scanner.VulnerabilitiesFoundErr) {
 		return r, err
@@ -0,0 +1,371 @@
+// Package diff compares two SBOMs produced by this tool, to report how the dependencies of a project changed
+// between them. Components are identified the same way as in SBOMs: by their PURL, ignoring the version and qualifiers.
+package diff
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/output"
+	"github.com/DataDog/datadog-sbom-generator/internal/semantic"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+)
+
+// Component is a package reported in an SBOM
+type Component struct {
+	Purl      string `json:"purl"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Ecosystem string `json:"ecosystem"`
+	Direct    bool   `json:"direct"`
+	Dev       bool   `json:"dev"`
+	// Locations lists the files the package is declared in
+	Locations []string `json:"locations,omitempty"`
+	// Reachable lists the advisories of the package whose vulnerable symbols are used by the scanned code
+	Reachable []string `json:"reachable,omitempty"`
+
+	identity string
+}
+
+// FlagChange describes a boolean attribute of a component whose value changed
+type FlagChange struct {
+	Before bool `json:"before"`
+	After  bool `json:"after"`
+}
+
+// Change describes a component which is part of both SBOMs, with different versions or attributes
+type Change struct {
+	Component
+
+	PreviousVersion  string      `json:"previousVersion,omitempty"`
+	Direct           *FlagChange `json:"directChange,omitempty"`
+	Dev              *FlagChange `json:"devChange,omitempty"`
+	AddedLocations   []string    `json:"addedLocations,omitempty"`
+	RemovedLocations []string    `json:"removedLocations,omitempty"`
+	AddedReachable   []string    `json:"addedReachable,omitempty"`
+	RemovedReachable []string    `json:"removedReachable,omitempty"`
+}
+
+// Report lists the differences between two SBOMs, each list being sorted by PURL
+type Report struct {
+	Added      []Component `json:"added"`
+	Removed    []Component `json:"removed"`
+	Upgraded   []Change    `json:"upgraded"`
+	Downgraded []Change    `json:"downgraded"`
+	// VersionChanged lists the components whose versions cannot be ordered, such as the ones of unsupported ecosystems
+	VersionChanged []Change `json:"versionChanged"`
+	// Changed lists the components whose version did not change, but which are declared or used differently
+	Changed []Change `json:"changed"`
+}
+
+// Compare reports the differences between the packages of two SBOMs, grouped by PURL as returned by Load.
+// When several versions of a component are found, the versions found in both SBOMs are compared together,
+// and the remaining ones are reported as a version change if there is only one on each side, or as added
+// and removed components otherwise.
+func Compare(before, after map[string]models.PackageVulns) Report {
+	report := Report{
+		Added:          []Component{},
+		Removed:        []Component{},
+		Upgraded:       []Change{},
+		Downgraded:     []Change{},
+		VersionChanged: []Change{},
+		Changed:        []Change{},
+	}
+
+	beforeComponents := groupByIdentity(before)
+	afterComponents := groupByIdentity(after)
+
+	identities := make([]string, 0, len(beforeComponents)+len(afterComponents))
+	for identity := range beforeComponents {
+		identities = append(identities, identity)
+	}
+	for identity := range afterComponents {
+		identities = append(identities, identity)
+	}
+	slices.Sort(identities)
+
+	for _, identity := range slices.Compact(identities) {
+		var remainingBefore, remainingAfter []Component
+		for _, component := range beforeComponents[identity] {
+			index := slices.IndexFunc(afterComponents[identity], func(c Component) bool { return c.Version == component.Version })
+			if index < 0 {
+				remainingBefore = append(remainingBefore, component)
+				continue
+			}
+			if change, changed := compareAttributes(component, afterComponents[identity][index]); changed {
+				report.Changed = append(report.Changed, change)
+			}
+		}
+		for _, component := range afterComponents[identity] {
+			if !slices.ContainsFunc(beforeComponents[identity], func(c Component) bool { return c.Version == component.Version }) {
+				remainingAfter = append(remainingAfter, component)
+			}
+		}
+
+		if len(remainingBefore) == 1 && len(remainingAfter) == 1 {
+			change, _ := compareAttributes(remainingBefore[0], remainingAfter[0])
+			change.PreviousVersion = remainingBefore[0].Version
+
+			switch compareVersions(remainingBefore[0], remainingAfter[0]) {
+			case versionUpgraded:
+				report.Upgraded = append(report.Upgraded, change)
+			case versionDowngraded:
+				report.Downgraded = append(report.Downgraded, change)
+			default:
+				report.VersionChanged = append(report.VersionChanged, change)
+			}
+
+			continue
+		}
+		report.Removed = append(report.Removed, remainingBefore...)
+		report.Added = append(report.Added, remainingAfter...)
+	}
+
+	return report
+}
+
+// groupByIdentity returns the components of an SBOM grouped by PURL without version, sorted by version
+func groupByIdentity(packages map[string]models.PackageVulns) map[string][]Component {
+	components := make(map[string][]Component)
+	for packageURL, pkg := range packages {
+		component := newComponent(packageURL, pkg)
+		components[component.identity] = append(components[component.identity], component)
+	}
+	for _, versions := range components {
+		slices.SortFunc(versions, func(a, b Component) int { return strings.Compare(a.Version, b.Version) })
+	}
+
+	return components
+}
+
+func newComponent(packageURL string, pkg models.PackageVulns) Component {
+	component := Component{
+		Purl:      packageURL,
+		Name:      pkg.Package.Name,
+		Version:   pkg.Package.Version,
+		Ecosystem: pkg.Package.Ecosystem,
+		Direct:    pkg.Metadata[models.IsDirectDependencyMetadata] == "true",
+		Dev:       pkg.Metadata[models.IsDevDependencyMetadata] == "true",
+		identity:  packageURL,
+	}
+
+	// Qualifiers, such as the distro of OS packages, are specific to a version just like the version itself
+	if parsed, err := packageurl.FromString(packageURL); err == nil {
+		parsed.Version = ""
+		parsed.Qualifiers = nil
+		component.identity = parsed.ToString()
+	}
+
+	for _, location := range pkg.Locations {
+		if location.Block.Filename != "" {
+			component.Locations = append(component.Locations, location.Block.Filename)
+		}
+	}
+	slices.Sort(component.Locations)
+	component.Locations = slices.Compact(component.Locations)
+
+	reachablePrefix := string(models.ReachableSymbolLocationMetadata.WithValue(""))
+	for key := range pkg.Metadata {
+		if advisoryID, ok := strings.CutPrefix(string(key), reachablePrefix); ok {
+			component.Reachable = append(component.Reachable, advisoryID)
+		}
+	}
+	slices.Sort(component.Reachable)
+
+	return component
+}
+
+// compareAttributes returns the change of the attributes of a component, and whether any of them changed
+func compareAttributes(before, after Component) (Change, bool) {
+	change := Change{Component: after}
+
+	if before.Direct != after.Direct {
+		change.Direct = &FlagChange{Before: before.Direct, After: after.Direct}
+	}
+	if before.Dev != after.Dev {
+		change.Dev = &FlagChange{Before: before.Dev, After: after.Dev}
+	}
+	for _, location := range after.Locations {
+		if !slices.Contains(before.Locations, location) {
+			change.AddedLocations = append(change.AddedLocations, location)
+		}
+	}
+	for _, location := range before.Locations {
+		if !slices.Contains(after.Locations, location) {
+			change.RemovedLocations = append(change.RemovedLocations, location)
+		}
+	}
+	for _, advisoryID := range after.Reachable {
+		if !slices.Contains(before.Reachable, advisoryID) {
+			change.AddedReachable = append(change.AddedReachable, advisoryID)
+		}
+	}
+	for _, advisoryID := range before.Reachable {
+		if !slices.Contains(after.Reachable, advisoryID) {
+			change.RemovedReachable = append(change.RemovedReachable, advisoryID)
+		}
+	}
+
+	changed := change.Direct != nil || change.Dev != nil || len(change.AddedLocations) > 0 || len(change.RemovedLocations) > 0 ||
+		len(change.AddedReachable) > 0 || len(change.RemovedReachable) > 0
+
+	return change, changed
+}
+
+type versionChange int
+
+const (
+	versionUnordered versionChange = iota
+	versionUpgraded
+	versionDowngraded
+)
+
+// compareVersions orders the versions of a component using the version semantic of its ecosystem
+func compareVersions(before, after Component) versionChange {
+	// The release of OS packages, such as Debian:12, does not change how their versions are compared
+	ecosystem, _, _ := strings.Cut(cmp.Or(after.Ecosystem, before.Ecosystem), ":")
+
+	version, err := semantic.Parse(before.Version, models.Ecosystem(ecosystem))
+	if err != nil {
+		return versionUnordered
+	}
+
+	switch version.CompareStr(after.Version) {
+	case -1:
+		return versionUpgraded
+	case 1:
+		return versionDowngraded
+	}
+
+	return versionUnordered
+}
+
+// IsEmpty returns whether both SBOMs have the same components
+func (report Report) IsEmpty() bool {
+	return len(report.Added) == 0 && len(report.Removed) == 0 && len(report.Upgraded) == 0 &&
+		len(report.Downgraded) == 0 && len(report.VersionChanged) == 0 && len(report.Changed) == 0
+}
+
+// Summary returns a one-line description of the report, such as
+// "3 components added (1 direct, 2 transitive), 1 upgraded, 1 downgraded"
+func (report Report) Summary() string {
+	if report.IsEmpty() {
+		return "No dependency changes"
+	}
+
+	var parts []string
+	if len(report.Added) > 0 {
+		direct := len(slices.DeleteFunc(slices.Clone(report.Added), func(c Component) bool { return !c.Direct }))
+		parts = append(parts, fmt.Sprintf("%d %s added (%d direct, %d transitive)",
+			len(report.Added), output.Form(len(report.Added), "component", "components"), direct, len(report.Added)-direct))
+	}
+	for _, part := range []struct {
+		count int
+		label string
+	}{
+		{len(report.Removed), "removed"},
+		{len(report.Upgraded), "upgraded"},
+		{len(report.Downgraded), "downgraded"},
+		{len(report.VersionChanged), "with an unordered version change"},
+		{len(report.Changed), "declared differently"},
+	} {
+		if part.count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", part.count, part.label))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// String returns a human-readable report of all the differences
+func (report Report) String() string {
+	var text strings.Builder
+
+	text.WriteString(report.Summary() + "\n")
+
+	writeSection(&text, "Added", report.Added, func(c Component) string {
+		return fmt.Sprintf("%s (%s)", c.Purl, strings.Join(c.flags(), ", "))
+	})
+	writeSection(&text, "Removed", report.Removed, func(c Component) string {
+		return fmt.Sprintf("%s (%s)", c.Purl, strings.Join(c.flags(), ", "))
+	})
+	for _, section := range []struct {
+		title   string
+		changes []Change
+	}{
+		{"Upgraded", report.Upgraded},
+		{"Downgraded", report.Downgraded},
+		{"Version changed", report.VersionChanged},
+	} {
+		writeSection(&text, section.title, section.changes, func(c Change) string {
+			line := fmt.Sprintf("%s: %s -> %s", c.identity, c.PreviousVersion, c.Version)
+			if details := c.details(); len(details) > 0 {
+				line += " (" + strings.Join(details, ", ") + ")"
+			}
+
+			return line
+		})
+	}
+	writeSection(&text, "Declared differently", report.Changed, func(c Change) string {
+		return fmt.Sprintf("%s: %s", c.Purl, strings.Join(c.details(), ", "))
+	})
+
+	return text.String()
+}
+
+func writeSection[T any](text *strings.Builder, title string, items []T, format func(T) string) {
+	if len(items) == 0 {
+		return
+	}
+
+	fmt.Fprintf(text, "\n%s:\n", title)
+	for _, item := range items {
+		fmt.Fprintf(text, "  - %s\n", format(item))
+	}
+}
+
+func (c Component) flags() []string {
+	flags := []string{"transitive"}
+	if c.Direct {
+		flags[0] = "direct"
+	}
+	if c.Dev {
+		flags = append(flags, "dev")
+	}
+
+	return flags
+}
+
+func (c Change) details() []string {
+	var details []string
+	switch {
+	case c.Direct != nil && c.Direct.After:
+		details = append(details, "now direct")
+	case c.Direct != nil:
+		details = append(details, "now transitive")
+	}
+	switch {
+	case c.Dev != nil && c.Dev.After:
+		details = append(details, "now dev")
+	case c.Dev != nil:
+		details = append(details, "no longer dev")
+	}
+	if len(c.AddedLocations) > 0 {
+		details = append(details, "now declared in "+strings.Join(c.AddedLocations, ", "))
+	}
+	if len(c.RemovedLocations) > 0 {
+		details = append(details, "no longer declared in "+strings.Join(c.RemovedLocations, ", "))
+	}
+	if len(c.AddedReachable) > 0 {
+		details = append(details, "now reachable through "+strings.Join(c.AddedReachable, ", "))
+	}
+	if len(c.RemovedReachable) > 0 {
+		details = append(details, "no longer reachable through "+strings.Join(c.RemovedReachable, ", "))
+	}
+
+	return details
+}
@@ -0,0 +1,238 @@
+package diff_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/diff"
+	"github.com/DataDog/datadog-sbom-generator/internal/output"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	for _, path := range []string{"fixtures/after.cdx.json", "fixtures/after-1-5.cdx.json"} {
+		packages, err := diff.Load(path)
+		require.NoError(t, err, path)
+
+		assert.Len(t, packages, 5, path)
+		chalk := packages["pkg:npm/chalk@4.1.2"]
+		assert.Equal(t, models.PackageInfo{Name: "chalk", Version: "4.1.2", Ecosystem: "npm", Purl: "pkg:npm/chalk@4.1.2"}, chalk.Package, path)
+		assert.Equal(t, "true", chalk.Metadata[models.IsDirectDependencyMetadata], path)
+		require.Len(t, chalk.Locations, 1, path)
+		assert.Equal(t, "package.json", chalk.Locations[0].Block.Filename, path)
+	}
+
+	packages, err := diff.Load("fixtures/before.json")
+	require.NoError(t, err)
+	assert.Len(t, packages, 4)
+	assert.Equal(t, "true", packages["pkg:npm/debug@2.6.9"].Metadata[models.IsDevDependencyMetadata])
+
+	_, err = diff.Load("fixtures/does-not-exist.json")
+	require.Error(t, err)
+}
+
+func TestLoad_ReachableSymbolLocations(t *testing.T) {
+	t.Parallel()
+
+	packages, err := diff.Load("fixtures/reachable.cdx.json")
+	require.NoError(t, err)
+
+	// The call stack frames of CycloneDX 1.6 outputs only hold the start of the locations
+	lodash := packages["pkg:npm/lodash@4.17.21"]
+	assert.JSONEq(t,
+		`[{"file_name":"src/index.js","line_start":3,"line_end":0,"column_start":1,"column_end":0,"symbol":"_.template","source_type":"production"}]`,
+		lodash.Metadata[models.ReachableSymbolLocationMetadata.WithValue("GHSA-35jh-r3h4-6jhm")],
+	)
+	assert.Equal(t, "true", lodash.Metadata[models.IsDirectDependencyMetadata])
+}
+
+func TestLoad_CycloneDXRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	results := models.VulnerabilityResults{
+		Results: []models.PackageSource{
+			{
+				Source: models.SourceInfo{Path: "pom.xml"},
+				Packages: []models.PackageVulns{
+					{
+						Package: models.PackageInfo{Name: "com.nobody:mine1", Version: "1.2.3", Ecosystem: "Maven", Purl: "pkg:maven/com.nobody/mine1@1.2.3"},
+						Metadata: models.PackageMetadata{
+							models.ReachableSymbolLocationMetadata.WithValue("GHSA-1"): `[{"file_name":"src/main/java/App.java","line_start":10,"line_end":10,"column_start":9,"column_end":30,"symbol":"Parser","source_type":"production"}]`,
+							models.ReachableSymbolLocationMetadata.WithValue("GHSA-2"): `[{"file_name":"src/main/java/App.java","line_start":10,"line_end":10,"column_start":9,"column_end":30,"symbol":"Parser","source_type":"production"},{"file_name":"src/test/java/AppTest.java","line_start":3,"line_end":3,"column_start":1,"column_end":12,"symbol":"Reader","source_type":"test"}]`,
+						},
+					},
+				},
+			},
+		},
+	}
+	unreachable := diff.FromResults(models.VulnerabilityResults{
+		Results: []models.PackageSource{
+			{
+				Source:   models.SourceInfo{Path: "pom.xml"},
+				Packages: []models.PackageVulns{{Package: results.Results[0].Packages[0].Package}},
+			},
+		},
+	})
+
+	for _, specVersion := range []cyclonedx.SpecVersion{cyclonedx.SpecVersion1_5, cyclonedx.SpecVersion1_6} {
+		var buffer bytes.Buffer
+		require.NoError(t, output.PrintCycloneDXResults(&results, specVersion, &buffer))
+		path := filepath.Join(t.TempDir(), "sbom.cdx.json")
+		require.NoError(t, os.WriteFile(path, buffer.Bytes(), 0o600))
+
+		packages, err := diff.Load(path)
+		require.NoError(t, err, specVersion)
+
+		pkg := packages["pkg:maven/com.nobody/mine1@1.2.3"]
+		var locations models.ReachableSymbolLocations
+		require.NoError(t, json.Unmarshal([]byte(pkg.Metadata[models.ReachableSymbolLocationMetadata.WithValue("GHSA-2")]), &locations))
+		require.Len(t, locations, 2, specVersion)
+		assert.Equal(t, "Reader", locations[1].Symbol, specVersion)
+		assert.Equal(t, models.TestSource, locations[1].SourceType, specVersion)
+
+		report := diff.Compare(unreachable, packages)
+		require.Len(t, report.Changed, 1, specVersion)
+		assert.Equal(t, []string{"GHSA-1", "GHSA-2"}, report.Changed[0].AddedReachable, specVersion)
+
+		assert.Empty(t, diff.Compare(diff.FromResults(results), packages).Changed, specVersion)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	before, err := diff.Load("fixtures/before.json")
+	require.NoError(t, err)
+	after, err := diff.Load("fixtures/after.cdx.json")
+	require.NoError(t, err)
+
+	report := diff.Compare(before, after)
+
+	assert.Equal(t, []diff.Component{
+		{Purl: "pkg:npm/ansi-styles@4.3.0", Name: "ansi-styles", Version: "4.3.0", Ecosystem: "npm"},
+		{Purl: "pkg:npm/chalk@4.1.2", Name: "chalk", Version: "4.1.2", Ecosystem: "npm", Direct: true, Locations: []string{"package.json"}},
+	}, clearIdentities(report.Added))
+	assert.Equal(t, []diff.Component{
+		{Purl: "pkg:npm/left-pad@1.3.0", Name: "left-pad", Version: "1.3.0", Ecosystem: "npm", Direct: true, Locations: []string{"package.json"}},
+	}, clearIdentities(report.Removed))
+
+	require.Len(t, report.Upgraded, 1)
+	assert.Equal(t, "pkg:npm/lodash@4.17.21", report.Upgraded[0].Purl)
+	assert.Equal(t, "4.17.20", report.Upgraded[0].PreviousVersion)
+
+	require.Len(t, report.Downgraded, 1)
+	assert.Equal(t, "pkg:npm/debug@2.6.8", report.Downgraded[0].Purl)
+	assert.Equal(t, "2.6.9", report.Downgraded[0].PreviousVersion)
+
+	require.Len(t, report.Changed, 1)
+	assert.Equal(t, "pkg:npm/ms@2.0.0", report.Changed[0].Purl)
+	assert.Equal(t, &diff.FlagChange{Before: false, After: true}, report.Changed[0].Direct)
+	assert.Equal(t, &diff.FlagChange{Before: true, After: false}, report.Changed[0].Dev)
+	assert.Equal(t, []string{"package.json"}, report.Changed[0].AddedLocations)
+
+	assert.Empty(t, report.VersionChanged)
+	assert.Equal(t, "2 components added (1 direct, 1 transitive), 1 removed, 1 upgraded, 1 downgraded, 1 declared differently", report.Summary())
+}
+
+func TestCompare_OSPackagesAndSeveralVersions(t *testing.T) {
+	t.Parallel()
+
+	pkg := func(name, version, ecosystem, purl string) models.PackageVulns {
+		return models.PackageVulns{Package: models.PackageInfo{Name: name, Version: version, Ecosystem: ecosystem, Purl: purl}}
+	}
+
+	before := map[string]models.PackageVulns{
+		"pkg:deb/debian/bash@5.1-2?distro=debian-11":   pkg("bash", "5.1-2", "Debian:11", ""),
+		"pkg:npm/lodash@3.10.1":                        pkg("lodash", "3.10.1", "npm", ""),
+		"pkg:npm/lodash@4.17.20":                       pkg("lodash", "4.17.20", "npm", ""),
+		"pkg:github/actions/checkout@v3":               pkg("actions/checkout", "v3", "GitHub Actions", ""),
+		"pkg:deb/debian/libc6@2.36-9?distro=debian-12": pkg("libc6", "2.36-9", "Debian:12", ""),
+	}
+	after := map[string]models.PackageVulns{
+		"pkg:deb/debian/bash@5.2.15-2?distro=debian-12": pkg("bash", "5.2.15-2", "Debian:12", ""),
+		"pkg:npm/lodash@4.17.20":                        pkg("lodash", "4.17.20", "npm", ""),
+		"pkg:npm/lodash@4.17.21":                        pkg("lodash", "4.17.21", "npm", ""),
+		"pkg:npm/lodash@2.4.2":                          pkg("lodash", "2.4.2", "npm", ""),
+		"pkg:github/actions/checkout@v4":                pkg("actions/checkout", "v4", "GitHub Actions", ""),
+		"pkg:deb/debian/libc6@2.36-9?distro=debian-12":  pkg("libc6", "2.36-9", "Debian:12", ""),
+	}
+
+	report := diff.Compare(before, after)
+
+	require.Len(t, report.Upgraded, 1)
+	assert.Equal(t, "pkg:deb/debian/bash@5.2.15-2?distro=debian-12", report.Upgraded[0].Purl)
+	require.Len(t, report.VersionChanged, 1)
+	assert.Equal(t, "pkg:github/actions/checkout@v4", report.VersionChanged[0].Purl)
+
+	// lodash 3.10.1 cannot be matched with a single new version
+	require.Len(t, report.Removed, 1)
+	assert.Equal(t, "pkg:npm/lodash@3.10.1", report.Removed[0].Purl)
+	require.Len(t, report.Added, 2)
+	assert.Equal(t, "pkg:npm/lodash@2.4.2", report.Added[0].Purl)
+	assert.Equal(t, "pkg:npm/lodash@4.17.21", report.Added[1].Purl)
+
+	assert.Empty(t, report.Downgraded)
+	assert.Empty(t, report.Changed)
+}
+
+func TestCompare_Reachability(t *testing.T) {
+	t.Parallel()
+
+	before, err := diff.Load("fixtures/after.cdx.json")
+	require.NoError(t, err)
+	after, err := diff.Load("fixtures/reachable.cdx.json")
+	require.NoError(t, err)
+
+	report := diff.Compare(before, after)
+
+	require.Len(t, report.Changed, 1)
+	assert.Equal(t, "pkg:npm/lodash@4.17.21", report.Changed[0].Purl)
+	assert.Equal(t, []string{"GHSA-35jh-r3h4-6jhm"}, report.Changed[0].AddedReachable)
+	assert.Empty(t, report.Changed[0].RemovedReachable)
+	assert.Contains(t, report.String(), "pkg:npm/lodash@4.17.21: now reachable through GHSA-35jh-r3h4-6jhm")
+
+	report = diff.Compare(after, before)
+
+	require.Len(t, report.Changed, 1)
+	assert.Equal(t, []string{"GHSA-35jh-r3h4-6jhm"}, report.Changed[0].RemovedReachable)
+}
+
+func TestCompare_NoChanges(t *testing.T) {
+	t.Parallel()
+
+	packages, err := diff.Load("fixtures/before.json")
+	require.NoError(t, err)
+
+	report := diff.Compare(packages, packages)
+
+	assert.True(t, report.IsEmpty())
+	assert.Equal(t, "No dependency changes\n", report.String())
+}
+
+// clearIdentities drops the unexported fields of the components, so that they can be compared with literals
+func clearIdentities(components []diff.Component) []diff.Component {
+	cleared := make([]diff.Component, 0, len(components))
+	for _, component := range components {
+		cleared = append(cleared, diff.Component{
+			Purl:      component.Purl,
+			Name:      component.Name,
+			Version:   component.Version,
+			Ecosystem: component.Ecosystem,
+			Direct:    component.Direct,
+			Dev:       component.Dev,
+			Locations: component.Locations,
+			Reachable: component.Reachable,
+		})
+	}
+
+	return cleared
+}
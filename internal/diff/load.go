@@ -0,0 +1,173 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/package-url/packageurl-go"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/output/sbom"
+	"github.com/DataDog/datadog-sbom-generator/internal/utility/purl"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+)
+
+// cycloneDXPropertyPrefixes are the prefixes of the properties holding package metadata in CycloneDX outputs,
+// the reachability of packages being reported with the prefix of this tool
+var cycloneDXPropertyPrefixes = []string{"osv-scanner:", "datadog-sbom-generator:"}
+
+var ErrUnsupportedFormat = errors.New("unsupported SBOM format")
+
+// Load reads an SBOM produced by this tool, either in the CycloneDX or the JSON format,
+// and returns its packages grouped by PURL
+func Load(path string) (map[string]models.PackageVulns, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var header struct {
+		BOMFormat string          `json:"bomFormat"`
+		Results   json.RawMessage `json:"results"`
+	}
+	if err := json.Unmarshal(content, &header); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+
+	switch {
+	case header.BOMFormat == cyclonedx.BOMFormat:
+		var bom cyclonedx.BOM
+		if err := cyclonedx.NewBOMDecoder(bytes.NewReader(content), cyclonedx.BOMFileFormatJSON).Decode(&bom); err != nil {
+			return nil, fmt.Errorf("could not parse %s: %w", path, err)
+		}
+
+		return fromCycloneDX(bom), nil
+	case header.Results != nil:
+		var results models.VulnerabilityResults
+		if err := json.Unmarshal(content, &results); err != nil {
+			return nil, fmt.Errorf("could not parse %s: %w", path, err)
+		}
+
+		return FromResults(results), nil
+	}
+
+	return nil, fmt.Errorf("%w: %s is neither a CycloneDX nor a JSON output", ErrUnsupportedFormat, path)
+}
+
+// FromResults groups the packages of scan results by PURL, the same way as they are reported in SBOMs
+func FromResults(results models.VulnerabilityResults) map[string]models.PackageVulns {
+	// Packages without PURL are not part of SBOMs either
+	packages, _ := purl.Group(results.Results)
+
+	return packages
+}
+
+func fromCycloneDX(bom cyclonedx.BOM) map[string]models.PackageVulns {
+	packages := make(map[string]models.PackageVulns)
+	if bom.Components == nil {
+		return packages
+	}
+
+	for _, component := range *bom.Components {
+		if component.Type != cyclonedx.ComponentTypeLibrary || component.PackageURL == "" {
+			continue
+		}
+
+		var ecosystem models.Ecosystem
+		if packageURL, err := packageurl.FromString(component.PackageURL); err == nil {
+			ecosystem, _ = purl.ToEcosystem(packageURL)
+		}
+
+		pkg := models.PackageVulns{
+			Package: models.PackageInfo{
+				Name:      component.Name,
+				Version:   component.Version,
+				Ecosystem: string(ecosystem),
+				Purl:      component.PackageURL,
+			},
+			Metadata: models.PackageMetadata{},
+		}
+		if component.Properties != nil {
+			for _, property := range *component.Properties {
+				for _, prefix := range cycloneDXPropertyPrefixes {
+					if key, ok := strings.CutPrefix(property.Name, prefix); ok {
+						pkg.Metadata[models.PackageMetadataType(key)] = property.Value
+						break
+					}
+				}
+			}
+		}
+		if component.Evidence != nil && component.Evidence.Occurrences != nil {
+			for _, occurrence := range *component.Evidence.Occurrences {
+				pkg.Locations = append(pkg.Locations, parseOccurrence(occurrence))
+			}
+		}
+		if component.Evidence != nil && component.Evidence.Callstack != nil && component.Evidence.Callstack.Frames != nil {
+			addReachableSymbolLocations(pkg.Metadata, *component.Evidence.Callstack.Frames)
+		}
+
+		packages[component.PackageURL] = pkg
+	}
+
+	return packages
+}
+
+// parseOccurrence reads the location of a package, which is JSON encoded in CycloneDX 1.5 outputs
+// and a plain file name in CycloneDX 1.6 outputs
+func parseOccurrence(occurrence cyclonedx.EvidenceOccurrence) models.PackageLocations {
+	var location models.PackageLocations
+	if err := json.Unmarshal([]byte(occurrence.Location), &location); err == nil {
+		return location
+	}
+
+	location.Block.Filename = occurrence.Location
+	if occurrence.Line != nil {
+		location.Block.LineStart = *occurrence.Line
+	}
+
+	return location
+}
+
+// addReachableSymbolLocations reads the reachable symbol locations of a package from the call stack frames of CycloneDX 1.6
+// outputs, whose module is the advisory whose symbol is used. The locations reported as properties are kept as is.
+func addReachableSymbolLocations(metadata models.PackageMetadata, frames []cyclonedx.CallstackFrame) {
+	locations := make(map[models.PackageMetadataType]models.ReachableSymbolLocations)
+	for _, frame := range frames {
+		if frame.Module == "" {
+			continue
+		}
+		key := models.ReachableSymbolLocationMetadata.WithValue(frame.Module)
+		if _, ok := metadata[key]; ok {
+			continue
+		}
+
+		location := models.ReachableSymbolLocation{
+			PackageLocation: models.PackageLocation{Filename: frame.FullFilename},
+			Symbol:          frame.Function,
+		}
+		if frame.Line != nil {
+			location.LineStart = *frame.Line
+		}
+		if frame.Column != nil {
+			location.ColumnStart = *frame.Column
+		}
+		if frame.Parameters != nil {
+			for _, parameter := range *frame.Parameters {
+				if sourceType, ok := strings.CutPrefix(parameter, sbom.CallstackSourceTypeParameter); ok {
+					location.SourceType = models.SourceType(sourceType)
+				}
+			}
+		}
+		locations[key] = append(locations[key], location)
+	}
+
+	for key, advisoryLocations := range locations {
+		if value, err := advisoryLocations.MarshalToJSONString(); err == nil {
+			metadata[key] = value
+		}
+	}
+}
@@ -0,0 +1,310 @@
+// Package gitrev reads the files of a git revision straight from the object database of a repository,
+// so that commits, branches and tags can be scanned without checking them out.
+package gitrev
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/lockfile"
+)
+
+// peekSize is the size of the start of a file which can be read at random positions without loading it in memory,
+// for extractors checking magic bytes
+const peekSize = 512
+
+// Tree is the tree of a revision of a git repository. Files are identified by their absolute path from the root
+// of the repository, e.g. /services/api/package-lock.json.
+type Tree struct {
+	tree *object.Tree
+	// Dir is the absolute path, in the tree, of the directory which has been opened
+	Dir string
+
+	// loaded is the last blob loaded in memory, which is kept as files are checked, extracted and reopened
+	// by the extractors one after the other
+	loaded        plumbing.Hash
+	loadedContent []byte
+}
+
+// Open opens the tree of the given revision (a commit hash, a branch, a tag or any expression supported by
+// `git rev-parse`) of the repository containing dir. The repository can either be a working tree or a bare
+// repository; when dir is a subdirectory of a working tree, Tree.Dir is set to the matching directory of the tree.
+func Open(dir string, revision string) (*Tree, error) {
+	// Bare repositories are not detected when looking for the .git directory of a working tree
+	repository, err := git.PlainOpen(dir)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		repository, err = git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open git repository %s: %w", dir, err)
+	}
+
+	hash, err := repository.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve %s in %s: %w", revision, dir, err)
+	}
+
+	commit, err := repository.CommitObject(*hash)
+	if err != nil {
+		// Annotated tags are resolved to the tag object rather than to the commit it targets
+		tag, tagErr := repository.TagObject(*hash)
+		if tagErr != nil {
+			return nil, fmt.Errorf("could not read commit %s: %w", hash, err)
+		}
+		if commit, err = tag.Commit(); err != nil {
+			return nil, fmt.Errorf("could not read commit targeted by tag %s: %w", revision, err)
+		}
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("could not read tree of commit %s: %w", commit.Hash, err)
+	}
+
+	return &Tree{tree: tree, Dir: subdirectory(repository, dir)}, nil
+}
+
+// subdirectory returns the path of dir from the root of the working tree, or "/" for bare repositories
+func subdirectory(repository *git.Repository, dir string) string {
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return "/"
+	}
+
+	root, rootErr := filepath.EvalSymlinks(worktree.Filesystem.Root())
+	absDir, dirErr := filepath.Abs(dir)
+	if rootErr != nil || dirErr != nil {
+		return "/"
+	}
+	if resolved, err := filepath.EvalSymlinks(absDir); err == nil {
+		absDir = resolved
+	}
+
+	relative, err := filepath.Rel(root, absDir)
+	if err != nil || strings.HasPrefix(relative, "..") {
+		return "/"
+	}
+
+	return path.Join("/", filepath.ToSlash(relative))
+}
+
+// Files returns the sorted paths of the regular files of Tree.Dir, including the ones of its subdirectories
+// when recursive is set
+func (t *Tree) Files(recursive bool) ([]string, error) {
+	return t.files(strings.TrimSuffix(t.Dir, "/")+"/", recursive)
+}
+
+// files returns the sorted paths of the regular files whose path starts with the given prefix
+func (t *Tree) files(prefix string, recursive bool) ([]string, error) {
+	var files []string
+	err := t.tree.Files().ForEach(func(file *object.File) error {
+		name := "/" + file.Name
+		if !file.Mode.IsFile() || file.Mode == filemode.Symlink || !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+		if !recursive && strings.Contains(strings.TrimPrefix(name, prefix), "/") {
+			return nil
+		}
+		files = append(files, name)
+
+		return nil
+	})
+	slices.Sort(files)
+
+	return files, err
+}
+
+// Export writes the files of Tree.Dir, including the ones of its subdirectories when recursive is set, to the given
+// directory of the local filesystem, for the tools which can only read the files of a project from there.
+// Only the files whose path from Tree.Dir is accepted by include are written, as exporting a whole revision takes
+// as much disk space as a checkout.
+func (t *Tree) Export(destination string, recursive bool, include func(relative string) bool) error {
+	files, err := t.Files(recursive)
+	if err != nil {
+		return err
+	}
+
+	prefix := strings.TrimSuffix(t.Dir, "/") + "/"
+	for _, name := range files {
+		relative := filepath.FromSlash(strings.TrimPrefix(name, prefix))
+		if !include(relative) {
+			continue
+		}
+		if !filepath.IsLocal(relative) {
+			return fmt.Errorf("could not export %s: invalid path", name)
+		}
+		if err := t.exportFile(name, filepath.Join(destination, relative)); err != nil {
+			return fmt.Errorf("could not export %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (t *Tree) exportFile(name string, target string) error {
+	file, err := t.tree.File(strings.TrimPrefix(name, "/"))
+	if err != nil {
+		return err
+	}
+	reader, err := file.Blob.Reader()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	output, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(output, reader); err != nil {
+		output.Close()
+		return err
+	}
+
+	return output.Close()
+}
+
+// Open opens the file at the given absolute path of the tree
+func (t *Tree) Open(name string) (lockfile.NestedDepFile, error) {
+	name = path.Clean("/" + name)
+
+	file, err := t.tree.File(strings.TrimPrefix(name, "/"))
+	if errors.Is(err, object.ErrFileNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	if !file.Mode.IsFile() || file.Mode == filemode.Symlink {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errors.New("not a regular file")}
+	}
+
+	reader, err := file.Blob.Reader()
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	return &File{ReadCloser: reader, tree: t, blob: &file.Blob, path: name}, nil
+}
+
+// File is a file of a git tree, read from its blob
+type File struct {
+	io.ReadCloser
+
+	tree *Tree
+	blob *object.Blob
+	path string
+	// content is the whole content of the blob, only loaded when it is read at random positions
+	content *bytes.Reader
+}
+
+// ReadAt reads the content of the blob at the given position, without consuming it. Reads at the start of the blob
+// are streamed, others load the blob in memory.
+func (f *File) ReadAt(p []byte, off int64) (int, error) {
+	if f.content == nil && off+int64(len(p)) <= peekSize {
+		return f.peek(p, off)
+	}
+
+	if f.content == nil {
+		content, err := f.tree.load(f.blob)
+		if err != nil {
+			return 0, err
+		}
+		f.content = bytes.NewReader(content)
+	}
+
+	return f.content.ReadAt(p, off)
+}
+
+// load returns the whole content of the blob, which is only read once when the same file is opened several times
+func (t *Tree) load(blob *object.Blob) ([]byte, error) {
+	if t.loadedContent != nil && t.loaded == blob.Hash {
+		return t.loadedContent, nil
+	}
+
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	t.loaded, t.loadedContent = blob.Hash, content
+
+	return content, nil
+}
+
+func (f *File) peek(p []byte, off int64) (int, error) {
+	reader, err := f.blob.Reader()
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	if _, err := io.CopyN(io.Discard, reader, off); err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(reader, p)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+
+	return n, err
+}
+
+// Open opens a file of the tree, relatively to the current file if the path is relative
+func (f *File) Open(name string) (lockfile.NestedDepFile, error) {
+	if !path.IsAbs(name) {
+		name = path.Join(path.Dir(f.path), name)
+	}
+
+	return f.tree.Open(name)
+}
+
+// Reopen opens the file again, to read it from the start
+func (f *File) Reopen() (lockfile.NestedDepFile, error) {
+	return f.tree.Open(f.path)
+}
+
+// Glob returns the paths of the files and directories of the tree matching the pattern,
+// relatively to the current file if the pattern is relative
+func (f *File) Glob(pattern string) ([]string, error) {
+	if !path.IsAbs(pattern) {
+		pattern = path.Join(path.Dir(f.path), pattern)
+	}
+
+	files, err := f.tree.files("/", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return lockfile.MatchPaths(files, pattern)
+}
+
+// Path returns the absolute path of the file in the tree
+func (f *File) Path() string {
+	return f.path
+}
+
+var _ lockfile.NestedDepFile = &File{}
+var _ lockfile.ReopenableDepFile = &File{}
+var _ lockfile.GlobbableDepFile = &File{}
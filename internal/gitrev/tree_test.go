@@ -0,0 +1,239 @@
+package gitrev_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/gitrev"
+	"github.com/DataDog/datadog-sbom-generator/pkg/lockfile"
+)
+
+var signature = &object.Signature{Name: "test", Email: "test@example.com", When: time.Unix(0, 0)}
+
+// commitFiles writes the given files in the working tree of the repository and commits them
+func commitFiles(t *testing.T, repository *git.Repository, files map[string]string) {
+	t.Helper()
+
+	worktree, err := repository.Worktree()
+	require.NoError(t, err)
+
+	root := worktree.Filesystem.Root()
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, name), []byte(content), 0o600))
+		_, err := worktree.Add(name)
+		require.NoError(t, err)
+	}
+
+	_, err = worktree.Commit("commit", &git.CommitOptions{Author: signature})
+	require.NoError(t, err)
+}
+
+func readFile(t *testing.T, tree *gitrev.Tree, name string) string {
+	t.Helper()
+
+	f, err := tree.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+
+	content, err := io.ReadAll(f)
+	require.NoError(t, err)
+
+	return string(content)
+}
+
+func setupRepository(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	repository, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	commitFiles(t, repository, map[string]string{
+		"go.mod":                  "module example.com/v1",
+		"services/api/go.mod":     "module example.com/api/v1",
+		"services/api/docs/a.txt": "a",
+	})
+	head, err := repository.Head()
+	require.NoError(t, err)
+	_, err = repository.CreateTag("v1.0.0", head.Hash(), &git.CreateTagOptions{Tagger: signature, Message: "v1.0.0"})
+	require.NoError(t, err)
+
+	commitFiles(t, repository, map[string]string{
+		"go.mod": "module example.com/v2",
+	})
+
+	// Uncommitted changes are never read
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/dirty"), 0o600))
+
+	return dir
+}
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+
+	dir := setupRepository(t)
+
+	for revision, expected := range map[string]string{
+		"HEAD":   "module example.com/v2",
+		"master": "module example.com/v2",
+		"HEAD~1": "module example.com/v1",
+		"v1.0.0": "module example.com/v1",
+	} {
+		tree, err := gitrev.Open(dir, revision)
+		require.NoError(t, err, revision)
+
+		assert.Equal(t, "/", tree.Dir)
+		assert.Equal(t, expected, readFile(t, tree, "/go.mod"), revision)
+	}
+
+	_, err := gitrev.Open(dir, "unknown")
+	require.Error(t, err)
+}
+
+func TestTree_Files(t *testing.T) {
+	t.Parallel()
+
+	dir := setupRepository(t)
+
+	tree, err := gitrev.Open(dir, "HEAD")
+	require.NoError(t, err)
+
+	files, err := tree.Files(true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/go.mod", "/services/api/docs/a.txt", "/services/api/go.mod"}, files)
+
+	files, err = tree.Files(false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/go.mod"}, files)
+
+	subTree, err := gitrev.Open(filepath.Join(dir, "services", "api"), "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, "/services/api", subTree.Dir)
+
+	files, err = subTree.Files(false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/services/api/go.mod"}, files)
+}
+
+func TestTree_Export(t *testing.T) {
+	t.Parallel()
+
+	dir := setupRepository(t)
+
+	subTree, err := gitrev.Open(filepath.Join(dir, "services", "api"), "v1.0.0")
+	require.NoError(t, err)
+
+	destination := t.TempDir()
+	require.NoError(t, subTree.Export(destination, true, func(string) bool { return true }))
+
+	content, err := os.ReadFile(filepath.Join(destination, "go.mod"))
+	require.NoError(t, err)
+	assert.Equal(t, "module example.com/api/v1", string(content))
+	content, err = os.ReadFile(filepath.Join(destination, "docs", "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(content))
+
+	entries, err := os.ReadDir(destination)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestTree_Export_Include(t *testing.T) {
+	t.Parallel()
+
+	dir := setupRepository(t)
+
+	subTree, err := gitrev.Open(filepath.Join(dir, "services", "api"), "v1.0.0")
+	require.NoError(t, err)
+
+	destination := t.TempDir()
+	require.NoError(t, subTree.Export(destination, true, func(relative string) bool {
+		return filepath.Ext(relative) == ".mod"
+	}))
+
+	entries, err := os.ReadDir(destination)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "go.mod", entries[0].Name())
+}
+
+func TestFile(t *testing.T) {
+	t.Parallel()
+
+	dir := setupRepository(t)
+
+	tree, err := gitrev.Open(dir, "HEAD")
+	require.NoError(t, err)
+
+	f, err := tree.Open("/services/api/go.mod")
+	require.NoError(t, err)
+	defer f.Close()
+
+	nested, err := f.Open("../../go.mod")
+	require.NoError(t, err)
+	defer nested.Close()
+	assert.Equal(t, "/go.mod", nested.Path())
+
+	readerAt, ok := f.(io.ReaderAt)
+	require.True(t, ok)
+	header := make([]byte, 6)
+	_, err = readerAt.ReadAt(header, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "module", string(header))
+
+	content, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Equal(t, "module example.com/api/v1", string(content))
+
+	_, err = tree.Open("/services/missing.txt")
+	require.ErrorIs(t, err, os.ErrNotExist)
+	_, err = tree.Open("/services")
+	require.Error(t, err)
+}
+
+func TestFile_Glob(t *testing.T) {
+	t.Parallel()
+
+	dir := setupRepository(t)
+
+	tree, err := gitrev.Open(dir, "v1.0.0")
+	require.NoError(t, err)
+
+	f, err := tree.Open("/go.mod")
+	require.NoError(t, err)
+	defer f.Close()
+
+	globbable, ok := f.(lockfile.GlobbableDepFile)
+	require.True(t, ok)
+
+	matches, err := globbable.Glob("services/*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/services/api"}, matches)
+
+	matches, err = globbable.Glob("services/api/*/*.txt")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/services/api/docs/a.txt"}, matches)
+}
+
+func TestOpen_BareRepository(t *testing.T) {
+	t.Parallel()
+
+	mirror := filepath.Join(t.TempDir(), "mirror.git")
+	_, err := git.PlainClone(mirror, true, &git.CloneOptions{URL: setupRepository(t), Mirror: true})
+	require.NoError(t, err)
+
+	tree, err := gitrev.Open(mirror, "v1.0.0")
+	require.NoError(t, err)
+
+	assert.Equal(t, "/", tree.Dir)
+	assert.Equal(t, "module example.com/v1", readFile(t, tree, "/go.mod"))
+}
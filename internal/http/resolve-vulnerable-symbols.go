@@ -3,6 +3,7 @@ package http
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -90,3 +91,26 @@ func postResolveVulnerableSymbols(purls []string, baseURL string, ddJwtToken str
 
 	return data, nil
 }
+
+// ParseResolveVulnerableSymbolsResponse reads a response of the resolve-vulnerable-symbols endpoint, either as
+// returned by the API (JSON:API) or as plain JSON, e.g. `{"results": [{"purl": "...", "vulnerable_symbols": [...]}]}`
+func ParseResolveVulnerableSymbolsResponse(content []byte) (ResolveVulnerableSymbolsResponse, error) {
+	data := ResolveVulnerableSymbolsResponse{}
+
+	var document struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(content, &document); err != nil {
+		return data, err
+	}
+
+	if document.Data != nil {
+		err := jsonapi.Unmarshal(content, &data)
+
+		return data, err
+	}
+
+	err := json.Unmarshal(content, &data)
+
+	return data, err
+}
@@ -104,3 +104,59 @@ func createMockServer(statusCode int, data string) *httptest.Server {
 		_, _ = w.Write([]byte(data))
 	}))
 }
+
+func TestParseResolveVulnerableSymbolsResponse(t *testing.T) {
+	t.Parallel()
+
+	jsonAPI := `{
+		"data": {
+			"id": "response",
+			"type": "resolve-vulnerable-symbols-response",
+			"attributes": {
+				"results": [
+					{
+						"purl": "pkg:maven/org.apache.logging.log4j/log4j-core@2.13.0",
+						"vulnerable_symbols": [
+							{
+								"advisory_id": "GHSA-7rjr-3q55-vv33",
+								"symbols": [{"type": "class", "value": "org.apache.logging.log4j", "name": "Logger"}]
+							}
+						]
+					}
+				]
+			}
+		}
+	}`
+	plain := `{
+		"results": [
+			{
+				"purl": "pkg:maven/org.apache.logging.log4j/log4j-core@2.13.0",
+				"vulnerable_symbols": [
+					{
+						"advisory_id": "GHSA-7rjr-3q55-vv33",
+						"symbols": [{"type": "class", "value": "org.apache.logging.log4j", "name": "Logger"}]
+					}
+				]
+			}
+		]
+	}`
+
+	for _, content := range []string{jsonAPI, plain} {
+		resp, err := ParseResolveVulnerableSymbolsResponse([]byte(content))
+		require.NoError(t, err)
+		assert.Equal(t, []SymbolsForPurl{
+			{
+				Purl: "pkg:maven/org.apache.logging.log4j/log4j-core@2.13.0",
+				VulnerableSymbols: []SymbolDetails{
+					{
+						AdvisoryID: "GHSA-7rjr-3q55-vv33",
+						Symbols:    []Symbol{{Type: "class", Value: "org.apache.logging.log4j", Name: "Logger"}},
+					},
+				},
+			},
+		}, resp.Results)
+	}
+
+	_, err := ParseResolveVulnerableSymbolsResponse([]byte("not json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,267 @@
+package image
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/lockfile"
+)
+
+const (
+	whiteoutPrefix = ".wh."
+	opaqueWhiteout = ".wh..wh..opq"
+	// maxSymlinkHops is the maximum number of symbolic links followed to resolve a path, as done by Linux
+	maxSymlinkHops = 40
+)
+
+var errTooManyLinks = errors.New("too many levels of symbolic links")
+
+// fileVersion is the content of a regular file, as written by a layer
+type fileVersion struct {
+	layer   string
+	content *io.SectionReader
+}
+
+type node struct {
+	mode     fs.FileMode
+	linkname string
+	// versions lists the successive contents of a regular file, from the oldest layer to the newest one
+	versions []fileVersion
+}
+
+// FileSystem is the virtual filesystem resulting from applying the layers of an image on top of each other.
+// Files are never loaded in memory, their content is read from the layers when needed.
+type FileSystem struct {
+	nodes map[string]*node
+}
+
+func newFileSystem() *FileSystem {
+	return &FileSystem{nodes: map[string]*node{"/": {mode: fs.ModeDir}}}
+}
+
+// layerEntry is a file of a layer archive
+type layerEntry struct {
+	header  *tar.Header
+	content *io.SectionReader
+}
+
+// apply adds the entries of a layer to the filesystem. As whiteouts only hide files of the lower layers,
+// they are handled before adding the files of the layer itself.
+// See: https://github.com/opencontainers/image-spec/blob/main/layer.md#whiteouts
+func (fsys *FileSystem) apply(layer string, entries []layerEntry) error {
+	for _, entry := range entries {
+		name := cleanPath(entry.header.Name)
+		dir, base := path.Split(name)
+
+		switch {
+		case base == opaqueWhiteout:
+			fsys.removeChildren(path.Clean(dir))
+		case strings.HasPrefix(base, whiteoutPrefix):
+			fsys.remove(path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)))
+		}
+	}
+
+	for _, entry := range entries {
+		name := cleanPath(entry.header.Name)
+		if strings.HasPrefix(path.Base(name), whiteoutPrefix) {
+			continue
+		}
+
+		switch entry.header.Typeflag {
+		case tar.TypeDir:
+			if existing, ok := fsys.nodes[name]; !ok || !existing.mode.IsDir() {
+				fsys.remove(name)
+				fsys.nodes[name] = &node{mode: fs.ModeDir}
+			}
+		case tar.TypeReg, tar.TypeRegA: //nolint:staticcheck // TypeRegA is still produced by old tools
+			fsys.addVersion(name, fileVersion{layer: layer, content: entry.content})
+		case tar.TypeSymlink:
+			fsys.remove(name)
+			fsys.nodes[name] = &node{mode: fs.ModeSymlink, linkname: entry.header.Linkname}
+		case tar.TypeLink:
+			target, ok := fsys.nodes[cleanPath(entry.header.Linkname)]
+			if !ok || len(target.versions) == 0 {
+				return fmt.Errorf("hard link %s targets an unknown file %s", name, entry.header.Linkname)
+			}
+			version := target.versions[len(target.versions)-1]
+			fsys.addVersion(name, fileVersion{layer: layer, content: version.content})
+		}
+	}
+
+	return nil
+}
+
+func (fsys *FileSystem) addVersion(name string, version fileVersion) {
+	existing, ok := fsys.nodes[name]
+	if !ok || !existing.mode.IsRegular() {
+		fsys.remove(name)
+		existing = &node{}
+		fsys.nodes[name] = existing
+	}
+	existing.versions = append(existing.versions, version)
+}
+
+// remove deletes the given path and everything it contains
+func (fsys *FileSystem) remove(name string) {
+	if name == "/" {
+		return
+	}
+	delete(fsys.nodes, name)
+	fsys.removeChildren(name)
+}
+
+func (fsys *FileSystem) removeChildren(dir string) {
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	for name := range fsys.nodes {
+		if strings.HasPrefix(name, prefix) {
+			delete(fsys.nodes, name)
+		}
+	}
+}
+
+// resolve follows the symbolic links of the given absolute path, without ever leaving the filesystem
+func (fsys *FileSystem) resolve(name string) (string, error) {
+	name = cleanPath(name)
+
+	for hops := 0; hops <= maxSymlinkHops; hops++ {
+		parts := strings.Split(strings.TrimPrefix(name, "/"), "/")
+		resolved := "/"
+		followed := false
+
+		for index, part := range parts {
+			current := path.Join(resolved, part)
+			n, ok := fsys.nodes[current]
+			if !ok || n.mode&fs.ModeSymlink == 0 {
+				resolved = current
+				continue
+			}
+
+			target := n.linkname
+			if !path.IsAbs(target) {
+				target = path.Join(resolved, target)
+			}
+			name = cleanPath(path.Join(append([]string{target}, parts[index+1:]...)...))
+			followed = true
+
+			break
+		}
+
+		if !followed {
+			return resolved, nil
+		}
+	}
+
+	return "", errTooManyLinks
+}
+
+// Files returns the paths of all the regular files of the filesystem, sorted
+func (fsys *FileSystem) Files() []string {
+	files := make([]string, 0, len(fsys.nodes))
+	for name, n := range fsys.nodes {
+		if n.mode.IsRegular() {
+			files = append(files, name)
+		}
+	}
+	slices.Sort(files)
+
+	return files
+}
+
+// Open opens the latest version of the file at the given absolute path, following symbolic links
+func (fsys *FileSystem) Open(name string) (lockfile.NestedDepFile, error) {
+	versions, err := fsys.History(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return versions[len(versions)-1], nil
+}
+
+// History returns all the versions of the file at the given absolute path, from the oldest layer to the newest one
+func (fsys *FileSystem) History(name string) ([]*File, error) {
+	resolved, err := fsys.resolve(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	n, ok := fsys.nodes[resolved]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if !n.mode.IsRegular() {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errors.New("not a regular file")}
+	}
+
+	files := make([]*File, 0, len(n.versions))
+	for _, version := range n.versions {
+		files = append(files, &File{
+			SectionReader: io.NewSectionReader(version.content, 0, version.content.Size()),
+			fsys:          fsys,
+			path:          cleanPath(name),
+			layer:         version.layer,
+		})
+	}
+
+	return files, nil
+}
+
+// File is a file of the image filesystem, which can be read by the lockfile extractors
+type File struct {
+	*io.SectionReader
+
+	fsys  *FileSystem
+	path  string
+	layer string
+}
+
+// Open opens a file of the image filesystem, relatively to the current file if the path is relative
+func (f *File) Open(name string) (lockfile.NestedDepFile, error) {
+	if !path.IsAbs(name) {
+		name = path.Join(path.Dir(f.path), name)
+	}
+
+	return f.fsys.Open(name)
+}
+
+// Reopen opens the file again, to read it from the start
+func (f *File) Reopen() (lockfile.NestedDepFile, error) {
+	return f.fsys.Open(f.path)
+}
+
+// Path returns the absolute path of the file in the image
+func (f *File) Path() string {
+	return f.path
+}
+
+// Glob returns the paths of the files and directories of the image filesystem matching the pattern,
+// relatively to the current file if the pattern is relative
+func (f *File) Glob(pattern string) ([]string, error) {
+	if !path.IsAbs(pattern) {
+		pattern = path.Join(path.Dir(f.path), pattern)
+	}
+
+	return lockfile.MatchPaths(f.fsys.Files(), pattern)
+}
+
+// Layer returns the digest of the layer which wrote this version of the file
+func (f *File) Layer() string {
+	return f.layer
+}
+
+func (f *File) Close() error {
+	return nil
+}
+
+func cleanPath(name string) string {
+	return path.Clean("/" + name)
+}
+
+var _ lockfile.NestedDepFile = &File{}
+var _ lockfile.ReopenableDepFile = &File{}
+var _ lockfile.GlobbableDepFile = &File{}
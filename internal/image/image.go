@@ -0,0 +1,383 @@
+// Package image reads container images stored as `docker save` archives or OCI image layouts, and exposes
+// the filesystem resulting from their layers so that it can be scanned by the lockfile extractors.
+package image
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	dockerManifestFile = "manifest.json"
+	ociIndexFile       = "index.json"
+
+	ociImageIndexMediaType    = "application/vnd.oci.image.index.v1+json"
+	dockerManifestListMedia   = "application/vnd.docker.distribution.manifest.list.v2+json"
+	attestationPlatformOSName = "unknown"
+)
+
+var (
+	gzipMagic = []byte{0x1F, 0x8B}
+	zstdMagic = []byte{0x28, 0xB5, 0x2F, 0xFD}
+)
+
+var ErrUnsupportedImage = errors.New("unsupported image format")
+
+// Image is a container image whose layers have been applied into a virtual filesystem
+type Image struct {
+	// Layers lists the digests of the uncompressed layers (also known as diff IDs), from the base layer to the top one
+	Layers     []string
+	FileSystem *FileSystem
+
+	closers []func() error
+}
+
+type dockerManifest struct {
+	Config string   `json:"Config"`
+	Layers []string `json:"Layers"`
+}
+
+type ociPlatform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+}
+
+type ociDescriptor struct {
+	MediaType string       `json:"mediaType"`
+	Digest    string       `json:"digest"`
+	Platform  *ociPlatform `json:"platform,omitempty"`
+}
+
+// ociManifest is either an image manifest or an image index, depending on its media type
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Config    ociDescriptor   `json:"config"`
+	Layers    []ociDescriptor `json:"layers"`
+	Manifests []ociDescriptor `json:"manifests"`
+}
+
+type imageConfig struct {
+	RootFS struct {
+		DiffIDs []string `json:"diff_ids"`
+	} `json:"rootfs"`
+}
+
+// blobStore gives access to the files of an image, either stored in a directory or in an archive
+type blobStore interface {
+	open(name string) (*io.SectionReader, error)
+}
+
+// Load reads the image stored at the given path, which can either be an archive created by `docker save`
+// (or any OCI image layout archived as a tar file) or a directory containing an OCI image layout.
+// The returned image has to be closed to release the temporary files used to decompress its layers.
+func Load(imagePath string) (*Image, error) {
+	img := &Image{FileSystem: newFileSystem()}
+
+	store, err := img.openStore(imagePath)
+	if err != nil {
+		img.Close()
+		return nil, err
+	}
+
+	if err := img.load(store); err != nil {
+		img.Close()
+		return nil, fmt.Errorf("could not read image %s: %w", imagePath, err)
+	}
+
+	return img, nil
+}
+
+// Close releases the files opened to read the image
+func (img *Image) Close() error {
+	var errs []error
+	for index := len(img.closers) - 1; index >= 0; index-- {
+		errs = append(errs, img.closers[index]())
+	}
+	img.closers = nil
+
+	return errors.Join(errs...)
+}
+
+func (img *Image) openStore(imagePath string) (blobStore, error) {
+	info, err := os.Stat(imagePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read image: %w", err)
+	}
+
+	if info.IsDir() {
+		return &dirStore{root: imagePath, image: img}, nil
+	}
+
+	file, err := os.Open(imagePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read image: %w", err)
+	}
+	img.closers = append(img.closers, file.Close)
+
+	entries, err := readTar(io.NewSectionReader(file, 0, info.Size()))
+	if err != nil {
+		return nil, fmt.Errorf("could not read image %s: %w", imagePath, err)
+	}
+
+	store := &tarStore{entries: make(map[string]*io.SectionReader, len(entries))}
+	for _, entry := range entries {
+		if entry.header.Typeflag == tar.TypeReg || entry.header.Typeflag == tar.TypeRegA { //nolint:staticcheck // TypeRegA is still produced by old tools
+			store.entries[path.Clean(entry.header.Name)] = entry.content
+		}
+	}
+
+	return store, nil
+}
+
+func (img *Image) load(store blobStore) error {
+	configName, layerNames, err := findLayers(store)
+	if err != nil {
+		return err
+	}
+
+	var config imageConfig
+	if err := readJSON(store, configName, &config); err != nil {
+		return err
+	}
+
+	for index, layerName := range layerNames {
+		// Layers are identified by their uncompressed digest, as reported by `docker inspect`
+		digest := strings.TrimPrefix(layerName, "blobs/")
+		if index < len(config.RootFS.DiffIDs) {
+			digest = config.RootFS.DiffIDs[index]
+		}
+
+		if err := img.applyLayer(store, digest, layerName); err != nil {
+			return fmt.Errorf("could not apply layer %s: %w", digest, err)
+		}
+		img.Layers = append(img.Layers, digest)
+	}
+
+	return nil
+}
+
+// findLayers returns the names of the config and layers of the image, in the store
+func findLayers(store blobStore) (string, []string, error) {
+	if _, err := store.open(dockerManifestFile); err == nil {
+		var manifests []dockerManifest
+		if err := readJSON(store, dockerManifestFile, &manifests); err != nil {
+			return "", nil, err
+		}
+		if len(manifests) == 0 {
+			return "", nil, fmt.Errorf("%w: %s does not describe any image", ErrUnsupportedImage, dockerManifestFile)
+		}
+		if len(manifests) > 1 {
+			return "", nil, fmt.Errorf("%w: archives with several images are not supported", ErrUnsupportedImage)
+		}
+
+		return manifests[0].Config, manifests[0].Layers, nil
+	}
+
+	var index ociManifest
+	if err := readJSON(store, ociIndexFile, &index); err != nil {
+		return "", nil, fmt.Errorf("%w: neither %s nor %s found", ErrUnsupportedImage, dockerManifestFile, ociIndexFile)
+	}
+
+	manifest, err := resolveManifest(store, index, 0)
+	if err != nil {
+		return "", nil, err
+	}
+
+	layers := make([]string, 0, len(manifest.Layers))
+	for _, layer := range manifest.Layers {
+		layers = append(layers, blobPath(layer.Digest))
+	}
+
+	return blobPath(manifest.Config.Digest), layers, nil
+}
+
+// resolveManifest walks through image indexes until finding the manifest of the image to scan,
+// preferring the one built for the current architecture when there are several platforms
+func resolveManifest(store blobStore, index ociManifest, depth int) (ociManifest, error) {
+	if depth > 4 {
+		return ociManifest{}, fmt.Errorf("%w: too many nested image indexes", ErrUnsupportedImage)
+	}
+
+	var selected *ociDescriptor
+	for i, descriptor := range index.Manifests {
+		if descriptor.Platform != nil && descriptor.Platform.OS == attestationPlatformOSName {
+			continue
+		}
+		if selected == nil {
+			selected = &index.Manifests[i]
+		}
+		if descriptor.Platform != nil && descriptor.Platform.OS == "linux" && descriptor.Platform.Architecture == runtime.GOARCH {
+			selected = &index.Manifests[i]
+			break
+		}
+	}
+	if selected == nil {
+		return ociManifest{}, fmt.Errorf("%w: no image manifest found", ErrUnsupportedImage)
+	}
+
+	var manifest ociManifest
+	if err := readJSON(store, blobPath(selected.Digest), &manifest); err != nil {
+		return ociManifest{}, err
+	}
+
+	if selected.MediaType == ociImageIndexMediaType || selected.MediaType == dockerManifestListMedia || len(manifest.Manifests) > 0 {
+		return resolveManifest(store, manifest, depth+1)
+	}
+
+	return manifest, nil
+}
+
+func (img *Image) applyLayer(store blobStore, digest string, name string) error {
+	blob, err := store.open(name)
+	if err != nil {
+		return err
+	}
+
+	layer, err := img.decompress(blob)
+	if err != nil {
+		return err
+	}
+
+	entries, err := readTar(layer)
+	if err != nil {
+		return err
+	}
+
+	return img.FileSystem.apply(digest, entries)
+}
+
+// decompress returns the uncompressed content of the layer, gzip compressed layers are written in a temporary file
+// so that their files can be read at any time without loading them in memory
+func (img *Image) decompress(blob *io.SectionReader) (*io.SectionReader, error) {
+	magic := make([]byte, len(zstdMagic))
+	n, _ := blob.ReadAt(magic, 0)
+	magic = magic[:n]
+
+	switch {
+	case bytes.HasPrefix(magic, zstdMagic):
+		return nil, fmt.Errorf("%w: zstd compressed layers are not supported", ErrUnsupportedImage)
+	case !bytes.HasPrefix(magic, gzipMagic):
+		return blob, nil
+	}
+
+	reader, err := gzip.NewReader(io.NewSectionReader(blob, 0, blob.Size()))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	file, err := os.CreateTemp("", "datadog-sbom-generator-layer-*.tar")
+	if err != nil {
+		return nil, err
+	}
+	img.closers = append(img.closers, func() error {
+		return errors.Join(file.Close(), os.Remove(file.Name()))
+	})
+
+	size, err := io.Copy(file, reader) //nolint:gosec // layers are trusted as much as the image being scanned
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NewSectionReader(file, 0, size), nil
+}
+
+// countingReader counts the bytes read from the underlying reader, to locate the content of the files of a tar archive
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+
+	return n, err
+}
+
+// readTar lists the entries of a tar archive, their content being read directly from the archive when needed
+func readTar(archive *io.SectionReader) ([]layerEntry, error) {
+	counter := &countingReader{reader: io.NewSectionReader(archive, 0, archive.Size())}
+	reader := tar.NewReader(counter)
+
+	var entries []layerEntry
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, layerEntry{
+			header:  header,
+			content: io.NewSectionReader(archive, counter.count, header.Size),
+		})
+	}
+
+	return entries, nil
+}
+
+func readJSON(store blobStore, name string, value any) error {
+	content, err := store.open(name)
+	if err != nil {
+		return err
+	}
+
+	if err := json.NewDecoder(content).Decode(value); err != nil {
+		return fmt.Errorf("could not parse %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// blobPath returns the location of a blob in an OCI image layout, e.g. sha256:abc -> blobs/sha256/abc
+func blobPath(digest string) string {
+	algorithm, hash, _ := strings.Cut(digest, ":")
+
+	return path.Join("blobs", algorithm, hash)
+}
+
+type dirStore struct {
+	root  string
+	image *Image
+}
+
+func (store *dirStore) open(name string) (*io.SectionReader, error) {
+	file, err := os.Open(filepath.Join(store.root, filepath.FromSlash(path.Clean("/"+name))))
+	if err != nil {
+		return nil, err
+	}
+	store.image.closers = append(store.image.closers, file.Close)
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NewSectionReader(file, 0, info.Size()), nil
+}
+
+type tarStore struct {
+	entries map[string]*io.SectionReader
+}
+
+func (store *tarStore) open(name string) (*io.SectionReader, error) {
+	content, ok := store.entries[path.Clean(name)]
+	if !ok {
+		return nil, fmt.Errorf("open %s: %w", name, os.ErrNotExist)
+	}
+
+	return io.NewSectionReader(content, 0, content.Size()), nil
+}
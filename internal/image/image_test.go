@@ -0,0 +1,295 @@
+package image
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEntry struct {
+	name     string
+	content  string
+	typeflag byte
+	linkname string
+}
+
+type testLayer struct {
+	blob   []byte
+	diffID string
+}
+
+func digestOf(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func writeTar(t *testing.T, w io.Writer, entries []testEntry) {
+	t.Helper()
+
+	tw := tar.NewWriter(w)
+	for _, entry := range entries {
+		typeflag := entry.typeflag
+		if typeflag == 0 {
+			typeflag = tar.TypeReg
+		}
+		header := &tar.Header{Name: entry.name, Typeflag: typeflag, Linkname: entry.linkname, Mode: 0o644}
+		if typeflag == tar.TypeReg {
+			header.Size = int64(len(entry.content))
+		}
+		require.NoError(t, tw.WriteHeader(header))
+		_, err := tw.Write([]byte(entry.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+}
+
+func buildLayer(t *testing.T, compress bool, entries ...testEntry) testLayer {
+	t.Helper()
+
+	var layer bytes.Buffer
+	writeTar(t, &layer, entries)
+	diffID := digestOf(layer.Bytes())
+
+	if !compress {
+		return testLayer{blob: layer.Bytes(), diffID: diffID}
+	}
+
+	var compressed bytes.Buffer
+	gw := gzip.NewWriter(&compressed)
+	_, err := gw.Write(layer.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	return testLayer{blob: compressed.Bytes(), diffID: diffID}
+}
+
+func configOf(t *testing.T, layers []testLayer) []byte {
+	t.Helper()
+
+	var config imageConfig
+	for _, layer := range layers {
+		config.RootFS.DiffIDs = append(config.RootFS.DiffIDs, layer.diffID)
+	}
+	content, err := json.Marshal(config)
+	require.NoError(t, err)
+
+	return content
+}
+
+func writeDockerArchive(t *testing.T, layers ...testLayer) string {
+	t.Helper()
+
+	config := configOf(t, layers)
+	manifest := []dockerManifest{{Config: "config.json"}}
+	entries := []testEntry{{name: "config.json", content: string(config)}}
+	for index, layer := range layers {
+		name := fmt.Sprintf("layer%d/layer.tar", index)
+		manifest[0].Layers = append(manifest[0].Layers, name)
+		entries = append(entries, testEntry{name: name, content: string(layer.blob)})
+	}
+	manifestContent, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	entries = append(entries, testEntry{name: dockerManifestFile, content: string(manifestContent)})
+
+	archivePath := filepath.Join(t.TempDir(), "image.tar")
+	file, err := os.Create(archivePath)
+	require.NoError(t, err)
+	defer file.Close()
+	writeTar(t, file, entries)
+
+	return archivePath
+}
+
+func writeBlob(t *testing.T, dir string, content []byte) string {
+	t.Helper()
+
+	digest := digestOf(content)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "blobs", "sha256"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, blobPath(digest)), content, 0o600))
+
+	return digest
+}
+
+func writeOCILayout(t *testing.T, platform ociPlatform, layers ...testLayer) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	manifest := ociManifest{Config: ociDescriptor{Digest: writeBlob(t, dir, configOf(t, layers))}}
+	for _, layer := range layers {
+		manifest.Layers = append(manifest.Layers, ociDescriptor{Digest: writeBlob(t, dir, layer.blob)})
+	}
+	manifestContent, err := json.Marshal(manifest)
+	require.NoError(t, err)
+
+	// The manifest of the image is referenced by a multi-platform index, next to a build attestation
+	nested := ociManifest{MediaType: ociImageIndexMediaType, Manifests: []ociDescriptor{
+		{Digest: writeBlob(t, dir, []byte(`{"layers": []}`)), Platform: &ociPlatform{OS: attestationPlatformOSName, Architecture: "unknown"}},
+		{Digest: writeBlob(t, dir, manifestContent), Platform: &platform},
+	}}
+	nestedContent, err := json.Marshal(nested)
+	require.NoError(t, err)
+
+	index, err := json.Marshal(ociManifest{Manifests: []ociDescriptor{
+		{MediaType: ociImageIndexMediaType, Digest: writeBlob(t, dir, nestedContent)},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ociIndexFile), index, 0o600))
+
+	return dir
+}
+
+func readFile(t *testing.T, fsys *FileSystem, name string) string {
+	t.Helper()
+
+	f, err := fsys.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+
+	content, err := io.ReadAll(f)
+	require.NoError(t, err)
+
+	return string(content)
+}
+
+func testLayers(t *testing.T) []testLayer {
+	t.Helper()
+
+	return []testLayer{
+		buildLayer(t, false,
+			testEntry{name: "etc/", typeflag: tar.TypeDir},
+			testEntry{name: "etc/os-release", content: "ID=debian\n"},
+			testEntry{name: "var/lib/dpkg/status", content: "Package: bash\n"},
+			testEntry{name: "tmp/cache/a", content: "a"},
+			testEntry{name: "tmp/cache/b", content: "b"},
+			testEntry{name: "root/.bashrc", content: "export PS1"},
+		),
+		buildLayer(t, true,
+			testEntry{name: "var/lib/dpkg/status", content: "Package: bash\n\nPackage: curl\n"},
+			testEntry{name: "tmp/cache/.wh..wh..opq", content: ""},
+			testEntry{name: "tmp/cache/c", content: "c"},
+			testEntry{name: "root/.wh..bashrc", content: ""},
+			testEntry{name: "usr/lib/os-release", typeflag: tar.TypeSymlink, linkname: "../../etc/os-release"},
+			testEntry{name: "bin", typeflag: tar.TypeSymlink, linkname: "/usr/bin"},
+			testEntry{name: "usr/bin/bash", content: "#!"},
+			testEntry{name: "usr/bin/sh", typeflag: tar.TypeLink, linkname: "usr/bin/bash"},
+		),
+	}
+}
+
+func assertImage(t *testing.T, img *Image, layers []testLayer) {
+	t.Helper()
+
+	fsys := img.FileSystem
+
+	assert.Equal(t, []string{layers[0].diffID, layers[1].diffID}, img.Layers)
+	assert.Equal(t, []string{
+		"/etc/os-release",
+		"/tmp/cache/c",
+		"/usr/bin/bash",
+		"/usr/bin/sh",
+		"/var/lib/dpkg/status",
+	}, fsys.Files())
+
+	assert.Equal(t, "Package: bash\n\nPackage: curl\n", readFile(t, fsys, "/var/lib/dpkg/status"))
+	assert.Equal(t, "ID=debian\n", readFile(t, fsys, "/usr/lib/os-release"))
+	assert.Equal(t, "#!", readFile(t, fsys, "/bin/sh"))
+
+	history, err := fsys.History("/var/lib/dpkg/status")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, layers[0].diffID, history[0].Layer())
+	assert.Equal(t, layers[1].diffID, history[1].Layer())
+
+	nested, err := history[0].Open("../../../etc/os-release")
+	require.NoError(t, err)
+	assert.Equal(t, "/etc/os-release", nested.Path())
+
+	_, err = fsys.Open("/root/.bashrc")
+	require.ErrorIs(t, err, os.ErrNotExist)
+	_, err = fsys.Open("/tmp/cache/a")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLoad_DockerArchive(t *testing.T) {
+	t.Parallel()
+
+	layers := testLayers(t)
+
+	img, err := Load(writeDockerArchive(t, layers...))
+	require.NoError(t, err)
+	defer img.Close()
+
+	assertImage(t, img, layers)
+}
+
+func TestLoad_OCILayout(t *testing.T) {
+	t.Parallel()
+
+	layers := testLayers(t)
+
+	img, err := Load(writeOCILayout(t, ociPlatform{OS: "linux", Architecture: "arm"}, layers...))
+	require.NoError(t, err)
+	defer img.Close()
+
+	assertImage(t, img, layers)
+}
+
+func TestLoad_NotAnImage(t *testing.T) {
+	t.Parallel()
+
+	_, err := Load(t.TempDir())
+	require.ErrorIs(t, err, ErrUnsupportedImage)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.tar"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestFileSystem_SymlinkLoop(t *testing.T) {
+	t.Parallel()
+
+	fsys := newFileSystem()
+	require.NoError(t, fsys.apply("layer", []layerEntry{
+		{header: &tar.Header{Name: "a", Typeflag: tar.TypeSymlink, Linkname: "b"}},
+		{header: &tar.Header{Name: "b", Typeflag: tar.TypeSymlink, Linkname: "/a"}},
+	}))
+
+	_, err := fsys.Open("/a")
+	require.ErrorIs(t, err, errTooManyLinks)
+}
+
+func TestFile_Glob(t *testing.T) {
+	t.Parallel()
+
+	fsys := newFileSystem()
+	var entries []layerEntry
+	for _, name := range []string{"app/Cargo.toml", "app/crates/api/Cargo.toml", "app/crates/cli/src/main.rs", "etc/hosts"} {
+		entries = append(entries, layerEntry{
+			header:  &tar.Header{Name: name, Typeflag: tar.TypeReg},
+			content: io.NewSectionReader(bytes.NewReader(nil), 0, 0),
+		})
+	}
+	require.NoError(t, fsys.apply("layer", entries))
+
+	f, err := fsys.Open("/app/Cargo.toml")
+	require.NoError(t, err)
+
+	matches, err := f.(*File).Glob("crates/*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/app/crates/api", "/app/crates/cli"}, matches)
+
+	matches, err = f.(*File).Glob("/etc/*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/etc/hosts"}, matches)
+}
@@ -0,0 +1,152 @@
+package local
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/lockfile"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+	"github.com/DataDog/datadog-sbom-generator/pkg/reporter"
+)
+
+// DB holds OSV advisories loaded from the local filesystem, indexed by the packages they affect
+type DB struct {
+	vulnerabilities map[string][]models.Vulnerability
+}
+
+// Load reads all OSV advisories stored in the given directory, either as plain JSON files or as zip archives
+// of JSON files (such as the ones exported by osv.dev). Files which cannot be parsed are reported and skipped.
+func Load(r reporter.Reporter, path string) (*DB, error) {
+	db := &DB{vulnerabilities: make(map[string][]models.Vulnerability)}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read OSV database: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("could not read OSV database: %s is not a directory", path)
+	}
+
+	err = filepath.WalkDir(path, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".json":
+			content, err := os.ReadFile(path)
+			if err != nil {
+				r.Warnf("Failed to read OSV advisory %s: %v\n", path, err)
+				return nil
+			}
+			db.addFromJSON(r, path, content)
+		case ".zip":
+			db.addFromZip(r, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read OSV database: %w", err)
+	}
+
+	return db, nil
+}
+
+func (db *DB) addFromZip(r reporter.Reporter, path string) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		r.Warnf("Failed to open OSV advisories archive %s: %v\n", path, err)
+		return
+	}
+	defer archive.Close()
+
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || !strings.EqualFold(filepath.Ext(file.Name), ".json") {
+			continue
+		}
+		name := path + ":" + file.Name
+
+		content, err := readZipFile(file)
+		if err != nil {
+			r.Warnf("Failed to read OSV advisory %s: %v\n", name, err)
+			continue
+		}
+		db.addFromJSON(r, name, content)
+	}
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+func (db *DB) addFromJSON(r reporter.Reporter, name string, content []byte) {
+	var vulnerability models.Vulnerability
+	if err := json.Unmarshal(content, &vulnerability); err != nil {
+		r.Warnf("Failed to parse OSV advisory %s: %v\n", name, err)
+		return
+	}
+	if vulnerability.ID == "" {
+		r.Warnf("Failed to parse OSV advisory %s: missing id\n", name)
+		return
+	}
+
+	db.add(vulnerability)
+}
+
+func (db *DB) add(vulnerability models.Vulnerability) {
+	keys := make([]string, 0, len(vulnerability.Affected))
+	for _, affected := range vulnerability.Affected {
+		key := indexKey(affected.Package.Ecosystem, affected.Package.Name)
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	for _, key := range keys {
+		db.vulnerabilities[key] = append(db.vulnerabilities[key], vulnerability)
+	}
+}
+
+// VulnerabilitiesAffecting returns the advisories affecting the given package, sorted by ID
+func (db *DB) VulnerabilitiesAffecting(pkg lockfile.PackageDetails) models.Vulnerabilities {
+	if db == nil {
+		return nil
+	}
+
+	var vulnerabilities models.Vulnerabilities
+	for _, vulnerability := range db.vulnerabilities[indexKey(pkg.Ecosystem, pkg.Name)] {
+		if isAffected(vulnerability, pkg) {
+			vulnerabilities = append(vulnerabilities, vulnerability)
+		}
+	}
+
+	slices.SortFunc(vulnerabilities, func(a, b models.Vulnerability) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return vulnerabilities
+}
+
+// indexKey identifies a package across advisories, ignoring ecosystem releases (such as "Debian:12")
+func indexKey(ecosystem models.Ecosystem, name string) string {
+	base, _, _ := strings.Cut(string(ecosystem), ":")
+
+	return base + "/" + normalizePackageName(models.Ecosystem(base), name)
+}
@@ -0,0 +1,147 @@
+package local_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/local"
+	"github.com/DataDog/datadog-sbom-generator/pkg/lockfile"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+	"github.com/DataDog/datadog-sbom-generator/pkg/reporter"
+)
+
+func vulnerabilityIDs(vulnerabilities models.Vulnerabilities) []string {
+	ids := make([]string, 0, len(vulnerabilities))
+	for _, vulnerability := range vulnerabilities {
+		ids = append(ids, vulnerability.ID)
+	}
+
+	return ids
+}
+
+func TestLoad_NotADirectory(t *testing.T) {
+	t.Parallel()
+
+	_, err := local.Load(&reporter.VoidReporter{}, "fixtures/db/npm/GHSA-0001.json")
+	require.Error(t, err)
+
+	_, err = local.Load(&reporter.VoidReporter{}, "fixtures/does-not-exist")
+	require.Error(t, err)
+}
+
+func TestLoad_ReportsInvalidAdvisories(t *testing.T) {
+	t.Parallel()
+
+	stderr := &bytes.Buffer{}
+	_, err := local.Load(reporter.NewJSONReporter(io.Discard, stderr, reporter.WarnLevel), "fixtures/db")
+	require.NoError(t, err)
+
+	assert.Contains(t, stderr.String(), "invalid.json")
+	assert.NotContains(t, stderr.String(), "README.md")
+}
+
+func TestDB_VulnerabilitiesAffecting(t *testing.T) {
+	t.Parallel()
+
+	db, err := local.Load(&reporter.VoidReporter{}, "fixtures/db")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		pkg      lockfile.PackageDetails
+		expected []string
+	}{
+		{
+			name:     "version before the fix",
+			pkg:      lockfile.PackageDetails{Name: "lodash", Version: "4.17.20", Ecosystem: models.EcosystemNPM},
+			expected: []string{"GHSA-0001"},
+		},
+		{
+			name:     "fixed version",
+			pkg:      lockfile.PackageDetails{Name: "lodash", Version: "4.17.21", Ecosystem: models.EcosystemNPM},
+			expected: []string{},
+		},
+		{
+			name:     "last affected version",
+			pkg:      lockfile.PackageDetails{Name: "minimist", Version: "1.2.5", Ecosystem: models.EcosystemNPM},
+			expected: []string{"GHSA-0002"},
+		},
+		{
+			name:     "after the last affected version",
+			pkg:      lockfile.PackageDetails{Name: "minimist", Version: "1.2.6", Ecosystem: models.EcosystemNPM},
+			expected: []string{},
+		},
+		{
+			name:     "before the introduced version",
+			pkg:      lockfile.PackageDetails{Name: "minimist", Version: "0.2.4", Ecosystem: models.EcosystemNPM},
+			expected: []string{},
+		},
+		{
+			name:     "explicitly listed version with a normalized name",
+			pkg:      lockfile.PackageDetails{Name: "django-rest-framework", Version: "3.0.1", Ecosystem: models.EcosystemPyPI},
+			expected: []string{"PYSEC-0001"},
+		},
+		{
+			name:     "version which is not listed",
+			pkg:      lockfile.PackageDetails{Name: "django-rest-framework", Version: "3.0.2", Ecosystem: models.EcosystemPyPI},
+			expected: []string{},
+		},
+		{
+			name:     "same name in another ecosystem",
+			pkg:      lockfile.PackageDetails{Name: "lodash", Version: "4.17.20", Ecosystem: models.EcosystemPyPI},
+			expected: []string{},
+		},
+		{
+			name:     "package without version",
+			pkg:      lockfile.PackageDetails{Name: "lodash", Version: "", Ecosystem: models.EcosystemNPM},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, vulnerabilityIDs(db.VulnerabilitiesAffecting(tt.pkg)))
+		})
+	}
+}
+
+func TestDB_VulnerabilitiesAffecting_NilDB(t *testing.T) {
+	t.Parallel()
+
+	var db *local.DB
+
+	assert.Empty(t, db.VulnerabilitiesAffecting(lockfile.PackageDetails{Name: "lodash", Version: "4.17.20", Ecosystem: models.EcosystemNPM}))
+}
+
+func TestLoad_ZipArchive(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	archive, err := os.Create(filepath.Join(dir, "all.zip"))
+	require.NoError(t, err)
+
+	writer := zip.NewWriter(archive)
+	content, err := os.ReadFile("fixtures/db/npm/GHSA-0001.json")
+	require.NoError(t, err)
+	file, err := writer.Create("GHSA-0001.json")
+	require.NoError(t, err)
+	_, err = file.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	require.NoError(t, archive.Close())
+
+	db, err := local.Load(&reporter.VoidReporter{}, dir)
+	require.NoError(t, err)
+
+	vulnerabilities := db.VulnerabilitiesAffecting(lockfile.PackageDetails{Name: "lodash", Version: "4.17.20", Ecosystem: models.EcosystemNPM})
+	assert.Equal(t, []string{"GHSA-0001"}, vulnerabilityIDs(vulnerabilities))
+}
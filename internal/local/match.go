@@ -0,0 +1,131 @@
+package local
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/cachedregexp"
+	"github.com/DataDog/datadog-sbom-generator/internal/semantic"
+	"github.com/DataDog/datadog-sbom-generator/pkg/lockfile"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+)
+
+// isAffected checks whether the given package is affected by the given advisory, using either
+// the explicitly listed versions or the SEMVER and ECOSYSTEM ranges of the advisory
+func isAffected(vulnerability models.Vulnerability, pkg lockfile.PackageDetails) bool {
+	if !vulnerability.Withdrawn.IsZero() || pkg.Version == "" {
+		return false
+	}
+
+	for _, affected := range vulnerability.Affected {
+		if !affectsPackage(affected, pkg) {
+			continue
+		}
+		if slices.Contains(affected.Versions, pkg.Version) {
+			return true
+		}
+		for _, affectedRange := range affected.Ranges {
+			if rangeContainsVersion(affectedRange, pkg) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func affectsPackage(affected models.Affected, pkg lockfile.PackageDetails) bool {
+	affectedEcosystem, affectedRelease, _ := strings.Cut(string(affected.Package.Ecosystem), ":")
+	pkgEcosystem, pkgRelease, _ := strings.Cut(string(pkg.Ecosystem), ":")
+
+	if affectedEcosystem != pkgEcosystem {
+		return false
+	}
+	// Only compare releases when both the advisory and the package define one
+	if affectedRelease != "" && pkgRelease != "" && affectedRelease != pkgRelease {
+		return false
+	}
+
+	ecosystem := models.Ecosystem(pkgEcosystem)
+
+	return normalizePackageName(ecosystem, affected.Package.Name) == normalizePackageName(ecosystem, pkg.Name)
+}
+
+// rangeContainsVersion walks through the events of the range, ordered by version, to check if the package version is affected.
+// GIT ranges are not supported, as they require to know the commit history of the package.
+func rangeContainsVersion(affectedRange models.Range, pkg lockfile.PackageDetails) bool {
+	if affectedRange.Type != models.RangeEcosystem && affectedRange.Type != models.RangeSemVer {
+		return false
+	}
+	if len(affectedRange.Events) == 0 {
+		return false
+	}
+
+	ecosystem, _, _ := strings.Cut(string(pkg.Ecosystem), ":")
+	version, err := semantic.Parse(pkg.Version, models.Ecosystem(ecosystem))
+	if err != nil {
+		return false
+	}
+
+	events := slices.Clone(affectedRange.Events)
+	slices.SortStableFunc(events, func(a, b models.Event) int {
+		if a.Introduced == "0" && b.Introduced == "0" {
+			return 0
+		}
+		if a.Introduced == "0" {
+			return -1
+		}
+		if b.Introduced == "0" {
+			return 1
+		}
+		parsed, err := semantic.Parse(eventVersion(a), models.Ecosystem(ecosystem))
+		if err != nil {
+			return 0
+		}
+
+		return parsed.CompareStr(eventVersion(b))
+	})
+
+	affected := false
+	for _, event := range events {
+		if affected {
+			if event.Fixed != "" {
+				affected = version.CompareStr(event.Fixed) < 0
+			} else if event.LastAffected != "" {
+				affected = event.LastAffected == pkg.Version || version.CompareStr(event.LastAffected) <= 0
+			}
+		} else if event.Introduced != "" {
+			affected = event.Introduced == "0" || version.CompareStr(event.Introduced) >= 0
+		}
+	}
+
+	return affected
+}
+
+func eventVersion(event models.Event) string {
+	switch {
+	case event.Introduced != "":
+		return event.Introduced
+	case event.Fixed != "":
+		return event.Fixed
+	case event.LastAffected != "":
+		return event.LastAffected
+	case event.Limit != "":
+		return event.Limit
+	}
+
+	return ""
+}
+
+// normalizePackageName returns the canonical name of a package, for ecosystems where names are not case-sensitive
+func normalizePackageName(ecosystem models.Ecosystem, name string) string {
+	switch ecosystem {
+	case models.EcosystemPyPI:
+		// https://peps.python.org/pep-0503/#normalized-names
+		return strings.ToLower(cachedregexp.MustCompile(`[-_.]+`).ReplaceAllLiteralString(name, "-"))
+	case models.EcosystemNuGet, models.EcosystemPackagist:
+		return strings.ToLower(name)
+	}
+
+	return name
+}
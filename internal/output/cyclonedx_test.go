@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/CycloneDX/cyclonedx-go"
+
 	"github.com/DataDog/datadog-sbom-generator/internal/output"
 	"github.com/DataDog/datadog-sbom-generator/internal/testutility"
 )
@@ -15,7 +17,7 @@ func TestPrintCycloneDX15Results_WithDependencies(t *testing.T) {
 		t.Helper()
 
 		outputWriter := &bytes.Buffer{}
-		err := output.PrintCycloneDXResults(args.vulnResult, outputWriter)
+		err := output.PrintCycloneDXResults(args.vulnResult, cyclonedx.SpecVersion1_5, outputWriter)
 
 		if err != nil {
 			t.Errorf("%v", err)
@@ -32,7 +34,7 @@ func TestPrintCycloneDX15Results_WithVulnerabilities(t *testing.T) {
 		t.Helper()
 
 		outputWriter := &bytes.Buffer{}
-		err := output.PrintCycloneDXResults(args.vulnResult, outputWriter)
+		err := output.PrintCycloneDXResults(args.vulnResult, cyclonedx.SpecVersion1_5, outputWriter)
 
 		if err != nil {
 			t.Errorf("%v", err)
@@ -49,7 +51,126 @@ func TestPrintCycloneDX15Results_WithMixedIssues(t *testing.T) {
 		t.Helper()
 
 		outputWriter := &bytes.Buffer{}
-		err := output.PrintCycloneDXResults(args.vulnResult, outputWriter)
+		err := output.PrintCycloneDXResults(args.vulnResult, cyclonedx.SpecVersion1_5, outputWriter)
+
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+
+		testutility.NewSnapshot().MatchText(t, outputWriter.String())
+	})
+}
+
+func TestPrintCycloneDX15Results_WithDependencyGraph(t *testing.T) {
+	t.Parallel()
+
+	testOutputWithDependencyGraph(t, func(t *testing.T, args outputTestCaseArgs) {
+		t.Helper()
+
+		outputWriter := &bytes.Buffer{}
+		err := output.PrintCycloneDXResults(args.vulnResult, cyclonedx.SpecVersion1_5, outputWriter)
+
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+
+		testutility.NewSnapshot().MatchText(t, outputWriter.String())
+	})
+}
+
+func TestPrintCycloneDX16Results_WithDependencies(t *testing.T) {
+	t.Parallel()
+
+	testOutputWithArtifacts(t, func(t *testing.T, args outputTestCaseArgs) {
+		t.Helper()
+
+		outputWriter := &bytes.Buffer{}
+		err := output.PrintCycloneDXResults(args.vulnResult, cyclonedx.SpecVersion1_6, outputWriter)
+
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+
+		testutility.NewSnapshot().MatchText(t, outputWriter.String())
+	})
+}
+
+func TestPrintCycloneDX16Results_WithVulnerabilities(t *testing.T) {
+	t.Parallel()
+
+	testOutputWithVulnerabilities(t, func(t *testing.T, args outputTestCaseArgs) {
+		t.Helper()
+
+		outputWriter := &bytes.Buffer{}
+		err := output.PrintCycloneDXResults(args.vulnResult, cyclonedx.SpecVersion1_6, outputWriter)
+
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+
+		testutility.NewSnapshot().MatchText(t, outputWriter.String())
+	})
+}
+
+func TestPrintCycloneDX16Results_WithMixedIssues(t *testing.T) {
+	t.Parallel()
+
+	testOutputWithMixedIssues(t, func(t *testing.T, args outputTestCaseArgs) {
+		t.Helper()
+
+		outputWriter := &bytes.Buffer{}
+		err := output.PrintCycloneDXResults(args.vulnResult, cyclonedx.SpecVersion1_6, outputWriter)
+
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+
+		testutility.NewSnapshot().MatchText(t, outputWriter.String())
+	})
+}
+
+func TestPrintCycloneDX16Results_WithDependencyGraph(t *testing.T) {
+	t.Parallel()
+
+	testOutputWithDependencyGraph(t, func(t *testing.T, args outputTestCaseArgs) {
+		t.Helper()
+
+		outputWriter := &bytes.Buffer{}
+		err := output.PrintCycloneDXResults(args.vulnResult, cyclonedx.SpecVersion1_6, outputWriter)
+
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+
+		testutility.NewSnapshot().MatchText(t, outputWriter.String())
+	})
+}
+
+func TestPrintCycloneDX15Results_WithReachableSymbols(t *testing.T) {
+	t.Parallel()
+
+	testOutputWithReachableSymbols(t, func(t *testing.T, args outputTestCaseArgs) {
+		t.Helper()
+
+		outputWriter := &bytes.Buffer{}
+		err := output.PrintCycloneDXResults(args.vulnResult, cyclonedx.SpecVersion1_5, outputWriter)
+
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+
+		testutility.NewSnapshot().MatchText(t, outputWriter.String())
+	})
+}
+
+func TestPrintCycloneDX16Results_WithReachableSymbols(t *testing.T) {
+	t.Parallel()
+
+	testOutputWithReachableSymbols(t, func(t *testing.T, args outputTestCaseArgs) {
+		t.Helper()
+
+		outputWriter := &bytes.Buffer{}
+		err := output.PrintCycloneDXResults(args.vulnResult, cyclonedx.SpecVersion1_6, outputWriter)
 
 		if err != nil {
 			t.Errorf("%v", err)
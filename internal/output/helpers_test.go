@@ -1472,3 +1472,316 @@ func testOutputWithArtifacts(t *testing.T, run outputTestRunner) {
 		})
 	}
 }
+
+func testOutputWithDependencyGraph(t *testing.T, run outputTestRunner) {
+	t.Helper()
+
+	tests := []outputTestCase{
+		{
+			name: "one source with a chain of dependencies",
+			args: outputTestCaseArgs{
+				vulnResult: &models.VulnerabilityResults{
+					Results: []models.PackageSource{
+						{
+							Source: models.SourceInfo{Path: "package-lock.json"},
+							Packages: []models.PackageVulns{
+								{
+									Package: models.PackageInfo{
+										Name:      "mine1",
+										Version:   "1.2.3",
+										Ecosystem: "npm",
+									},
+									Locations: []models.PackageLocations{
+										{
+											Block: models.PackageLocation{
+												Filename:    "package.json",
+												LineStart:   4,
+												LineEnd:     4,
+												ColumnStart: 5,
+												ColumnEnd:   22,
+											},
+										},
+									},
+									Metadata: models.PackageMetadata{
+										models.IsDirectDependencyMetadata: "true",
+									},
+									Dependencies: []string{"pkg:npm/mine2@3.2.5"},
+								},
+								{
+									Package: models.PackageInfo{
+										Name:      "mine2",
+										Version:   "3.2.5",
+										Ecosystem: "npm",
+									},
+									Dependencies: []string{"pkg:npm/mine3@0.4.1"},
+								},
+								{
+									Package: models.PackageInfo{
+										Name:      "mine3",
+										Version:   "0.4.1",
+										Ecosystem: "npm",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "one source with a dependency which was not reported",
+			args: outputTestCaseArgs{
+				vulnResult: &models.VulnerabilityResults{
+					Results: []models.PackageSource{
+						{
+							Source: models.SourceInfo{Path: "package-lock.json"},
+							Packages: []models.PackageVulns{
+								{
+									Package: models.PackageInfo{
+										Name:      "mine1",
+										Version:   "1.2.3",
+										Ecosystem: "npm",
+									},
+									Dependencies: []string{"pkg:npm/mine2@3.2.5", "pkg:npm/unknown@1.0.0"},
+								},
+								{
+									Package: models.PackageInfo{
+										Name:      "mine2",
+										Version:   "3.2.5",
+										Ecosystem: "npm",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "one source with a development dependency",
+			args: outputTestCaseArgs{
+				vulnResult: &models.VulnerabilityResults{
+					Results: []models.PackageSource{
+						{
+							Source: models.SourceInfo{Path: "package-lock.json"},
+							Packages: []models.PackageVulns{
+								{
+									Package: models.PackageInfo{
+										Name:      "mine1",
+										Version:   "1.2.3",
+										Ecosystem: "npm",
+									},
+									DepGroups: []string{"dev"},
+									Metadata: models.PackageMetadata{
+										models.IsDirectDependencyMetadata: "true",
+										models.IsDevDependencyMetadata:    "true",
+									},
+									Dependencies: []string{"pkg:npm/mine2@3.2.5"},
+								},
+								{
+									Package: models.PackageInfo{
+										Name:      "mine2",
+										Version:   "3.2.5",
+										Ecosystem: "npm",
+									},
+									DepGroups: []string{"dev"},
+									Metadata: models.PackageMetadata{
+										models.IsDevDependencyMetadata: "true",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "multiple sources declaring the same direct dependency",
+			args: outputTestCaseArgs{
+				vulnResult: &models.VulnerabilityResults{
+					Results: []models.PackageSource{
+						{
+							Source: models.SourceInfo{Path: "first/package-lock.json"},
+							Packages: []models.PackageVulns{
+								{
+									Package: models.PackageInfo{
+										Name:      "mine1",
+										Version:   "1.2.3",
+										Ecosystem: "npm",
+									},
+									Locations: []models.PackageLocations{
+										{
+											Block: models.PackageLocation{
+												Filename:    "first/package.json",
+												LineStart:   4,
+												LineEnd:     4,
+												ColumnStart: 5,
+												ColumnEnd:   22,
+											},
+										},
+									},
+									Metadata: models.PackageMetadata{
+										models.IsDirectDependencyMetadata: "true",
+									},
+									Dependencies: []string{"pkg:npm/mine2@3.2.5"},
+								},
+								{
+									Package: models.PackageInfo{
+										Name:      "mine2",
+										Version:   "3.2.5",
+										Ecosystem: "npm",
+									},
+								},
+							},
+						},
+						{
+							Source: models.SourceInfo{Path: "second/package-lock.json"},
+							Packages: []models.PackageVulns{
+								{
+									Package: models.PackageInfo{
+										Name:      "mine1",
+										Version:   "1.2.3",
+										Ecosystem: "npm",
+									},
+									Locations: []models.PackageLocations{
+										{
+											Block: models.PackageLocation{
+												Filename:    "second/package.json",
+												LineStart:   7,
+												LineEnd:     7,
+												ColumnStart: 5,
+												ColumnEnd:   22,
+											},
+										},
+									},
+									Metadata: models.PackageMetadata{
+										models.IsDirectDependencyMetadata: "true",
+									},
+									Dependencies: []string{"pkg:npm/mine3@0.4.1"},
+								},
+								{
+									Package: models.PackageInfo{
+										Name:      "mine3",
+										Version:   "0.4.1",
+										Ecosystem: "npm",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "one source with registry, git and path crates",
+			args: outputTestCaseArgs{
+				vulnResult: &models.VulnerabilityResults{
+					Results: []models.PackageSource{
+						{
+							Source: models.SourceInfo{Path: "Cargo.lock"},
+							Packages: []models.PackageVulns{
+								{
+									Package: models.PackageInfo{
+										Name:      "app",
+										Version:   "0.1.0",
+										Ecosystem: "crates.io",
+									},
+									Dependencies: []string{"pkg:cargo/syn@2.0.66", "pkg:cargo/tokio-util@0.7.11"},
+								},
+								{
+									Package: models.PackageInfo{
+										Name:      "syn",
+										Version:   "2.0.66",
+										Ecosystem: "crates.io",
+										Hashes: []models.Hash{
+											{Algorithm: models.HashSHA256, Value: "c42f3f41a2de00b01c0aaad383c5a45241efc8b2d1eda5661812fda5f3cdcff5"},
+										},
+									},
+									Metadata: models.PackageMetadata{
+										models.IsDirectDependencyMetadata: "true",
+									},
+								},
+								{
+									Package: models.PackageInfo{
+										Name:      "tokio-util",
+										Version:   "0.7.11",
+										Ecosystem: "crates.io",
+										Commit:    "0cbf1a5adae81e8ff86ca6d040aeee67cf888262",
+									},
+									Dependencies: []string{"pkg:cargo/syn@2.0.66"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			run(t, tt.args)
+		})
+	}
+}
+
+func testOutputWithReachableSymbols(t *testing.T, run outputTestRunner) {
+	t.Helper()
+
+	tests := []outputTestCase{
+		{
+			name: "one source with one package reachable through multiple advisories",
+			args: outputTestCaseArgs{
+				vulnResult: &models.VulnerabilityResults{
+					Results: []models.PackageSource{
+						{
+							Source: models.SourceInfo{Path: "pom.xml"},
+							Packages: []models.PackageVulns{
+								{
+									Package: models.PackageInfo{
+										Name:      "com.nobody:mine1",
+										Version:   "1.2.3",
+										Ecosystem: "Maven",
+										Purl:      "pkg:maven/com.nobody/mine1@1.2.3",
+									},
+									Locations: []models.PackageLocations{
+										{
+											Block: models.PackageLocation{
+												Filename:    "pom.xml",
+												LineStart:   12,
+												LineEnd:     16,
+												ColumnStart: 5,
+												ColumnEnd:   18,
+											},
+											Version: &models.PackageLocation{
+												Filename:    "pom.xml",
+												LineStart:   15,
+												LineEnd:     15,
+												ColumnStart: 16,
+												ColumnEnd:   21,
+											},
+										},
+									},
+									Metadata: models.PackageMetadata{
+										models.IsDirectDependencyMetadata:                          "true",
+										models.ReachableSymbolLocationMetadata.WithValue("GHSA-1"): `[{"file_name":"src/main/java/App.java","line_start":10,"line_end":10,"column_start":9,"column_end":30,"symbol":"Parser","source_type":"production"}]`,
+										models.ReachableSymbolLocationMetadata.WithValue("GHSA-2"): `[{"file_name":"src/main/java/App.java","line_start":10,"line_end":10,"column_start":9,"column_end":30,"symbol":"Parser","source_type":"production"},{"file_name":"src/test/java/OtherTest.java","line_start":3,"line_end":3,"column_start":1,"column_end":12,"symbol":"Reader","source_type":"test"}]`,
+									},
+									AdvisoriesForReachability: []string{"GHSA-1", "GHSA-2", "GHSA-3"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			run(t, tt.args)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package sbom
 
 import (
+	"encoding/json"
 	"slices"
 	"strings"
 	"time"
@@ -15,10 +16,17 @@ import (
 
 type PackageProcessingHook = func(component *cyclonedx.Component, details models.PackageVulns)
 
-func BuildCycloneDXBom(uniquePackages map[string]models.PackageVulns, artifacts []models.ScannedArtifact) *cyclonedx.BOM {
+// BuildCycloneDXBom creates a CycloneDX BOM following the given specification version.
+// Only 1.5 and 1.6 are supported, any other version falls back to 1.5.
+func BuildCycloneDXBom(uniquePackages map[string]models.PackageVulns, artifacts []models.ScannedArtifact, specVersion cyclonedx.SpecVersion) *cyclonedx.BOM {
 	bom := cyclonedx.NewBOM()
-	bom.JSONSchema = cycloneDx15Schema
-	bom.SpecVersion = cyclonedx.SpecVersion1_5
+	if specVersion == cyclonedx.SpecVersion1_6 {
+		bom.JSONSchema = cycloneDx16Schema
+		bom.SpecVersion = cyclonedx.SpecVersion1_6
+	} else {
+		bom.JSONSchema = cycloneDx15Schema
+		bom.SpecVersion = cyclonedx.SpecVersion1_5
+	}
 
 	components := make([]cyclonedx.Component, 0)
 	bomVulnerabilities := make([]cyclonedx.Vulnerability, 0)
@@ -27,12 +35,19 @@ func BuildCycloneDXBom(uniquePackages map[string]models.PackageVulns, artifacts
 
 	fileComponents, dependsOn := addFileDependencies(artifacts)
 	for packageURL, packageDetail := range uniquePackages {
-		libraryComponent := createLibraryComponent(packageURL, packageDetail)
+		libraryComponent := createLibraryComponent(packageURL, packageDetail, bom.SpecVersion)
 		artifact := findArtifact(packageDetail.Package.Name, packageDetail.Package.Version, artifacts)
 		createFileComponents(packageDetail, artifact, dependsOn)
+		createPackageDependencies(packageURL, packageDetail, uniquePackages, dependsOn)
+		createManifestComponents(packageURL, packageDetail, fileComponents, dependsOn)
 
-		addLocations(&libraryComponent, packageDetail)
-		addVulnerabilities(vulnerabilities, packageDetail)
+		if bom.SpecVersion == cyclonedx.SpecVersion1_6 {
+			addOccurrences(&libraryComponent, packageDetail)
+			addReachableSymbolCallstack(&libraryComponent, packageDetail)
+		} else {
+			addLocations(&libraryComponent, packageDetail)
+		}
+		addVulnerabilities(vulnerabilities, packageURL, packageDetail)
 		addToUniqueAdvisoryAndPurls(uniqueAdvisoryIdsAndUniquePurls, packageDetail)
 
 		components = append(components, libraryComponent)
@@ -88,6 +103,89 @@ func addLocations(component *cyclonedx.Component, details models.PackageVulns) {
 	}
 }
 
+// addOccurrences reports package locations using the native line and offset fields introduced with CycloneDX 1.6
+func addOccurrences(component *cyclonedx.Component, details models.PackageVulns) {
+	occurrences := make([]cyclonedx.EvidenceOccurrence, 0)
+
+	for _, packageLocations := range details.Locations {
+		cleanedLocation := packageLocations.Clean()
+
+		if cleanedLocation == nil {
+			continue
+		}
+		line := cleanedLocation.Block.LineStart
+		offset := cleanedLocation.Block.ColumnStart
+		occurrences = append(occurrences, cyclonedx.EvidenceOccurrence{
+			Location: cleanedLocation.Block.Filename,
+			Line:     &line,
+			Offset:   &offset,
+		})
+	}
+	if len(occurrences) > 0 {
+		if component.Evidence == nil {
+			component.Evidence = &cyclonedx.Evidence{}
+		}
+		component.Evidence.Occurrences = &occurrences
+	}
+}
+
+// addReachableSymbolCallstack reports the locations where vulnerable symbols of the package are used as call stack frames.
+// The module of each frame is the advisory whose symbol is used, and its parameters hold the source type of the location
+// (e.g. source_type=test), so that a location used by several advisories has one frame per advisory.
+func addReachableSymbolCallstack(component *cyclonedx.Component, details models.PackageVulns) {
+	frames := make([]cyclonedx.CallstackFrame, 0)
+
+	for _, metadataType := range slices.Sorted(maps.Keys(details.Metadata)) {
+		advisoryID, ok := strings.CutPrefix(string(metadataType), string(models.ReachableSymbolLocationMetadata.WithValue("")))
+		if !ok {
+			continue
+		}
+		var reachableSymbolLocations models.ReachableSymbolLocations
+		if err := json.Unmarshal([]byte(details.Metadata[metadataType]), &reachableSymbolLocations); err != nil {
+			continue
+		}
+
+		seenFrames := make(map[string]struct{})
+		for _, reachableSymbolLocation := range reachableSymbolLocations {
+			key := reachableSymbolLocation.Symbol + "#" + reachableSymbolLocation.Hash()
+			if _, seen := seenFrames[key]; seen {
+				continue
+			}
+			seenFrames[key] = struct{}{}
+
+			frames = append(frames, buildCallstackFrame(advisoryID, reachableSymbolLocation))
+		}
+	}
+
+	if len(frames) > 0 {
+		if component.Evidence == nil {
+			component.Evidence = &cyclonedx.Evidence{}
+		}
+		component.Evidence.Callstack = &cyclonedx.Callstack{Frames: &frames}
+	}
+}
+
+func buildCallstackFrame(advisoryID string, reachableSymbolLocation models.ReachableSymbolLocation) cyclonedx.CallstackFrame {
+	line := reachableSymbolLocation.LineStart
+	column := reachableSymbolLocation.ColumnStart
+	frame := cyclonedx.CallstackFrame{
+		Module:       advisoryID,
+		Function:     reachableSymbolLocation.Symbol,
+		FullFilename: reachableSymbolLocation.Filename,
+		Line:         &line,
+		Column:       &column,
+	}
+	if reachableSymbolLocation.SourceType != "" {
+		frame.Parameters = &[]string{CallstackSourceTypeParameter + string(reachableSymbolLocation.SourceType)}
+	}
+
+	return frame
+}
+
+func isReachableSymbolLocationMetadata(metadataType models.PackageMetadataType) bool {
+	return strings.HasPrefix(string(metadataType), string(models.ReachableSymbolLocationMetadata))
+}
+
 func buildProperties(metadatas models.PackageMetadata) []cyclonedx.Property {
 	properties := make([]cyclonedx.Property, 0)
 
@@ -96,7 +194,7 @@ func buildProperties(metadatas models.PackageMetadata) []cyclonedx.Property {
 			continue
 		}
 		// TODO(daniel.strong) Remove this conditional when we support datadog-sbom-generator prefixes in all metadata keys.
-		if strings.HasPrefix(string(metadataType), string(models.ReachableSymbolLocationMetadata)) {
+		if isReachableSymbolLocationMetadata(metadataType) || metadataType == models.DependencyPathMetadata {
 			properties = append(properties, cyclonedx.Property{
 				Name:  "datadog-sbom-generator:" + string(metadataType),
 				Value: value,
@@ -147,7 +245,60 @@ func createFileComponents(packageDetail models.PackageVulns, artifact *models.Sc
 	}
 }
 
-func createLibraryComponent(packageURL string, packageDetail models.PackageVulns) cyclonedx.Component {
+// createPackageDependencies reports a dependsOn from a library to each of its resolved dependencies which is part of the BOM
+func createPackageDependencies(packageURL string, packageDetail models.PackageVulns, uniquePackages map[string]models.PackageVulns, dependsOn map[string]cyclonedx.Dependency) {
+	for _, dependencyURL := range packageDetail.Dependencies {
+		if _, ok := uniquePackages[dependencyURL]; !ok {
+			continue
+		}
+		addDependency(dependsOn, packageURL, dependencyURL)
+	}
+}
+
+// createManifestComponents reports the files declaring a direct dependency as root components depending on it
+func createManifestComponents(packageURL string, packageDetail models.PackageVulns, fileComponents map[string]cyclonedx.Component, dependsOn map[string]cyclonedx.Dependency) {
+	if packageDetail.Metadata[models.IsDirectDependencyMetadata] != "true" {
+		return
+	}
+
+	for _, location := range packageDetail.Locations {
+		filename := location.Block.Filename
+		if filename == "" {
+			continue
+		}
+		if _, ok := fileComponents[filename]; !ok {
+			fileComponents[filename] = cyclonedx.Component{
+				Name:   filename,
+				BOMRef: filename,
+				Type:   fileComponentType,
+			}
+		}
+		addDependency(dependsOn, filename, packageURL)
+	}
+}
+
+// addDependency adds a dependsOn from ref to dependency, keeping the dependencies sorted and unique
+func addDependency(dependsOn map[string]cyclonedx.Dependency, ref string, dependency string) {
+	existing, ok := dependsOn[ref]
+	if !ok || existing.Dependencies == nil {
+		dependsOn[ref] = cyclonedx.Dependency{
+			Ref:          ref,
+			Dependencies: &[]string{dependency},
+		}
+
+		return
+	}
+
+	if slices.Contains(*existing.Dependencies, dependency) {
+		return
+	}
+	dependencies := append(*existing.Dependencies, dependency)
+	slices.Sort(dependencies)
+	existing.Dependencies = &dependencies
+	dependsOn[ref] = existing
+}
+
+func createLibraryComponent(packageURL string, packageDetail models.PackageVulns, specVersion cyclonedx.SpecVersion) cyclonedx.Component {
 	component := cyclonedx.Component{}
 
 	component.Type = libraryComponentType
@@ -155,16 +306,40 @@ func createLibraryComponent(packageURL string, packageDetail models.PackageVulns
 	component.PackageURL = packageURL
 	component.Name = packageDetail.Package.Name
 	component.Version = packageDetail.Package.Version
+	if len(packageDetail.Package.Hashes) > 0 {
+		hashes := make([]cyclonedx.Hash, 0, len(packageDetail.Package.Hashes))
+		for _, hash := range packageDetail.Package.Hashes {
+			// The algorithms are named as in CycloneDX
+			hashes = append(hashes, cyclonedx.Hash{Algorithm: cyclonedx.HashAlgorithm(hash.Algorithm), Value: hash.Value})
+		}
+		component.Hashes = &hashes
+	}
 
-	properties := buildProperties(packageDetail.Metadata)
+	metadata := packageDetail.Metadata
+	if specVersion == cyclonedx.SpecVersion1_6 {
+		// Reachable symbol locations are reported as call stack evidence instead
+		metadata = maps.Clone(metadata)
+		maps.DeleteFunc(metadata, func(metadataType models.PackageMetadataType, _ string) bool {
+			return isReachableSymbolLocationMetadata(metadataType)
+		})
+	}
+	properties := buildProperties(metadata)
 	component.Properties = &properties
 
 	return component
 }
 
-func addVulnerabilities(vulnerabilities map[string]cyclonedx.Vulnerability, packageDetail models.PackageVulns) {
+func addVulnerabilities(vulnerabilities map[string]cyclonedx.Vulnerability, packageURL string, packageDetail models.PackageVulns) {
 	for _, vulnerability := range packageDetail.Vulnerabilities {
-		if _, exists := vulnerabilities[vulnerability.ID]; exists {
+		if existing, exists := vulnerabilities[vulnerability.ID]; exists {
+			// The vulnerability affects several packages, let's add the current one
+			affects := append(*existing.Affects, cyclonedx.Affects{Ref: packageURL})
+			slices.SortFunc(affects, func(a, b cyclonedx.Affects) int {
+				return strings.Compare(a.Ref, b.Ref)
+			})
+			existing.Affects = &affects
+			vulnerabilities[vulnerability.ID] = existing
+
 			continue
 		}
 
@@ -177,7 +352,7 @@ func addVulnerabilities(vulnerabilities map[string]cyclonedx.Vulnerability, pack
 			References:  buildReferences(vulnerability),
 			Description: vulnerability.Summary,
 			Detail:      vulnerability.Details,
-			Affects:     buildAffectedPackages(vulnerability),
+			Affects:     &[]cyclonedx.Affects{{Ref: packageURL}},
 			Ratings:     buildRatings(vulnerability),
 			Advisories:  buildAdvisories(vulnerability),
 			Credits:     buildCredits(vulnerability),
@@ -201,6 +376,13 @@ func addToUniqueAdvisoryAndPurls(uniqueAdvisoryIdsAndUniquePurls map[string]map[
 // combineReachableVulnerability converts a map of unique advisory IDs and their affected PURLs into a map of vulnerabilities
 func combineReachableVulnerability(vulnerabilities map[string]cyclonedx.Vulnerability, uniqueAdvisoriesToPurls map[string]map[string]struct{}) {
 	for advisoryID, purlsMap := range uniqueAdvisoriesToPurls {
+		if existing, exists := vulnerabilities[advisoryID]; exists {
+			// The advisory was matched from an OSV database, its details and affected packages are kept
+			existing.BOMRef = advisoryID
+			vulnerabilities[advisoryID] = existing
+
+			continue
+		}
 		if len(purlsMap) == 0 {
 			vulnerabilities[advisoryID] = cyclonedx.Vulnerability{
 				ID:     advisoryID,
@@ -296,18 +478,6 @@ func buildCredits(vulnerability models.Vulnerability) *cyclonedx.Credits {
 	}
 }
 
-func buildAffectedPackages(vulnerability models.Vulnerability) *[]cyclonedx.Affects {
-	affectedPackages := make([]cyclonedx.Affects, len(vulnerability.Affected))
-
-	for index, affected := range vulnerability.Affected {
-		affectedPackages[index] = cyclonedx.Affects{
-			Ref: affected.Package.Purl,
-		}
-	}
-
-	return &affectedPackages
-}
-
 func buildRatings(vulnerability models.Vulnerability) *[]cyclonedx.VulnerabilityRating {
 	ratings := make([]cyclonedx.VulnerabilityRating, len(vulnerability.Severity))
 	for index, severity := range vulnerability.Severity {
@@ -8,6 +8,20 @@ import (
 
 const (
 	cycloneDx15Schema = "http://cyclonedx.org/schema/bom-1.5.schema.json"
+	cycloneDx16Schema = "http://cyclonedx.org/schema/bom-1.6.schema.json"
+)
+
+const (
+	spdxDocumentIdentifier       = "DOCUMENT"
+	spdxDocumentName             = "datadog-sbom-generator"
+	spdxDocumentNamespacePrefix  = "https://spdx.org/spdxdocs/datadog-sbom-generator-"
+	spdxToolCreatorType          = "Tool"
+	spdxToolName                 = "datadog-sbom-generator"
+	spdxNoAssertion              = "NOASSERTION"
+	spdxSourcePackagePurpose     = "SOURCE"
+	spdxAnnotationTypeOther      = "OTHER"
+	spdxLocationAnnotationPrefix = "datadog-sbom-generator:location="
+	spdxAdvisoryURLPrefix        = "https://osv.dev/vulnerability/"
 )
 
 const (
@@ -15,6 +29,10 @@ const (
 	fileComponentType    = "file"
 )
 
+// CallstackSourceTypeParameter prefixes the parameter of CycloneDX 1.6 call stack frames holding the source type
+// of a reachable symbol location, the module of the frames being the advisory whose symbol is used
+const CallstackSourceTypeParameter = "source_type="
+
 var SeverityMapper = map[models.SeverityType]cyclonedx.ScoringMethod{
 	models.SeverityCVSSV2: cyclonedx.ScoringMethodCVSSv2,
 	models.SeverityCVSSV3: cyclonedx.ScoringMethodCVSSv3,
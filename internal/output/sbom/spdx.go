@@ -0,0 +1,299 @@
+package sbom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/spdx/v2/v2_3"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/utility/purl"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+)
+
+// BuildSPDXDocument creates an SPDX 2.3 document out of the unique packages found during the scan.
+//
+// The document describes a root package for each scanned source, which depends on its direct dependencies,
+// or on all its packages when the direct ones are unknown. Development dependencies are reported as such
+// to the root package, and packages depend on their resolved dependencies when the lockfile records them.
+func BuildSPDXDocument(packageSources []models.PackageSource, uniquePackages map[string]models.PackageVulns, created time.Time) *v2_3.Document {
+	createdAt := created.UTC().Format(time.RFC3339)
+	packageURLs := slices.Sorted(maps.Keys(uniquePackages))
+
+	document := &v2_3.Document{
+		SPDXVersion:       v2_3.Version,
+		DataLicense:       v2_3.DataLicense,
+		SPDXIdentifier:    spdxDocumentIdentifier,
+		DocumentName:      spdxDocumentName,
+		DocumentNamespace: buildSPDXDocumentNamespace(packageURLs, createdAt),
+		CreationInfo: &v2_3.CreationInfo{
+			Creators: []common.Creator{{CreatorType: spdxToolCreatorType, Creator: spdxToolName}},
+			Created:  createdAt,
+		},
+		Packages:      make([]*v2_3.Package, 0, len(packageSources)+len(uniquePackages)),
+		Relationships: make([]*v2_3.Relationship, 0),
+	}
+
+	identifiers := buildSPDXIdentifiers(packageURLs)
+	sourcePaths := make([]string, 0, len(packageSources))
+	for _, packageSource := range packageSources {
+		if !slices.Contains(sourcePaths, packageSource.Source.Path) {
+			sourcePaths = append(sourcePaths, packageSource.Source.Path)
+		}
+	}
+	slices.Sort(sourcePaths)
+	sourceIdentifiers := buildSPDXSourceIdentifiers(sourcePaths)
+
+	for _, sourcePath := range sourcePaths {
+		identifier := sourceIdentifiers[sourcePath]
+		document.Packages = append(document.Packages, createSPDXSourcePackage(sourcePath, identifier))
+		document.Relationships = append(document.Relationships, newSPDXRelationship(spdxDocumentIdentifier, identifier, common.TypeRelationshipDescribe))
+	}
+
+	seen := make(map[v2_3.Relationship]struct{})
+	addRelationship := func(from common.ElementID, to common.ElementID, relationshipType string) {
+		relationship := newSPDXRelationship(from, to, relationshipType)
+		if _, ok := seen[*relationship]; ok {
+			return
+		}
+		seen[*relationship] = struct{}{}
+		document.Relationships = append(document.Relationships, relationship)
+	}
+
+	for _, sourcePath := range sourcePaths {
+		root := sourceIdentifiers[sourcePath]
+		for _, dependency := range buildSPDXSourceDependencies(packageSources, sourcePath, identifiers) {
+			if dependency.isDev {
+				addRelationship(dependency.identifier, root, common.TypeRelationshipDevDependencyOf)
+			} else {
+				addRelationship(root, dependency.identifier, common.TypeRelationshipDependsOn)
+			}
+		}
+	}
+
+	for _, packageURL := range packageURLs {
+		packageDetail := uniquePackages[packageURL]
+		identifier := identifiers[packageURL]
+
+		document.Packages = append(document.Packages, createSPDXPackage(packageURL, identifier, packageDetail, createdAt))
+
+		for _, dependencyURL := range packageDetail.Dependencies {
+			if dependencyIdentifier, ok := identifiers[dependencyURL]; ok {
+				addRelationship(identifier, dependencyIdentifier, common.TypeRelationshipDependsOn)
+			}
+		}
+	}
+
+	return document
+}
+
+// spdxSourceDependency is a package a scanned source depends on
+type spdxSourceDependency struct {
+	identifier common.ElementID
+	isDev      bool
+}
+
+// buildSPDXSourceDependencies returns the packages the root package of a scanned source depends on, which are its
+// direct dependencies, or all its packages when none of them is known to be a direct dependency
+func buildSPDXSourceDependencies(packageSources []models.PackageSource, sourcePath string, identifiers map[string]common.ElementID) []spdxSourceDependency {
+	var packages []models.PackageVulns
+	for _, packageSource := range packageSources {
+		if packageSource.Source.Path == sourcePath {
+			packages = append(packages, packageSource.Packages...)
+		}
+	}
+
+	hasDirectDependencies := slices.ContainsFunc(packages, func(pkg models.PackageVulns) bool {
+		return pkg.Metadata[models.IsDirectDependencyMetadata] == strconv.FormatBool(true)
+	})
+
+	dependencies := make([]spdxSourceDependency, 0)
+	for _, pkg := range packages {
+		if hasDirectDependencies && pkg.Metadata[models.IsDirectDependencyMetadata] != strconv.FormatBool(true) {
+			continue
+		}
+		packageURL, err := purl.From(pkg.Package)
+		if err != nil {
+			continue
+		}
+		identifier, ok := identifiers[packageURL.ToString()]
+		if !ok {
+			continue
+		}
+		dependencies = append(dependencies, spdxSourceDependency{
+			identifier: identifier,
+			isDev:      pkg.Metadata[models.IsDevDependencyMetadata] == strconv.FormatBool(true),
+		})
+	}
+	slices.SortFunc(dependencies, func(a, b spdxSourceDependency) int {
+		return strings.Compare(string(a.identifier), string(b.identifier))
+	})
+
+	return dependencies
+}
+
+// createSPDXSourcePackage creates the root package of a scanned source, such as a lockfile or a manifest
+func createSPDXSourcePackage(sourcePath string, identifier common.ElementID) *v2_3.Package {
+	return &v2_3.Package{
+		PackageName:             sourcePath,
+		PackageSPDXIdentifier:   identifier,
+		PackageDownloadLocation: spdxNoAssertion,
+		FilesAnalyzed:           false,
+		PrimaryPackagePurpose:   spdxSourcePackagePurpose,
+	}
+}
+
+func createSPDXPackage(packageURL string, identifier common.ElementID, packageDetail models.PackageVulns, createdAt string) *v2_3.Package {
+	return &v2_3.Package{
+		PackageName:               packageDetail.Package.Name,
+		PackageSPDXIdentifier:     identifier,
+		PackageVersion:            packageDetail.Package.Version,
+		PackageDownloadLocation:   spdxNoAssertion,
+		FilesAnalyzed:             false,
+		PackageChecksums:          buildSPDXChecksums(packageDetail.Package.Hashes),
+		PackageExternalReferences: buildSPDXExternalReferences(packageURL, packageDetail),
+		Annotations:               buildSPDXAnnotations(packageDetail, createdAt),
+	}
+}
+
+// spdxChecksumAlgorithms maps the algorithms of the hashes of the packages to the ones of SPDX
+var spdxChecksumAlgorithms = map[models.HashAlgorithm]common.ChecksumAlgorithm{
+	models.HashSHA256: common.SHA256,
+}
+
+func buildSPDXChecksums(hashes []models.Hash) []common.Checksum {
+	var checksums []common.Checksum
+	for _, hash := range hashes {
+		if algorithm, ok := spdxChecksumAlgorithms[hash.Algorithm]; ok {
+			checksums = append(checksums, common.Checksum{Algorithm: algorithm, Value: hash.Value})
+		}
+	}
+
+	return checksums
+}
+
+// buildSPDXExternalReferences references the PURL of the package, as well as the advisories affecting it
+func buildSPDXExternalReferences(packageURL string, packageDetail models.PackageVulns) []*v2_3.PackageExternalReference {
+	references := []*v2_3.PackageExternalReference{
+		{
+			Category: common.CategoryPackageManager,
+			RefType:  common.TypePackageManagerPURL,
+			Locator:  packageURL,
+		},
+	}
+
+	for _, vulnerability := range packageDetail.Vulnerabilities {
+		references = append(references, &v2_3.PackageExternalReference{
+			Category: common.CategorySecurity,
+			RefType:  common.TypeSecurityAdvisory,
+			Locator:  spdxAdvisoryURLPrefix + vulnerability.ID,
+		})
+	}
+
+	return references
+}
+
+// buildSPDXAnnotations reports package locations and metadata using the same names as the CycloneDX properties
+func buildSPDXAnnotations(packageDetail models.PackageVulns, createdAt string) []v2_3.Annotation {
+	annotations := make([]v2_3.Annotation, 0)
+
+	for _, property := range buildProperties(packageDetail.Metadata) {
+		annotations = append(annotations, newSPDXAnnotation(property.Name+"="+property.Value, createdAt))
+	}
+
+	for _, packageLocations := range packageDetail.Locations {
+		if packageLocations.Clean() == nil {
+			continue
+		}
+		jsonLocation, err := packageLocations.MarshalToJSONString()
+		if err != nil {
+			continue
+		}
+		annotations = append(annotations, newSPDXAnnotation(spdxLocationAnnotationPrefix+jsonLocation, createdAt))
+	}
+
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	return annotations
+}
+
+func newSPDXAnnotation(comment string, createdAt string) v2_3.Annotation {
+	return v2_3.Annotation{
+		Annotator:         common.Annotator{AnnotatorType: spdxToolCreatorType, Annotator: spdxToolName},
+		AnnotationDate:    createdAt,
+		AnnotationType:    spdxAnnotationTypeOther,
+		AnnotationComment: comment,
+	}
+}
+
+func newSPDXRelationship(from common.ElementID, to common.ElementID, relationshipType string) *v2_3.Relationship {
+	return &v2_3.Relationship{
+		RefA:         common.MakeDocElementID("", string(from)),
+		RefB:         common.MakeDocElementID("", string(to)),
+		Relationship: relationshipType,
+	}
+}
+
+// buildSPDXIdentifiers computes a unique SPDX identifier for each package URL
+func buildSPDXIdentifiers(packageURLs []string) map[string]common.ElementID {
+	return buildSPDXUniqueIdentifiers("Package-", packageURLs, func(packageURL string) string {
+		return strings.TrimPrefix(packageURL, "pkg:")
+	})
+}
+
+// buildSPDXSourceIdentifiers computes a unique SPDX identifier for the root package of each scanned source
+func buildSPDXSourceIdentifiers(sourcePaths []string) map[string]common.ElementID {
+	return buildSPDXUniqueIdentifiers("Source-", sourcePaths, func(sourcePath string) string {
+		return sourcePath
+	})
+}
+
+// buildSPDXUniqueIdentifiers computes a unique SPDX identifier for each value.
+// SPDX identifiers can only contain letters, numbers, "." and "-", so other characters are replaced,
+// and a counter is appended when two values end up with the same identifier.
+func buildSPDXUniqueIdentifiers(prefix string, values []string, name func(string) string) map[string]common.ElementID {
+	identifiers := make(map[string]common.ElementID, len(values))
+	used := make(map[common.ElementID]struct{}, len(values))
+
+	for _, value := range values {
+		base := prefix + strings.Map(func(r rune) rune {
+			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '-' {
+				return r
+			}
+
+			return '-'
+		}, name(value))
+
+		identifier := common.ElementID(base)
+		for i := 2; ; i++ {
+			if _, exists := used[identifier]; !exists {
+				break
+			}
+			identifier = common.ElementID(base + "-" + strconv.Itoa(i))
+		}
+
+		used[identifier] = struct{}{}
+		identifiers[value] = identifier
+	}
+
+	return identifiers
+}
+
+// buildSPDXDocumentNamespace computes a namespace which is unique to the scanned packages and the creation date
+func buildSPDXDocumentNamespace(packageURLs []string, createdAt string) string {
+	hash := sha256.New()
+	hash.Write([]byte(createdAt))
+	for _, packageURL := range packageURLs {
+		hash.Write([]byte{'\n'})
+		hash.Write([]byte(packageURL))
+	}
+
+	return spdxDocumentNamespacePrefix + hex.EncodeToString(hash.Sum(nil))
+}
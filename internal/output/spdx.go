@@ -0,0 +1,39 @@
+package output
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	spdxjson "github.com/spdx/tools-golang/json"
+	"github.com/spdx/tools-golang/spdx/v2/v2_3"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/output/sbom"
+	"github.com/DataDog/datadog-sbom-generator/internal/utility/purl"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+)
+
+// This method creates an SPDX 2.3 document and returns it. Error being returned here are from packages being filtered during PURL grouping
+func CreateSPDXDocument(vulnResult *models.VulnerabilityResults) (*v2_3.Document, error) {
+	resultsByPurl, errs := purl.Group(vulnResult.Results)
+
+	return sbom.BuildSPDXDocument(vulnResult.Results, resultsByPurl, time.Now()), errors.Join(errs...)
+}
+
+// PrintSPDXResults writes results to the provided writer in SPDX 2.3 JSON format
+func PrintSPDXResults(vulnResult *models.VulnerabilityResults, outputWriter io.Writer) error {
+	document, errs := CreateSPDXDocument(vulnResult)
+
+	if document == nil {
+		return errs
+	}
+
+	options := make([]spdxjson.WriteOption, 0)
+	if testing.Testing() {
+		options = append(options, spdxjson.Indent("  "))
+	}
+	encodingErr := spdxjson.Write(document, outputWriter, options...)
+
+	return errors.Join(encodingErr, errs)
+}
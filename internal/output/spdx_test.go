@@ -0,0 +1,77 @@
+package output_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/output"
+	"github.com/DataDog/datadog-sbom-generator/internal/testutility"
+)
+
+func TestPrintSPDX23Results_WithDependencies(t *testing.T) {
+	t.Parallel()
+
+	testOutputWithArtifacts(t, func(t *testing.T, args outputTestCaseArgs) {
+		t.Helper()
+
+		outputWriter := &bytes.Buffer{}
+		err := output.PrintSPDXResults(args.vulnResult, outputWriter)
+
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+
+		testutility.NewSnapshot().MatchText(t, testutility.NormalizeSPDXDocument(t, outputWriter.String()))
+	})
+}
+
+func TestPrintSPDX23Results_WithVulnerabilities(t *testing.T) {
+	t.Parallel()
+
+	testOutputWithVulnerabilities(t, func(t *testing.T, args outputTestCaseArgs) {
+		t.Helper()
+
+		outputWriter := &bytes.Buffer{}
+		err := output.PrintSPDXResults(args.vulnResult, outputWriter)
+
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+
+		testutility.NewSnapshot().MatchText(t, testutility.NormalizeSPDXDocument(t, outputWriter.String()))
+	})
+}
+
+func TestPrintSPDX23Results_WithMixedIssues(t *testing.T) {
+	t.Parallel()
+
+	testOutputWithMixedIssues(t, func(t *testing.T, args outputTestCaseArgs) {
+		t.Helper()
+
+		outputWriter := &bytes.Buffer{}
+		err := output.PrintSPDXResults(args.vulnResult, outputWriter)
+
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+
+		testutility.NewSnapshot().MatchText(t, testutility.NormalizeSPDXDocument(t, outputWriter.String()))
+	})
+}
+
+func TestPrintSPDX23Results_WithDependencyGraph(t *testing.T) {
+	t.Parallel()
+
+	testOutputWithDependencyGraph(t, func(t *testing.T, args outputTestCaseArgs) {
+		t.Helper()
+
+		outputWriter := &bytes.Buffer{}
+		err := output.PrintSPDXResults(args.vulnResult, outputWriter)
+
+		if err != nil {
+			t.Errorf("%v", err)
+		}
+
+		testutility.NewSnapshot().MatchText(t, testutility.NormalizeSPDXDocument(t, outputWriter.String()))
+	})
+}
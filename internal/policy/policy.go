@@ -0,0 +1,249 @@
+// Package policy evaluates scan results against user defined rules, so that CI pipelines can be failed
+// when vulnerable packages matching these rules are found.
+package policy
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/output"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+)
+
+const (
+	conditionSeparator       = "&&"
+	severityCondition        = "severity>="
+	reachableCondition       = "reachable"
+	reachableInProdCondition = "reachable-in-production"
+	directCondition          = "direct"
+	prodCondition            = "production"
+)
+
+// Rule is a set of conditions which must all be satisfied by a vulnerability for it to be a violation
+type Rule struct {
+	expression      string
+	minimumSeverity SeverityLevel
+	reachable       bool
+	reachableInProd bool
+	direct          bool
+	production      bool
+}
+
+// Policy is a list of rules, a vulnerability is a violation as soon as it matches one of them
+type Policy []Rule
+
+// Violation describes a vulnerability of a scanned package which matched a rule of the policy
+type Violation struct {
+	Rule       string
+	Source     models.SourceInfo
+	Package    models.PackageInfo
+	AdvisoryID string
+	Severity   SeverityLevel
+	Reachable  bool
+}
+
+// finding is a vulnerability affecting a package, along with everything rules can be evaluated against
+type finding struct {
+	advisoryID string
+	severity   SeverityLevel
+	reachable  bool
+	// reachableInProd tells whether a vulnerable symbol is used by the production code, rather than by tests only
+	reachableInProd bool
+	direct          bool
+	production      bool
+}
+
+// ParseRule parses a rule made of conditions separated by "&&", such as "severity>=high && reachable".
+// Supported conditions are:
+//   - severity>=<low|medium|high|critical>: the vulnerability has at least the given severity
+//   - reachable: a vulnerable symbol of the package is reachable from the scanned code
+//   - reachable-in-production: a vulnerable symbol of the package is reachable from the production code,
+//     rather than from tests only
+//   - direct: the package is a direct dependency
+//   - production: the package is not a development dependency
+func ParseRule(expression string) (Rule, error) {
+	rule := Rule{expression: strings.TrimSpace(expression)}
+
+	for _, condition := range strings.Split(expression, conditionSeparator) {
+		condition = strings.ToLower(strings.Join(strings.Fields(condition), ""))
+
+		switch {
+		case strings.HasPrefix(condition, severityCondition):
+			level, err := ParseSeverityLevel(strings.TrimPrefix(condition, severityCondition))
+			if err != nil {
+				return Rule{}, fmt.Errorf("invalid policy rule %q: %w", rule.expression, err)
+			}
+			rule.minimumSeverity = level
+		case condition == reachableCondition:
+			rule.reachable = true
+		case condition == reachableInProdCondition:
+			rule.reachableInProd = true
+		case condition == directCondition:
+			rule.direct = true
+		case condition == prodCondition:
+			rule.production = true
+		default:
+			return Rule{}, fmt.Errorf("invalid policy rule %q: unknown condition %q", rule.expression, condition)
+		}
+	}
+
+	return rule, nil
+}
+
+// Parse parses all the given rules, an empty policy never reports any violation
+func Parse(expressions []string) (Policy, error) {
+	policy := make(Policy, 0, len(expressions))
+	for _, expression := range expressions {
+		rule, err := ParseRule(expression)
+		if err != nil {
+			return nil, err
+		}
+		policy = append(policy, rule)
+	}
+
+	return policy, nil
+}
+
+func (rule Rule) String() string {
+	return rule.expression
+}
+
+func (rule Rule) matches(f finding) bool {
+	if rule.minimumSeverity != SeverityUnknown && f.severity < rule.minimumSeverity {
+		return false
+	}
+	if rule.reachable && !f.reachable {
+		return false
+	}
+	if rule.reachableInProd && !f.reachableInProd {
+		return false
+	}
+	if rule.direct && !f.direct {
+		return false
+	}
+	if rule.production && !f.production {
+		return false
+	}
+
+	return true
+}
+
+// Evaluate returns the violations found in the given results, sorted by source, package and advisory.
+// A vulnerability matching several rules is only reported once, for the first rule it matches.
+func (policy Policy) Evaluate(results models.VulnerabilityResults) []Violation {
+	if len(policy) == 0 {
+		return nil
+	}
+
+	var violations []Violation
+	for _, source := range results.Results {
+		for _, pkg := range source.Packages {
+			for _, f := range findingsOf(pkg) {
+				index := slices.IndexFunc(policy, func(rule Rule) bool { return rule.matches(f) })
+				if index < 0 {
+					continue
+				}
+				violations = append(violations, Violation{
+					Rule:       policy[index].String(),
+					Source:     source.Source,
+					Package:    pkg.Package,
+					AdvisoryID: f.advisoryID,
+					Severity:   f.severity,
+					Reachable:  f.reachable,
+				})
+			}
+		}
+	}
+
+	slices.SortFunc(violations, func(a, b Violation) int {
+		return cmp.Or(
+			strings.Compare(a.Source.Path, b.Source.Path),
+			strings.Compare(a.Package.Purl, b.Package.Purl),
+			strings.Compare(a.AdvisoryID, b.AdvisoryID),
+		)
+	})
+
+	return violations
+}
+
+// findingsOf lists the vulnerabilities matched for the package as well as the advisories found reachable
+// by the reachability analysis, which are merged with the matching vulnerability when there is one
+func findingsOf(pkg models.PackageVulns) []finding {
+	_, direct := pkg.Metadata[models.IsDirectDependencyMetadata]
+	_, dev := pkg.Metadata[models.IsDevDependencyMetadata]
+
+	findings := make([]finding, 0, len(pkg.Vulnerabilities))
+	for _, vulnerability := range pkg.Vulnerabilities {
+		findings = append(findings, finding{
+			advisoryID: vulnerability.ID,
+			severity:   severityLevelOf(vulnerability),
+			direct:     direct,
+			production: !dev,
+		})
+	}
+
+	reachablePrefix := string(models.ReachableSymbolLocationMetadata.WithValue(""))
+	for key := range pkg.Metadata {
+		advisoryID, isReachable := strings.CutPrefix(string(key), reachablePrefix)
+		if !isReachable {
+			continue
+		}
+
+		index := slices.IndexFunc(pkg.Vulnerabilities, func(vulnerability models.Vulnerability) bool {
+			return vulnerability.ID == advisoryID || slices.Contains(vulnerability.Aliases, advisoryID)
+		})
+		reachableInProd := isReachableInProduction(pkg.Metadata[key])
+		if index >= 0 {
+			findings[index].reachable = true
+			findings[index].reachableInProd = findings[index].reachableInProd || reachableInProd
+			continue
+		}
+		findings = append(findings, finding{
+			advisoryID:      advisoryID,
+			reachable:       true,
+			reachableInProd: reachableInProd,
+			direct:          direct,
+			production:      !dev,
+		})
+	}
+
+	return findings
+}
+
+// isReachableInProduction returns whether some of the reachable symbol locations of an advisory are not in tests,
+// the locations reported without source type being considered as production code
+func isReachableInProduction(locations string) bool {
+	var reachableSymbolLocations models.ReachableSymbolLocations
+	if err := json.Unmarshal([]byte(locations), &reachableSymbolLocations); err != nil {
+		return false
+	}
+
+	return slices.ContainsFunc(reachableSymbolLocations, func(location models.ReachableSymbolLocation) bool {
+		return location.SourceType != models.TestSource
+	})
+}
+
+// Summarize returns a human-readable report of the given violations
+func Summarize(violations []Violation) string {
+	var summary strings.Builder
+
+	fmt.Fprintf(&summary, "Found %d policy %s:\n", len(violations), output.Form(len(violations), "violation", "violations"))
+	for _, violation := range violations {
+		details := []string{violation.Severity.String()}
+		if violation.Reachable {
+			details = append(details, reachableCondition)
+		}
+		fmt.Fprintf(&summary, "  - %s (%s) in %s from %s, matching %q\n",
+			violation.AdvisoryID,
+			strings.Join(details, ", "),
+			cmp.Or(violation.Package.Purl, violation.Package.Name),
+			violation.Source.Path,
+			violation.Rule,
+		)
+	}
+
+	return summary.String()
+}
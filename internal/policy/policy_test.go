@@ -0,0 +1,184 @@
+package policy_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/policy"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+)
+
+func TestParse_InvalidRules(t *testing.T) {
+	t.Parallel()
+
+	for _, expression := range []string{"", "severity>=huge", "severity>high", "reachable && unknown", "&&"} {
+		_, err := policy.Parse([]string{expression})
+		require.Error(t, err, expression)
+	}
+}
+
+func TestParse_ValidRules(t *testing.T) {
+	t.Parallel()
+
+	rules, err := policy.Parse([]string{"severity>=high", " Severity >= Moderate && reachable ", "direct&&production", "reachable-in-production"})
+	require.NoError(t, err)
+	assert.Len(t, rules, 4)
+	assert.Equal(t, "Severity >= Moderate && reachable", rules[1].String())
+}
+
+func testResults() models.VulnerabilityResults {
+	critical := models.Vulnerability{
+		ID:       "GHSA-critical",
+		Aliases:  []string{"CVE-2024-0001"},
+		Severity: []models.Severity{{Type: models.SeverityCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}},
+	}
+	low := models.Vulnerability{
+		ID:               "GHSA-low",
+		DatabaseSpecific: map[string]interface{}{"severity": "LOW"},
+	}
+	unknown := models.Vulnerability{ID: "GHSA-unknown"}
+
+	return models.VulnerabilityResults{
+		Results: []models.PackageSource{
+			{
+				Source: models.SourceInfo{Path: "package-lock.json"},
+				Packages: []models.PackageVulns{
+					{
+						Package:         models.PackageInfo{Name: "direct", Purl: "pkg:npm/direct@1.0.0"},
+						Vulnerabilities: []models.Vulnerability{low, critical},
+						Metadata: models.PackageMetadata{
+							models.IsDirectDependencyMetadata:                                 "true",
+							models.ReachableSymbolLocationMetadata.WithValue("CVE-2024-0001"): `[{"file_name":"src/index.js","symbol":"parse","source_type":"production"}]`,
+						},
+					},
+					{
+						Package:         models.PackageInfo{Name: "dev", Purl: "pkg:npm/dev@1.0.0"},
+						Vulnerabilities: []models.Vulnerability{critical},
+						Metadata: models.PackageMetadata{
+							models.IsDevDependencyMetadata: "true",
+						},
+					},
+					{
+						Package:         models.PackageInfo{Name: "transitive", Purl: "pkg:npm/transitive@1.0.0"},
+						Vulnerabilities: []models.Vulnerability{unknown},
+						Metadata: models.PackageMetadata{
+							models.ReachableSymbolLocationMetadata.WithValue("GHSA-only-reachable"): `[{"file_name":"src/index.test.js","symbol":"parse","source_type":"test"}]`,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+type violationKey struct {
+	Purl       string
+	AdvisoryID string
+}
+
+func violationKeys(violations []policy.Violation) []violationKey {
+	keys := make([]violationKey, 0, len(violations))
+	for _, violation := range violations {
+		keys = append(keys, violationKey{violation.Package.Purl, violation.AdvisoryID})
+	}
+
+	return keys
+}
+
+func TestPolicy_Evaluate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		rules    []string
+		expected []violationKey
+	}{
+		{
+			name:     "empty policy",
+			rules:    nil,
+			expected: []violationKey{},
+		},
+		{
+			name:  "minimum severity",
+			rules: []string{"severity>=high"},
+			expected: []violationKey{
+				{"pkg:npm/dev@1.0.0", "GHSA-critical"},
+				{"pkg:npm/direct@1.0.0", "GHSA-critical"},
+			},
+		},
+		{
+			name:  "low severity from the advisory database",
+			rules: []string{"severity>=low && direct"},
+			expected: []violationKey{
+				{"pkg:npm/direct@1.0.0", "GHSA-critical"},
+				{"pkg:npm/direct@1.0.0", "GHSA-low"},
+			},
+		},
+		{
+			name:  "reachable vulnerabilities, matched through aliases",
+			rules: []string{"reachable"},
+			expected: []violationKey{
+				{"pkg:npm/direct@1.0.0", "GHSA-critical"},
+				{"pkg:npm/transitive@1.0.0", "GHSA-only-reachable"},
+			},
+		},
+		{
+			name:  "reachable vulnerabilities need a known severity to match a severity condition",
+			rules: []string{"reachable && severity>=low"},
+			expected: []violationKey{
+				{"pkg:npm/direct@1.0.0", "GHSA-critical"},
+			},
+		},
+		{
+			name:  "vulnerabilities reachable from the production code rather than from tests only",
+			rules: []string{"reachable-in-production"},
+			expected: []violationKey{
+				{"pkg:npm/direct@1.0.0", "GHSA-critical"},
+			},
+		},
+		{
+			name:  "production dependencies",
+			rules: []string{"severity>=critical && production"},
+			expected: []violationKey{
+				{"pkg:npm/direct@1.0.0", "GHSA-critical"},
+			},
+		},
+		{
+			name:  "vulnerabilities matching several rules are reported once",
+			rules: []string{"severity>=critical", "reachable"},
+			expected: []violationKey{
+				{"pkg:npm/dev@1.0.0", "GHSA-critical"},
+				{"pkg:npm/direct@1.0.0", "GHSA-critical"},
+				{"pkg:npm/transitive@1.0.0", "GHSA-only-reachable"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rules, err := policy.Parse(tt.rules)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, violationKeys(rules.Evaluate(testResults())))
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+
+	rules, err := policy.Parse([]string{"severity>=critical", "reachable"})
+	require.NoError(t, err)
+
+	summary := policy.Summarize(rules.Evaluate(testResults()))
+
+	assert.Equal(t, `Found 3 policy violations:
+  - GHSA-critical (critical) in pkg:npm/dev@1.0.0 from package-lock.json, matching "severity>=critical"
+  - GHSA-critical (critical, reachable) in pkg:npm/direct@1.0.0 from package-lock.json, matching "severity>=critical"
+  - GHSA-only-reachable (unknown, reachable) in pkg:npm/transitive@1.0.0 from package-lock.json, matching "reachable"
+`, summary)
+}
@@ -0,0 +1,130 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	gocvss20 "github.com/pandatix/go-cvss/20"
+	gocvss30 "github.com/pandatix/go-cvss/30"
+	gocvss31 "github.com/pandatix/go-cvss/31"
+	gocvss40 "github.com/pandatix/go-cvss/40"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+)
+
+// SeverityLevel is the qualitative severity rating of a vulnerability, as defined by CVSS
+type SeverityLevel int
+
+const (
+	SeverityUnknown SeverityLevel = iota
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+var severityNames = map[SeverityLevel]string{
+	SeverityUnknown:  "unknown",
+	SeverityLow:      "low",
+	SeverityMedium:   "medium",
+	SeverityHigh:     "high",
+	SeverityCritical: "critical",
+}
+
+func (level SeverityLevel) String() string {
+	return severityNames[level]
+}
+
+// ParseSeverityLevel returns the severity level matching the given name ("moderate" is accepted as GitHub advisories use it)
+func ParseSeverityLevel(name string) (SeverityLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "low":
+		return SeverityLow, nil
+	case "medium", "moderate":
+		return SeverityMedium, nil
+	case "high":
+		return SeverityHigh, nil
+	case "critical":
+		return SeverityCritical, nil
+	}
+
+	return SeverityUnknown, fmt.Errorf("unknown severity %q - must be one of: low, medium, high, critical", name)
+}
+
+// severityLevelFromScore maps a CVSS score to its qualitative rating
+func severityLevelFromScore(score float64) SeverityLevel {
+	switch {
+	case score >= 9.0:
+		return SeverityCritical
+	case score >= 7.0:
+		return SeverityHigh
+	case score >= 4.0:
+		return SeverityMedium
+	case score > 0:
+		return SeverityLow
+	}
+
+	return SeverityUnknown
+}
+
+// severityLevelOf returns the highest severity level reported by the vulnerability, either through its CVSS
+// vectors or through the severity rating of the advisory database (such as GitHub advisories)
+func severityLevelOf(vulnerability models.Vulnerability) SeverityLevel {
+	level := SeverityUnknown
+
+	severities := vulnerability.Severity
+	for _, affected := range vulnerability.Affected {
+		severities = append(severities[:len(severities):len(severities)], affected.Severity...)
+	}
+	for _, severity := range severities {
+		score, err := cvssScore(severity)
+		if err != nil {
+			continue
+		}
+		level = max(level, severityLevelFromScore(score))
+	}
+
+	if rating, ok := vulnerability.DatabaseSpecific["severity"].(string); ok {
+		if databaseLevel, err := ParseSeverityLevel(rating); err == nil {
+			level = max(level, databaseLevel)
+		}
+	}
+
+	return level
+}
+
+func cvssScore(severity models.Severity) (float64, error) {
+	switch severity.Type {
+	case models.SeverityCVSSV2:
+		vector, err := gocvss20.ParseVector(severity.Score)
+		if err != nil {
+			return 0, err
+		}
+
+		return vector.BaseScore(), nil
+	case models.SeverityCVSSV3:
+		if strings.HasPrefix(severity.Score, "CVSS:3.0/") {
+			vector, err := gocvss30.ParseVector(severity.Score)
+			if err != nil {
+				return 0, err
+			}
+
+			return vector.BaseScore(), nil
+		}
+		vector, err := gocvss31.ParseVector(severity.Score)
+		if err != nil {
+			return 0, err
+		}
+
+		return vector.BaseScore(), nil
+	case models.SeverityCVSSV4:
+		vector, err := gocvss40.ParseVector(severity.Score)
+		if err != nil {
+			return 0, err
+		}
+
+		return vector.Score(), nil
+	}
+
+	return 0, fmt.Errorf("unsupported severity type %q", severity.Type)
+}
@@ -30,7 +30,7 @@ func Parse(str string, ecosystem models.Ecosystem) (Version, error) {
 		version = parseSemverVersion(str)
 	case models.EcosystemCratesIO:
 		version = parseSemverVersion(str)
-	case models.EcosystemDebian:
+	case models.EcosystemDebian, models.EcosystemUbuntu:
 		version = parseDebianVersion(str)
 	case models.EcosystemAlpine:
 		version = parseAlpineVersion(str)
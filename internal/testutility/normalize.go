@@ -111,3 +111,13 @@ func NormalizeJSON(t *testing.T, data any) string {
 
 	return NormalizeStdStream(t, bytes.NewBuffer(j))
 }
+
+// NormalizeSPDXDocument replaces the creation date of SPDX documents, and the hash of their namespace which
+// depends on it, with placeholders, as they change on every run
+func NormalizeSPDXDocument(t *testing.T, str string) string {
+	t.Helper()
+
+	str = cachedregexp.MustCompile(`"(created|annotationDate)": "[^"]*"`).ReplaceAllString(str, `"$1": "<date>"`)
+
+	return cachedregexp.MustCompile(`(https://spdx\.org/spdxdocs/datadog-sbom-generator-)[0-9a-f]{64}`).ReplaceAllString(str, "$1<hash>")
+}
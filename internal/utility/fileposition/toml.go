@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
 
 	"github.com/DataDog/datadog-sbom-generator/pkg/models"
 )
@@ -60,3 +63,51 @@ func closeTOMLDependency[P models.IFilePosition](lineNumber int, open *bool, lin
 		_, _ = fmt.Fprintf(os.Stdout, "[DEPENDENCY][END] '%s' at line %d, column %d\n", name, lineEnd, columnEnd)
 	}
 }
+
+// TOMLTableKeys returns the keys of the table declared by a line, such as target, cfg(unix) and dependencies
+// for [target.'cfg(unix)'.dependencies], or false when the line does not declare a table
+func TOMLTableKeys(line string) ([]string, bool) {
+	trimmedLine := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmedLine, "[") {
+		return nil, false
+	}
+
+	var table map[string]any
+	if _, err := toml.Decode(trimmedLine, &table); err != nil {
+		return nil, false
+	}
+
+	var keys []string
+	for len(table) == 1 {
+		for key, value := range table {
+			keys = append(keys, key)
+			switch value := value.(type) {
+			case map[string]any:
+				table = value
+			case []map[string]any:
+				// Arrays of tables, such as [[bin]]
+				table = value[0]
+			default:
+				return nil, false
+			}
+		}
+	}
+
+	return keys, len(keys) > 0
+}
+
+// TOMLKeyValue decodes a line holding a key/value pair, the values of dotted keys (a.b = 1) being nested
+// as the ones of inline tables (a = { b = 1 }), or returns false when the line does not hold a whole pair
+func TOMLKeyValue(line string) (map[string]any, bool) {
+	trimmedLine := strings.TrimSpace(line)
+	if trimmedLine == "" || strings.HasPrefix(trimmedLine, "[") || strings.HasPrefix(trimmedLine, "#") {
+		return nil, false
+	}
+
+	var pair map[string]any
+	if _, err := toml.Decode(trimmedLine, &pair); err != nil || len(pair) == 0 {
+		return nil, false
+	}
+
+	return pair, true
+}
@@ -87,3 +87,47 @@ func TestInTOML(t *testing.T) {
 		}
 	}
 }
+
+func TestTOMLTableKeys(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		line     string
+		expected []string
+	}{
+		{line: `[dependencies]`, expected: []string{"dependencies"}},
+		{line: `  [dependencies.serde] # comment`, expected: []string{"dependencies", "serde"}},
+		{line: `[target.'cfg(target_os = "linux")'.dev-dependencies]`, expected: []string{"target", `cfg(target_os = "linux")`, "dev-dependencies"}},
+		{line: `[[bin]]`, expected: []string{"bin"}},
+		{line: `serde = "1.0"`, expected: nil},
+		{line: `features = [`, expected: nil},
+	}
+
+	for _, tt := range testCases {
+		keys, ok := TOMLTableKeys(tt.line)
+		assert.Equal(t, tt.expected, keys, tt.line)
+		assert.Equal(t, tt.expected != nil, ok, tt.line)
+	}
+}
+
+func TestTOMLKeyValue(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		line     string
+		expected map[string]any
+	}{
+		{line: `serde = "1.0"`, expected: map[string]any{"serde": "1.0"}},
+		{line: `serde = { version = "1.0", default-features = false }`, expected: map[string]any{"serde": map[string]any{"version": "1.0", "default-features": false}}},
+		{line: `serde.workspace = true`, expected: map[string]any{"serde": map[string]any{"workspace": true}}},
+		{line: `[dependencies]`, expected: nil},
+		{line: `# serde = "1.0"`, expected: nil},
+		{line: `features = [`, expected: nil},
+	}
+
+	for _, tt := range testCases {
+		pair, ok := TOMLKeyValue(tt.line)
+		assert.Equal(t, tt.expected, pair, tt.line)
+		assert.Equal(t, tt.expected != nil, ok, tt.line)
+	}
+}
@@ -0,0 +1,40 @@
+package purl
+
+import (
+	"strings"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+
+	"github.com/package-url/packageurl-go"
+)
+
+type osPackageType struct {
+	purlType  string
+	namespace string
+}
+
+// osEcosystemToPURLMapper maps the ecosystems of OS packages to their PURL type and vendor namespace
+var osEcosystemToPURLMapper = map[models.Ecosystem]osPackageType{
+	models.EcosystemDebian: {purlType: packageurl.TypeDebian, namespace: "debian"},
+	models.EcosystemUbuntu: {purlType: packageurl.TypeDebian, namespace: "ubuntu"},
+	models.EcosystemAlpine: {purlType: "apk", namespace: "alpine"},
+}
+
+// FromOSPackage builds the PURL of a package installed by a distribution package manager.
+// The release of the ecosystem (such as "Debian:12" or "Alpine:v3.20") is reported in the distro qualifier.
+func FromOSPackage(packageInfo models.PackageInfo) (*packageurl.PackageURL, bool) {
+	ecosystem, release, _ := strings.Cut(packageInfo.Ecosystem, ":")
+	packageType, ok := osEcosystemToPURLMapper[models.Ecosystem(ecosystem)]
+	if !ok {
+		return nil, false
+	}
+
+	var qualifiers packageurl.Qualifiers
+	if release != "" {
+		qualifiers = packageurl.QualifiersFromMap(map[string]string{
+			"distro": packageType.namespace + "-" + strings.TrimPrefix(release, "v"),
+		})
+	}
+
+	return packageurl.NewPackageURL(packageType.purlType, packageType.namespace, packageInfo.Name, packageInfo.Version, qualifiers, ""), true
+}
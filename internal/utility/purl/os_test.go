@@ -0,0 +1,49 @@
+package purl_test
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/utility/purl"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+)
+
+func TestFromOSPackage(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		packageInfo models.PackageInfo
+		expected    string
+	}{
+		{
+			packageInfo: models.PackageInfo{Name: "curl", Version: "7.88.1-10+deb12u5", Ecosystem: "Debian:12"},
+			expected:    "pkg:deb/debian/curl@7.88.1-10+deb12u5?distro=debian-12",
+		},
+		{
+			packageInfo: models.PackageInfo{Name: "bash", Version: "5.1-6ubuntu1", Ecosystem: "Ubuntu:22.04"},
+			expected:    "pkg:deb/ubuntu/bash@5.1-6ubuntu1?distro=ubuntu-22.04",
+		},
+		{
+			packageInfo: models.PackageInfo{Name: "busybox", Version: "1.36.1-r15", Ecosystem: "Alpine:v3.19"},
+			expected:    "pkg:apk/alpine/busybox@1.36.1-r15?distro=alpine-3.19",
+		},
+		{
+			packageInfo: models.PackageInfo{Name: "busybox", Version: "1.36.1-r15", Ecosystem: string(models.EcosystemAlpine)},
+			expected:    "pkg:apk/alpine/busybox@1.36.1-r15",
+		},
+	}
+
+	for _, testCase := range testCases {
+		packageURL, err := purl.From(testCase.packageInfo)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if packageURL.ToString() != testCase.expected {
+			t.Errorf("got %s; want %s", packageURL.ToString(), testCase.expected)
+		}
+	}
+
+	if _, ok := purl.FromOSPackage(models.PackageInfo{Name: "lodash", Version: "1.0.0", Ecosystem: string(models.EcosystemNPM)}); ok {
+		t.Errorf("npm packages should not be handled as OS packages")
+	}
+}
@@ -33,6 +33,11 @@ func Group(packageSources []models.PackageSource) (map[string]models.PackageVuln
 					packageVulns.Metadata = packageVulns.Metadata.Merge(pkg.Metadata)
 				}
 				packageVulns.AdvisoriesForReachability = append(packageVulns.AdvisoriesForReachability, pkg.AdvisoriesForReachability...)
+				if len(pkg.Dependencies) > 0 {
+					packageVulns.Dependencies = append(packageVulns.Dependencies, pkg.Dependencies...)
+					slices.Sort(packageVulns.Dependencies)
+					packageVulns.Dependencies = slices.Compact(packageVulns.Dependencies)
+				}
 
 				uniquePackages[packageURL.ToString()] = packageVulns
 			} else {
@@ -44,6 +49,7 @@ func Group(packageSources []models.PackageSource) (map[string]models.PackageVuln
 					Vulnerabilities:           slices.Clone(pkg.Vulnerabilities),
 					Metadata:                  pkg.Metadata,
 					AdvisoriesForReachability: pkg.AdvisoriesForReachability,
+					Dependencies:              slices.Clone(pkg.Dependencies),
 				}
 				uniquePackages[packageURL.ToString()] = newPackageVuln
 			}
@@ -197,3 +197,57 @@ func TestGroupPackageByPURL_ShouldReportDependencyAsDirect(t *testing.T) {
 		}
 	}
 }
+
+func TestGroupPackageByPURL_ShouldMergeDependencies(t *testing.T) {
+	t.Parallel()
+	input := []models.PackageSource{
+		{
+			Source: models.SourceInfo{
+				Path: "/first/package-lock.json",
+			},
+			Packages: []models.PackageVulns{
+				{
+					Package: models.PackageInfo{
+						Name:      "the-first-package",
+						Version:   "1.0.0",
+						Ecosystem: string(models.EcosystemNPM),
+					},
+					Dependencies: []string{"pkg:npm/the-third-package@3.0.0", "pkg:npm/the-second-package@2.0.0"},
+				},
+			},
+		},
+		{
+			Source: models.SourceInfo{
+				Path: "/second/package-lock.json",
+			},
+			Packages: []models.PackageVulns{
+				{
+					Package: models.PackageInfo{
+						Name:      "the-first-package",
+						Version:   "1.0.0",
+						Ecosystem: string(models.EcosystemNPM),
+					},
+					Dependencies: []string{"pkg:npm/the-second-package@2.0.0", "pkg:npm/the-fourth-package@4.0.0"},
+				},
+			},
+		},
+	}
+
+	result, errors := purl.Group(input)
+
+	if len(errors) > 0 {
+		t.Errorf("Unexpected errors: %v", errors)
+	}
+	info, exists := result["pkg:npm/the-first-package@1.0.0"]
+	if !exists {
+		t.Fatalf("Expected package pkg:npm/the-first-package@1.0.0 to be in the results")
+	}
+	expected := []string{
+		"pkg:npm/the-fourth-package@4.0.0",
+		"pkg:npm/the-second-package@2.0.0",
+		"pkg:npm/the-third-package@3.0.0",
+	}
+	if !reflect.DeepEqual(info.Dependencies, expected) {
+		t.Errorf("Expected dependencies to be %v, got %v", expected, info.Dependencies)
+	}
+}
@@ -32,6 +32,10 @@ var ecosystemPURLExtractor = map[models.Ecosystem]ParameterExtractor{
 }
 
 func From(packageInfo models.PackageInfo) (*packageurl.PackageURL, error) {
+	if packageURL, ok := FromOSPackage(packageInfo); ok {
+		return packageURL, nil
+	}
+
 	var namespace string
 	var name string
 	version := packageInfo.Version
@@ -56,6 +60,23 @@ func From(packageInfo models.PackageInfo) (*packageurl.PackageURL, error) {
 	return packageurl.NewPackageURL(purlType, namespace, name, version, nil, ""), nil
 }
 
+// ToEcosystem returns the ecosystem of the packages described by the given PURL type and namespace.
+// The release of OS packages is not part of the returned ecosystem.
+func ToEcosystem(packageURL packageurl.PackageURL) (models.Ecosystem, bool) {
+	for ecosystem, packageType := range osEcosystemToPURLMapper {
+		if packageType.purlType == packageURL.Type && packageType.namespace == packageURL.Namespace {
+			return ecosystem, true
+		}
+	}
+	for ecosystem, purlType := range EcosystemToPURLMapper {
+		if purlType == packageURL.Type {
+			return ecosystem, true
+		}
+	}
+
+	return "", false
+}
+
 func FromNameVersionEcosystem(name, version, ecosystem string) (*packageurl.PackageURL, error) {
 	return From(models.PackageInfo{
 		Name:      name,
@@ -0,0 +1,39 @@
+package purl_test
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/utility/purl"
+	"github.com/package-url/packageurl-go"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+)
+
+func TestToEcosystem(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		purl      string
+		ecosystem models.Ecosystem
+		found     bool
+	}{
+		{purl: "pkg:npm/lodash@4.17.21", ecosystem: models.EcosystemNPM, found: true},
+		{purl: "pkg:maven/org.apache/commons@1.0", ecosystem: models.EcosystemMaven, found: true},
+		{purl: "pkg:deb/debian/bash@5.2?distro=debian-12", ecosystem: models.EcosystemDebian, found: true},
+		{purl: "pkg:deb/ubuntu/bash@5.2", ecosystem: models.EcosystemUbuntu, found: true},
+		{purl: "pkg:apk/alpine/busybox@1.36", ecosystem: models.EcosystemAlpine, found: true},
+		{purl: "pkg:generic/openssl@3.0", ecosystem: "", found: false},
+	}
+
+	for _, testCase := range testCases {
+		packageURL, err := packageurl.FromString(testCase.purl)
+		if err != nil {
+			t.Fatalf("invalid PURL %s: %v", testCase.purl, err)
+		}
+
+		ecosystem, found := purl.ToEcosystem(packageURL)
+		if ecosystem != testCase.ecosystem || found != testCase.found {
+			t.Errorf("ToEcosystem(%s) = %q, %v, expected %q, %v", testCase.purl, ecosystem, found, testCase.ecosystem, testCase.found)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"io/fs"
 	"strings"
 
 	"github.com/DataDog/datadog-sbom-generator/pkg/models"
@@ -60,8 +61,13 @@ func ParseApkInstalled(pathToLockfile string) ([]PackageDetails, error) {
 
 type ApkInstalledExtractor struct{}
 
+// apkInstalledPath is the path of the database of installed packages in the root filesystem
+const apkInstalledPath = "/lib/apk/db/installed"
+
+// ShouldExtract matches the database of installed packages relatively to any root directory,
+// so that it can also be found in the extracted filesystem of a container image
 func (e ApkInstalledExtractor) ShouldExtract(path string) bool {
-	return path == "/lib/apk/db/installed"
+	return hasRootPathSuffix(path, apkInstalledPath)
 }
 
 func (e ApkInstalledExtractor) Extract(f DepFile) ([]PackageDetails, error) {
@@ -96,9 +102,15 @@ func (e ApkInstalledExtractor) Extract(f DepFile) ([]PackageDetails, error) {
 }
 
 // alpineReleaseExtractor extracts the release version for an alpine distro
-// will return "" if no release version can be found, or if distro is not alpine
+// will return "" if no release version can be found, or if distro is not alpine.
+// The release file is resolved relatively to the database, so that it is read from the root filesystem holding it
+// rather than from the host.
 func alpineReleaseExtractor(opener DepFile) (string, error) {
-	alpineReleaseFile, err := opener.Open("/etc/alpine-release")
+	if !hasRootPathSuffix(opener.Path(), apkInstalledPath) {
+		return "", fs.ErrNotExist
+	}
+
+	alpineReleaseFile, err := opener.Open("../../../etc/alpine-release")
 	if err != nil {
 		return "", err
 	}
@@ -122,3 +134,7 @@ func alpineReleaseExtractor(opener DepFile) (string, error) {
 }
 
 var _ Extractor = ApkInstalledExtractor{}
+
+func init() {
+	registerOSPackageExtractor("lib/apk/db/installed", ApkInstalledExtractor{})
+}
@@ -82,6 +82,27 @@ func TestParseApkInstalled_Single(t *testing.T) {
 	})
 }
 
+func TestParseApkInstalled_AlpineRelease(t *testing.T) {
+	t.Parallel()
+
+	// The release is read from the root filesystem holding the database, not from the host
+	packages, err := lockfile.ParseApkInstalled("fixtures/apk/rootfs/lib/apk/db/installed")
+
+	if err != nil {
+		t.Errorf("Got unexpected error: %v", err)
+	}
+
+	expectPackages(t, packages, []lockfile.PackageDetails{
+		{
+			Name:           "apk-tools",
+			Version:        "2.12.10-r1",
+			Commit:         "0188f510baadbae393472103427b9c1875117136",
+			Ecosystem:      models.Ecosystem(string(models.EcosystemAlpine) + ":v3.20"),
+			PackageManager: models.Unknown,
+		},
+	})
+}
+
 func TestParseApkInstalled_Shuffled(t *testing.T) {
 	t.Parallel()
 
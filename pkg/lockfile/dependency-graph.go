@@ -0,0 +1,59 @@
+package lockfile
+
+import (
+	"maps"
+	"slices"
+)
+
+/*
+dependencyEdges records which packages depend on which, while a lockfile is being parsed.
+
+Packages are identified by the same key the parser uses to deduplicate them (usually "name@version"),
+as pointers between PackageDetails can only be created once all packages have been collected in their final slice.
+*/
+type dependencyEdges map[string]map[string]struct{}
+
+func (edges dependencyEdges) add(parentKey string, childKey string) {
+	if parentKey == childKey {
+		return
+	}
+
+	if _, ok := edges[parentKey]; !ok {
+		edges[parentKey] = make(map[string]struct{})
+	}
+	edges[parentKey][childKey] = struct{}{}
+}
+
+/*
+collectWithDependencies turns a map of packages indexed by their key into a slice,
+and fills the Dependencies field of each package with pointers to its children in that slice.
+
+Edges pointing to a package which is not part of the map are ignored.
+Packages without any dependency keep a nil Dependencies field.
+*/
+func collectWithDependencies(packages map[string]PackageDetails, edges dependencyEdges) []PackageDetails {
+	keys := slices.Sorted(maps.Keys(packages))
+	result := make([]PackageDetails, 0, len(keys))
+	indexes := make(map[string]int, len(keys))
+
+	for _, key := range keys {
+		indexes[key] = len(result)
+		result = append(result, packages[key])
+	}
+
+	for _, parentKey := range keys {
+		children, ok := edges[parentKey]
+		if !ok {
+			continue
+		}
+
+		parent := &result[indexes[parentKey]]
+		for _, childKey := range slices.Sorted(maps.Keys(children)) {
+			if childIndex, exists := indexes[childKey]; exists {
+				parent.Dependencies = append(parent.Dependencies, &result[childIndex])
+			}
+		}
+	}
+
+	return result
+}
@@ -106,8 +106,10 @@ func ParseDpkgStatus(pathToLockfile string) ([]PackageDetails, error) {
 
 type DpkgStatusExtractor struct{}
 
+// ShouldExtract matches the status file relatively to any root directory,
+// so that it can also be found in the extracted filesystem of a container image
 func (e DpkgStatusExtractor) ShouldExtract(path string) bool {
-	return path == "/var/lib/dpkg/status"
+	return hasRootPathSuffix(path, "/var/lib/dpkg/status")
 }
 
 func (e DpkgStatusExtractor) Extract(f DepFile) ([]PackageDetails, error) {
@@ -164,3 +166,7 @@ func getReleaseVersion(packages []PackageDetails) string {
 }
 
 var _ Extractor = DpkgStatusExtractor{}
+
+func init() {
+	registerOSPackageExtractor("var/lib/dpkg/status", DpkgStatusExtractor{})
+}
@@ -4,12 +4,17 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"sort"
 	"strings"
 )
 
 var lockfileExtractors = map[string]Extractor{}
 
+// osPackageExtractors holds the names of the extractors of the packages installed on an operating system,
+// whose databases are only meaningful at the root of an image filesystem
+var osPackageExtractors = map[string]bool{}
+
 func registerExtractor(name string, extractor Extractor) {
 	if _, ok := lockfileExtractors[name]; ok {
 		panic("an extractor is already registered as " + name)
@@ -18,6 +23,19 @@ func registerExtractor(name string, extractor Extractor) {
 	lockfileExtractors[name] = extractor
 }
 
+// registerOSPackageExtractor registers an extractor of the packages installed on an operating system
+func registerOSPackageExtractor(name string, extractor Extractor) {
+	registerExtractor(name, extractor)
+	osPackageExtractors[name] = true
+}
+
+// IsOSPackageExtractor returns whether the extractor registered with the given name extracts the packages installed
+// on an operating system, which should only be enabled when scanning image filesystems. Otherwise, a database found
+// in a repository (e.g. a test fixture) would be reported as the packages of the scanned project.
+func IsOSPackageExtractor(name string) bool {
+	return osPackageExtractors[name]
+}
+
 func FindExtractor(path string, enabledParsers map[string]bool) (Extractor, string) {
 	for name, extractor := range lockfileExtractors {
 		isEnabled := enabledParsers[name]
@@ -29,6 +47,34 @@ func FindExtractor(path string, enabledParsers map[string]bool) (Extractor, stri
 	return nil, ""
 }
 
+// FindExtractorForFile returns the extractor to use for the given file. Unlike FindExtractor, extractors needing to
+// read the file are able to check files which are not stored on the local filesystem.
+func FindExtractorForFile(f DepFile, enabledParsers map[string]bool) (Extractor, string) {
+	for name, extractor := range lockfileExtractors {
+		if !enabledParsers[name] {
+			continue
+		}
+		if e, ok := extractor.(ExtractorWithContentCheck); ok {
+			if e.ShouldExtractFile(f) {
+				return extractor, name
+			}
+		} else if extractor.ShouldExtract(f.Path()) {
+			return extractor, name
+		}
+	}
+
+	return nil, ""
+}
+
+// reopenDepFile opens the given file again, to read it from the start
+func reopenDepFile(f DepFile) (NestedDepFile, error) {
+	if reopenable, ok := f.(ReopenableDepFile); ok {
+		return reopenable.Reopen()
+	}
+
+	return OpenLocalDepFile(f.Path())
+}
+
 func ListExtractors() []string {
 	es := make([]string, 0, len(lockfileExtractors))
 
@@ -46,7 +92,7 @@ func ListExtractors() []string {
 var ErrExtractorNotFound = errors.New("could not determine extractor")
 
 func ExtractDeps(f DepFile, enabledParsers map[string]bool) (Lockfile, error) {
-	extractor, extractedAs := FindExtractor(f.Path(), enabledParsers)
+	extractor, extractedAs := FindExtractorForFile(f, enabledParsers)
 
 	if extractor == nil {
 		return Lockfile{}, fmt.Errorf("%w for %s", ErrExtractorNotFound, f.Path())
@@ -71,6 +117,8 @@ func ExtractDeps(f DepFile, enabledParsers map[string]bool) (Lockfile, error) {
 		}
 	}
 
+	// Sort a copy, so that Dependencies pointers keep targeting the packages they were created for
+	packages = slices.Clone(packages)
 	sort.Slice(packages, func(i, j int) bool {
 		if packages[i].Name == packages[j].Name {
 			return packages[i].Version < packages[j].Version
@@ -85,7 +133,7 @@ func ExtractDeps(f DepFile, enabledParsers map[string]bool) (Lockfile, error) {
 		Packages: packages,
 	}
 
-	depFile, err := OpenLocalDepFile(f.Path())
+	depFile, err := reopenDepFile(f)
 	if err != nil {
 		return parsedLockfile, err
 	}
@@ -3,6 +3,7 @@ package lockfile_test
 import (
 	"errors"
 	"io"
+	"slices"
 	"strings"
 	"testing"
 
@@ -171,3 +172,29 @@ func TestDisabledExtractor(t *testing.T) {
 		t.Errorf("Expected no extractor to be found but one has been found (%s)", extractedAs)
 	}
 }
+
+func TestMatchPaths(t *testing.T) {
+	t.Parallel()
+
+	files := []string{"/Cargo.toml", "/crates/api/Cargo.toml", "/crates/cli/src/main.rs", "/docs/README.md"}
+
+	matches, err := lockfile.MatchPaths(files, "/crates/*")
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if expected := []string{"/crates/api", "/crates/cli"}; !slices.Equal(matches, expected) {
+		t.Errorf("Expected %v, got %v", expected, matches)
+	}
+
+	matches, err = lockfile.MatchPaths(files, "/*/*/Cargo.toml")
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if expected := []string{"/crates/api/Cargo.toml"}; !slices.Equal(matches, expected) {
+		t.Errorf("Expected %v, got %v", expected, matches)
+	}
+
+	if _, err := lockfile.MatchPaths(files, "/crates/["); err == nil {
+		t.Errorf("Expected an error for a malformed pattern")
+	}
+}
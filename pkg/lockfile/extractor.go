@@ -1,10 +1,13 @@
 package lockfile
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 
 	"github.com/DataDog/datadog-sbom-generator/pkg/models"
 
@@ -34,12 +37,58 @@ type NestedDepFile interface {
 	DepFile
 }
 
+// ReopenableDepFile is a DepFile which is not stored on the local filesystem, and knows how to open itself again
+// to be read from the start.
+type ReopenableDepFile interface {
+	DepFile
+	Reopen() (NestedDepFile, error)
+}
+
+// GlobbableDepFile is a DepFile which knows how to list the paths of its filesystem, such as the directories matched
+// by the globs of the members of a workspace
+type GlobbableDepFile interface {
+	DepFile
+	// Glob returns the paths of the files and directories matching the pattern, in the form returned by Path.
+	// The pattern has the syntax of path.Match, and is relative to the directory of the current DepFile unless it is absolute.
+	Glob(pattern string) ([]string, error)
+}
+
+// MatchPaths returns the paths of the given files, and of their parent directories, matching an absolute pattern
+// with the syntax of path.Match. It expands globs in the filesystems which are listed rather than walked.
+func MatchPaths(files []string, pattern string) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	visited := make(map[string]bool)
+	for _, file := range files {
+		for name := file; name != "/" && name != "." && !visited[name]; name = path.Dir(name) {
+			visited[name] = true
+			if matched, _ := path.Match(pattern, name); matched {
+				matches = append(matches, name)
+			}
+		}
+	}
+	slices.Sort(matches)
+
+	return matches, nil
+}
+
 type Extractor interface {
 	// ShouldExtract checks if the Extractor should be used for the given path.
 	ShouldExtract(path string) bool
 	Extract(f DepFile) ([]PackageDetails, error)
 }
 
+// ExtractorWithContentCheck is an Extractor which needs to read the file to know if it should be used,
+// as the path is not enough to identify the files it supports.
+type ExtractorWithContentCheck interface {
+	Extractor
+	// ShouldExtractFile checks if the Extractor should be used for the given file, without consuming its content.
+	ShouldExtractFile(f DepFile) bool
+}
+
 type WithMatcher struct {
 	Matchers []Matcher
 }
@@ -75,6 +124,25 @@ func (f LocalFile) Open(path string) (NestedDepFile, error) {
 
 func (f LocalFile) Path() string { return f.path }
 
+func (f LocalFile) Glob(pattern string) ([]string, error) {
+	pattern = filepath.FromSlash(pattern)
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(filepath.Dir(f.path), pattern)
+	}
+
+	return filepath.Glob(pattern)
+}
+
+// ReadAt reads the file at the given position, without decoding it nor consuming it
+func (f LocalFile) ReadAt(p []byte, off int64) (int, error) {
+	readerAt, ok := f.Closer.(io.ReaderAt)
+	if !ok {
+		return 0, errors.ErrUnsupported
+	}
+
+	return readerAt.ReadAt(p, off)
+}
+
 func OpenLocalDepFile(path string) (NestedDepFile, error) {
 	r, err := os.Open(path)
 
@@ -94,6 +162,7 @@ func OpenLocalDepFile(path string) (NestedDepFile, error) {
 
 var _ DepFile = LocalFile{}
 var _ NestedDepFile = LocalFile{}
+var _ GlobbableDepFile = LocalFile{}
 
 func ExtractFromFile(pathToLockfile string, extractor Extractor) ([]PackageDetails, error) {
 	f, err := OpenLocalDepFile(pathToLockfile)
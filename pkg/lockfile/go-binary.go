@@ -3,38 +3,76 @@ package lockfile
 import (
 	"bytes"
 	"debug/buildinfo"
+	"fmt"
 	"io"
-	"path/filepath"
+	"os"
 	"strings"
 
 	"github.com/DataDog/datadog-sbom-generator/pkg/models"
 )
 
-type GoBinaryExtractor struct{}
+type GoBinaryExtractor struct {
+	ArtifactExtractor
+}
+
+const magicLength = 4
+
+// executableMagics lists the magic bytes of the executable formats supported by debug/buildinfo
+var executableMagics = [][]byte{
+	[]byte("\x7FELF"),        // ELF
+	[]byte("MZ"),             // PE
+	{0xFE, 0xED, 0xFA, 0xCE}, // Mach-O 32-bit big endian
+	{0xFE, 0xED, 0xFA, 0xCF}, // Mach-O 64-bit big endian
+	{0xCE, 0xFA, 0xED, 0xFE}, // Mach-O 32-bit little endian
+	{0xCF, 0xFA, 0xED, 0xFE}, // Mach-O 64-bit little endian
+}
 
+// ShouldExtract keeps all the regular files, as Go binaries do not have any specific name nor permissions, e.g. versioned
+// release binaries (svc-1.2.3) or executables copied out of an archive. Whether they are Go binaries is checked by
+// ShouldExtractFile, from their magic bytes and build information.
 func (e GoBinaryExtractor) ShouldExtract(path string) bool {
 	if path == "" {
 		return false
 	}
 
-	if strings.HasSuffix(path, string(filepath.Separator)) { // Don't extract directories
-		return false
+	info, err := os.Stat(path)
+
+	return err == nil && info.Mode().IsRegular()
+}
+
+// ShouldExtractFile checks that the file is an executable embedding Go build information. Files which cannot be read
+// without consuming them are only checked by their path.
+func (e GoBinaryExtractor) ShouldExtractFile(f DepFile) bool {
+	readerAt, ok := f.(io.ReaderAt)
+	if !ok {
+		return e.ShouldExtract(f.Path())
 	}
 
-	if filepath.Ext(path) != ".exe" && filepath.Ext(path) != "" {
-		// Assume if a file has an extension (that's not exe), it is not a go binary
-		// This also filters out hidden files on Unix
-		// This is a heuristic to improve performance and can result in false negatives
-		// TODO(another-rex): When we have access to the full FS interface, we can open and check
-		// magic bytes to be more accurate
+	if !isExecutable(io.NewSectionReader(readerAt, 0, magicLength)) {
 		return false
 	}
+	_, err := buildinfo.Read(readerAt)
 
-	// Any other path can be a go binary
-	return true
+	return err == nil
 }
 
-func (e GoBinaryExtractor) Extract(f DepFile) ([]PackageDetails, error) {
+func isExecutable(r io.Reader) bool {
+	header := make([]byte, magicLength)
+	n, _ := io.ReadFull(r, header)
+	header = header[:n]
+
+	for _, magic := range executableMagics {
+		if bytes.HasPrefix(header, magic) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readBuildInfo reads the build information of the binary at random positions, which does not load local files,
+// nor the files of container images, in memory
+func readBuildInfo(f DepFile) (*buildinfo.BuildInfo, error) {
 	var readerAt io.ReaderAt
 	if fileWithReaderAt, ok := f.(io.ReaderAt); ok {
 		readerAt = fileWithReaderAt
@@ -42,14 +80,23 @@ func (e GoBinaryExtractor) Extract(f DepFile) ([]PackageDetails, error) {
 		buf := bytes.NewBuffer([]byte{})
 		_, err := io.Copy(buf, f)
 		if err != nil {
-			return []PackageDetails{}, err
+			return nil, err
 		}
 		readerAt = bytes.NewReader(buf.Bytes())
 	}
 
 	info, err := buildinfo.Read(readerAt)
 	if err != nil {
-		return []PackageDetails{}, ErrIncompatibleFileFormat
+		return nil, ErrIncompatibleFileFormat
+	}
+
+	return info, nil
+}
+
+func (e GoBinaryExtractor) Extract(f DepFile) ([]PackageDetails, error) {
+	info, err := readBuildInfo(f)
+	if err != nil {
+		return []PackageDetails{}, err
 	}
 
 	pkgs := make([]PackageDetails, 0, len(info.Deps)+1)
@@ -75,4 +122,35 @@ func (e GoBinaryExtractor) Extract(f DepFile) ([]PackageDetails, error) {
 	return pkgs, nil
 }
 
+// GetArtifact reports the main module the binary has been built from
+func (e GoBinaryExtractor) GetArtifact(f DepFile) (*models.ScannedArtifact, error) {
+	info, err := readBuildInfo(f)
+	if err != nil {
+		return nil, err
+	}
+	if info.Main.Path == "" {
+		return nil, fmt.Errorf("no main module found in %s", f.Path())
+	}
+
+	version := strings.TrimPrefix(info.Main.Version, "v")
+	if version == "(devel)" { // Binaries built from a local checkout do not have any version
+		version = ""
+	}
+
+	return &models.ScannedArtifact{
+		ArtifactDetail: models.ArtifactDetail{
+			Name:      info.Main.Path,
+			Version:   version,
+			Filename:  f.Path(),
+			Ecosystem: models.EcosystemGo,
+		},
+	}, nil
+}
+
 var _ Extractor = GoBinaryExtractor{}
+var _ ExtractorWithContentCheck = GoBinaryExtractor{}
+var _ ArtifactExtractor = GoBinaryExtractor{}
+
+func init() {
+	registerExtractor("go-binary", GoBinaryExtractor{})
+}
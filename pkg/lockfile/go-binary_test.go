@@ -1,6 +1,8 @@
 package lockfile_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/DataDog/datadog-sbom-generator/pkg/models"
@@ -23,38 +25,39 @@ func TestGoBinaryExtractor_ShouldExtract(t *testing.T) {
 		},
 		{
 			name: "",
-			path: "binary.json",
+			path: "fixtures/go/binaries",
 			want: false,
 		},
 		{
 			name: "",
-			path: "path/to/my/binary.json",
+			path: "fixtures/go/binaries/does-not-exist",
 			want: false,
 		},
 		{
 			name: "",
-			path: "path/to/my/binary-lock.json/file",
+			path: "fixtures/go/binaries/just-go",
 			want: true,
 		},
 		{
 			name: "",
-			path: "path/to/my/binary",
+			path: "fixtures/go/binaries/has-one-dep",
 			want: true,
 		},
 		{
 			name: "",
-			path: "path/to/my/binary.exe",
+			path: "fixtures/go/binaries/not-go",
 			want: true,
 		},
 		{
+			// Files are only rejected from their content by ShouldExtractFile
 			name: "",
-			path: "path/to/my/.hidden-binary",
-			want: false,
+			path: "fixtures/go/one-package.mod",
+			want: true,
 		},
 		{
 			name: "",
-			path: "path/to/my/binary.exe.1",
-			want: false,
+			path: "fixtures/go/empty.mod",
+			want: true,
 		},
 	}
 	for _, tt := range tests {
@@ -69,6 +72,103 @@ func TestGoBinaryExtractor_ShouldExtract(t *testing.T) {
 	}
 }
 
+func TestGoBinaryExtractor_ShouldExtractFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{
+			path: "fixtures/go/binaries/just-go",
+			want: true,
+		},
+		{
+			path: "fixtures/go/binaries/has-one-dep",
+			want: true,
+		},
+		{
+			// An executable without Go build information
+			path: "fixtures/go/binaries/not-go",
+			want: false,
+		},
+		{
+			path: "fixtures/go/one-package.mod",
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			file, err := lockfile.OpenLocalDepFile(tt.path)
+			if err != nil {
+				t.Fatalf("could not open file %v", err)
+			}
+			defer file.Close()
+
+			got := lockfile.GoBinaryExtractor{}.ShouldExtractFile(file)
+			if got != tt.want {
+				t.Errorf("ShouldExtractFile(%v) got = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoBinaryExtractor_ShouldExtractFile_AnyNameAndPermissions(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile("fixtures/go/binaries/has-one-dep")
+	if err != nil {
+		t.Fatalf("could not read file %v", err)
+	}
+
+	tests := []struct {
+		name string
+		mode os.FileMode
+	}{
+		{
+			// A versioned release binary
+			name: "svc-1.2.3",
+			mode: 0o755,
+		},
+		{
+			name: "agent-7.50.0-linux",
+			mode: 0o755,
+		},
+		{
+			// A binary copied without its executable permission
+			name: "not-executable",
+			mode: 0o644,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), tt.name)
+			if err := os.WriteFile(path, content, tt.mode); err != nil {
+				t.Fatalf("could not write file %v", err)
+			}
+
+			e := lockfile.GoBinaryExtractor{}
+			if !e.ShouldExtract(path) {
+				t.Errorf("ShouldExtract(%v) got = false, want true", tt.name)
+			}
+
+			file, err := lockfile.OpenLocalDepFile(path)
+			if err != nil {
+				t.Fatalf("could not open file %v", err)
+			}
+			defer file.Close()
+
+			if !e.ShouldExtractFile(file) {
+				t.Errorf("ShouldExtractFile(%v) got = false, want true", tt.name)
+			}
+		})
+	}
+}
+
 func TestExtractGoBinary_NoPackages(t *testing.T) {
 	t.Parallel()
 
@@ -121,6 +221,32 @@ func TestExtractGoBinary_OnePackage(t *testing.T) {
 	})
 }
 
+func TestGoBinaryExtractor_GetArtifact(t *testing.T) {
+	t.Parallel()
+
+	file, err := lockfile.OpenLocalDepFile("fixtures/go/binaries/has-one-dep")
+	if err != nil {
+		t.Fatalf("could not open file %v", err)
+	}
+
+	artifact, err := lockfile.GoBinaryExtractor{}.GetArtifact(file)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+
+	expected := models.ScannedArtifact{
+		ArtifactDetail: models.ArtifactDetail{
+			Name:      "github.com/abcd",
+			Version:   "",
+			Filename:  file.Path(),
+			Ecosystem: models.EcosystemGo,
+		},
+	}
+	if *artifact != expected {
+		t.Errorf("GetArtifact() got = %v, want %v", *artifact, expected)
+	}
+}
+
 func TestExtractGoBinary_NotAGoBinary(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,371 @@
+package lockfile
+
+import (
+	"io"
+	"maps"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/utility/fileposition"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+)
+
+const (
+	cargoBuildGroup = "build"
+	// cargoWorkspaceGroup is the group of the dependencies of [workspace.dependencies], which are not dependencies
+	// by themselves but declarations inherited by the members of the workspace (serde.workspace = true)
+	cargoWorkspaceGroup = "workspace"
+)
+
+// cargoDependencyGroups maps the dependency tables of Cargo manifests to the groups of their dependencies
+var cargoDependencyGroups = map[string]string{
+	"dependencies":       string(models.DepGroupProd),
+	"dev-dependencies":   string(models.DepGroupDev),
+	"dev_dependencies":   string(models.DepGroupDev),
+	"build-dependencies": cargoBuildGroup,
+	"build_dependencies": cargoBuildGroup,
+}
+
+type CargoTOMLMatcher struct{}
+
+// cargoManifestDependency is a dependency declared in a Cargo manifest, either on a single line
+// (serde = { version = "1.0" }) or in its own table ([dependencies.serde])
+type cargoManifestDependency struct {
+	key        string
+	group      string
+	filename   string
+	lines      []string
+	startLine  int
+	endLine    int
+	attributes map[string]any
+}
+
+func (m CargoTOMLMatcher) GetSourceFile(lockfile DepFile) (DepFile, error) {
+	return lockfile.Open("Cargo.toml")
+}
+
+// Match marks the crates declared by the manifest, and by the manifests of the members of its workspace, as direct
+// dependencies. The dependencies of the target-specific tables ([target.'cfg(unix)'.dependencies]) are grouped
+// as the ones of the tables they extend.
+func (m CargoTOMLMatcher) Match(sourcefile DepFile, packages []PackageDetails) error {
+	content, err := io.ReadAll(sourcefile)
+	if err != nil {
+		return err
+	}
+
+	members, err := cargoWorkspaceMembers(sourcefile, content)
+	if err != nil {
+		return err
+	}
+
+	dependencies := parseCargoManifestDependencies(sourcefile.Path(), content)
+	for _, member := range members {
+		memberFile, err := sourcefile.Open(filepath.Join(member, "Cargo.toml"))
+		if err != nil {
+			// Members matched by globs are not always crates
+			continue
+		}
+		memberContent, err := io.ReadAll(memberFile)
+		memberFile.Close()
+		if err != nil {
+			return err
+		}
+		dependencies = append(dependencies, parseCargoManifestDependencies(memberFile.Path(), memberContent)...)
+	}
+
+	workspaceDependencies := make(map[string]*cargoManifestDependency)
+	for _, dependency := range dependencies {
+		if dependency.group == cargoWorkspaceGroup {
+			workspaceDependencies[dependency.key] = dependency
+		}
+	}
+
+	packagesByName := make(map[string][]int)
+	for index, pkg := range packages {
+		packagesByName[pkg.Name] = append(packagesByName[pkg.Name], index)
+	}
+
+	// The locations of the crates are the ones of their first declaration, unless a later one is a production dependency
+	locatedInProd := make(map[int]bool)
+	for _, dependency := range dependencies {
+		if dependency.group == cargoWorkspaceGroup {
+			continue
+		}
+
+		// Inherited dependencies get their crate and version from the declaration of the workspace
+		declaration := dependency
+		if inherited, _ := dependency.attributes["workspace"].(bool); inherited && workspaceDependencies[dependency.key] != nil {
+			declaration = workspaceDependencies[dependency.key]
+		}
+		name := declaration.crateName()
+		requirement, _ := declaration.attributes["version"].(string)
+
+		for _, index := range cargoMatchingPackages(packages, packagesByName[name], requirement) {
+			if !slices.Contains(packages[index].DepGroups, dependency.group) {
+				packages[index].DepGroups = append(packages[index].DepGroups, dependency.group)
+			}
+
+			isProd := dependency.group == string(models.DepGroupProd)
+			if !packages[index].IsDirect || (isProd && !locatedInProd[index]) {
+				packages[index].BlockLocation = dependency.blockLocation()
+				packages[index].NameLocation = dependency.nameLocation(name)
+				packages[index].VersionLocation = declaration.versionLocation(requirement)
+				locatedInProd[index] = isProd
+			}
+			packages[index].IsDirect = true
+		}
+	}
+
+	return nil
+}
+
+// cargoWorkspaceMembers returns the directories of the members of the workspace declared by a manifest,
+// relatively to the manifest, expanding the globs of [workspace] members and removing its excluded paths.
+// Globs are expanded in the filesystem of the manifest, such as a git revision or a container image,
+// and skipped when it cannot be listed.
+func cargoWorkspaceMembers(sourcefile DepFile, content []byte) ([]string, error) {
+	var manifest struct {
+		Workspace struct {
+			Members []string `toml:"members"`
+			Exclude []string `toml:"exclude"`
+		} `toml:"workspace"`
+	}
+	if _, err := toml.Decode(string(content), &manifest); err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(sourcefile.Path())
+	globbable, canGlob := sourcefile.(GlobbableDepFile)
+	var members []string
+	for _, pattern := range manifest.Workspace.Members {
+		matches := []string{filepath.Join(dir, filepath.FromSlash(pattern))}
+		if strings.ContainsAny(pattern, `*?[\`) {
+			if !canGlob {
+				continue
+			}
+			var err error
+			if matches, err = globbable.Glob(pattern); err != nil {
+				return nil, err
+			}
+		}
+		for _, match := range matches {
+			member, err := filepath.Rel(dir, match)
+			if err != nil || member == "." || slices.Contains(members, member) {
+				continue
+			}
+			excluded := slices.ContainsFunc(manifest.Workspace.Exclude, func(exclude string) bool {
+				exclude = filepath.Clean(filepath.FromSlash(exclude))
+				return member == exclude || strings.HasPrefix(member, exclude+string(filepath.Separator))
+			})
+			if !excluded {
+				members = append(members, member)
+			}
+		}
+	}
+
+	return members, nil
+}
+
+// parseCargoManifestDependencies returns the dependencies declared by a manifest, in their order of declaration
+func parseCargoManifestDependencies(filename string, content []byte) []*cargoManifestDependency {
+	lines := fileposition.BytesToLines(content)
+
+	var dependencies []*cargoManifestDependency
+	declared := make(map[[2]string]*cargoManifestDependency)
+	declare := func(group string, key string, lineNumber int) *cargoManifestDependency {
+		// Dotted keys (serde.version = "1.0", serde.features = ["derive"]) declare a dependency over several lines
+		if dependency, ok := declared[[2]string{group, key}]; ok {
+			return dependency
+		}
+		dependency := &cargoManifestDependency{
+			key:        key,
+			group:      group,
+			filename:   filename,
+			lines:      lines,
+			startLine:  lineNumber,
+			endLine:    lineNumber,
+			attributes: make(map[string]any),
+		}
+		declared[[2]string{group, key}] = dependency
+		dependencies = append(dependencies, dependency)
+
+		return dependency
+	}
+
+	var group string
+	var tableDependency *cargoManifestDependency
+	for index, line := range lines {
+		lineNumber := index + 1
+
+		if keys, ok := fileposition.TOMLTableKeys(line); ok {
+			var key string
+			group, key = cargoDependencyTable(keys)
+			tableDependency = nil
+			if group != "" && key != "" {
+				tableDependency = declare(group, key, lineNumber)
+			}
+
+			continue
+		}
+		if group == "" {
+			continue
+		}
+
+		pair, ok := fileposition.TOMLKeyValue(line)
+		if !ok {
+			continue
+		}
+		if tableDependency != nil {
+			maps.Copy(tableDependency.attributes, pair)
+			tableDependency.endLine = lineNumber
+
+			continue
+		}
+		for key, value := range pair {
+			dependency := declare(group, key, lineNumber)
+			dependency.endLine = lineNumber
+			switch value := value.(type) {
+			case string:
+				dependency.attributes["version"] = value
+			case map[string]any:
+				maps.Copy(dependency.attributes, value)
+			}
+		}
+	}
+
+	return dependencies
+}
+
+// cargoDependencyTable returns the group of the dependencies of a table, and the key of the dependency when the table
+// declares a single one ([dependencies.serde]), or an empty group when the table does not declare dependencies
+func cargoDependencyTable(keys []string) (string, string) {
+	var group string
+	switch {
+	case len(keys) >= 2 && keys[0] == "workspace" && keys[1] == "dependencies":
+		group, keys = cargoWorkspaceGroup, keys[2:]
+	case len(keys) >= 3 && keys[0] == "target":
+		group, keys = cargoDependencyGroups[keys[2]], keys[3:]
+	default:
+		group, keys = cargoDependencyGroups[keys[0]], keys[1:]
+	}
+
+	switch {
+	case group == "" || len(keys) > 1:
+		return "", ""
+	case len(keys) == 1:
+		return group, keys[0]
+	default:
+		return group, ""
+	}
+}
+
+// crateName returns the name of the crate of the dependency, which is not its key when it is renamed
+func (d *cargoManifestDependency) crateName() string {
+	if name, ok := d.attributes["package"].(string); ok {
+		return name
+	}
+
+	return d.key
+}
+
+func (d *cargoManifestDependency) block() []string {
+	return d.lines[d.startLine-1 : d.endLine]
+}
+
+func (d *cargoManifestDependency) blockLocation() models.FilePosition {
+	return models.FilePosition{
+		Line:     models.Position{Start: d.startLine, End: d.endLine},
+		Column:   models.Position{Start: fileposition.GetFirstNonEmptyCharacterIndexInLine(d.lines[d.startLine-1]), End: fileposition.GetLastNonEmptyCharacterIndexInLine(d.lines[d.endLine-1])},
+		Filename: d.filename,
+	}
+}
+
+func (d *cargoManifestDependency) nameLocation(name string) *models.FilePosition {
+	var nameLocation *models.FilePosition
+	if name != d.key {
+		nameLocation = fileposition.ExtractDelimitedRegexpPositionInBlock(d.block(), regexp.QuoteMeta(name), d.startLine, `package\s*=\s*["']`, `["']`)
+	}
+	// Inherited dependencies renamed by the workspace are located by their key
+	if nameLocation == nil {
+		nameLocation = fileposition.ExtractStringPositionInBlock(d.block()[:1], d.key, d.startLine)
+	}
+	if nameLocation != nil {
+		nameLocation.Filename = d.filename
+	}
+
+	return nameLocation
+}
+
+func (d *cargoManifestDependency) versionLocation(requirement string) *models.FilePosition {
+	if requirement == "" {
+		return nil
+	}
+
+	versionLocation := fileposition.ExtractDelimitedRegexpPositionInBlock(d.block(), regexp.QuoteMeta(requirement), d.startLine, `=\s*["']`, `["']`)
+	if versionLocation != nil {
+		versionLocation.Filename = d.filename
+	}
+
+	return versionLocation
+}
+
+// cargoMatchingPackages returns the packages of a crate resolving a version requirement, as several versions
+// of a crate can be locked. All the packages are returned when none resolves the requirement.
+func cargoMatchingPackages(packages []PackageDetails, indexes []int, requirement string) []int {
+	if len(indexes) < 2 || requirement == "" {
+		return indexes
+	}
+
+	matching := slices.DeleteFunc(slices.Clone(indexes), func(index int) bool {
+		return !cargoRequirementMatches(requirement, packages[index].Version)
+	})
+	if len(matching) == 0 {
+		return indexes
+	}
+
+	return matching
+}
+
+// cargoRequirementMatches checks if a version is compatible with the first comparator of a requirement, comparing
+// the components the comparator fixes: "1.2" and "^1.2" fix the major version, "0.3" fixes the minor version,
+// "~1.2" the major and minor versions, and "=1.2.3" all of them. Range comparators (>=, <, *) match every version.
+func cargoRequirementMatches(requirement string, version string) bool {
+	comparator, _, _ := strings.Cut(requirement, ",")
+	comparator = strings.TrimSpace(comparator)
+	if comparator == "" || strings.ContainsAny(comparator[:1], "<>*") {
+		return true
+	}
+
+	components := strings.Split(strings.TrimSpace(strings.TrimLeft(comparator, "^~=")), ".")
+	version, _, _ = strings.Cut(version, "+")
+	version, _, _ = strings.Cut(version, "-")
+	versionComponents := strings.Split(version, ".")
+
+	fixed := len(components)
+	switch comparator[0] {
+	case '=':
+	case '~':
+		fixed = min(fixed, 2)
+	default:
+		if index := slices.IndexFunc(components, func(component string) bool { return strings.Trim(component, "0") != "" }); index >= 0 {
+			fixed = index + 1
+		}
+	}
+
+	for i := range fixed {
+		if components[i] == "*" || components[i] == "x" {
+			break
+		}
+		if i >= len(versionComponents) || components[i] != versionComponents[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+var _ Matcher = CargoTOMLMatcher{}
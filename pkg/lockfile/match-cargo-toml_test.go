@@ -0,0 +1,277 @@
+package lockfile_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/lockfile"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var cargoTOMLMatcher = lockfile.CargoTOMLMatcher{}
+
+// unlistedDepFile is a file of a filesystem which cannot be listed
+type unlistedDepFile struct {
+	lockfile.DepFile
+}
+
+func cargoPosition(filename string, lineStart int, lineEnd int, columnStart int, columnEnd int) models.FilePosition {
+	return models.FilePosition{
+		Line:     models.Position{Start: lineStart, End: lineEnd},
+		Column:   models.Position{Start: columnStart, End: columnEnd},
+		Filename: filename,
+	}
+}
+
+func cargoPositionPointer(filename string, line int, columnStart int, columnEnd int) *models.FilePosition {
+	position := cargoPosition(filename, line, line, columnStart, columnEnd)
+	return &position
+}
+
+func TestCargoTOMLMatcher_GetSourceFile_FileDoesNotExist(t *testing.T) {
+	t.Parallel()
+
+	lockFile, err := lockfile.OpenLocalDepFile("fixtures/cargo/one-package.lock")
+	if err != nil {
+		t.Errorf("Got unexpected error: %v", err)
+	}
+
+	sourceFile, err := cargoTOMLMatcher.GetSourceFile(lockFile)
+	expectErrIs(t, err, fs.ErrNotExist)
+	assert.Equal(t, "", sourceFile.Path())
+}
+
+func TestCargoTOMLMatcher_GetSourceFile(t *testing.T) {
+	t.Parallel()
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Errorf("Got unexpected error: %v", err)
+	}
+
+	basePath := "fixtures/cargo/manifest/"
+	sourcefilePath := filepath.FromSlash(filepath.Join(dir, basePath+"Cargo.toml"))
+
+	lockFile, err := lockfile.OpenLocalDepFile(basePath + "Cargo.lock")
+	if err != nil {
+		t.Errorf("Got unexpected error: %v", err)
+	}
+
+	sourceFile, err := cargoTOMLMatcher.GetSourceFile(lockFile)
+	if err != nil {
+		t.Errorf("Got unexpected error: %v", err)
+	}
+
+	assert.Equal(t, sourcefilePath, sourceFile.Path())
+}
+
+func TestCargoTOMLMatcher_Match_Manifest(t *testing.T) {
+	t.Parallel()
+
+	sourceFile, err := lockfile.OpenLocalDepFile("fixtures/cargo/manifest/Cargo.toml")
+	if err != nil {
+		t.Errorf("Got unexpected error: %v", err)
+	}
+	manifest := sourceFile.Path()
+
+	packages := []lockfile.PackageDetails{
+		{Name: "cc", Version: "1.0.99"},
+		{Name: "http-body-legacy", Version: "0.4.6"},
+		{Name: "insta", Version: "1.39.0"},
+		{Name: "local-rust-pkg", Version: "0.1.0"},
+		{Name: "nix", Version: "0.29.0"},
+		{Name: "proc-macro2", Version: "1.0.86"},
+		{Name: "sample", Version: "0.1.0"},
+		{Name: "serde", Version: "1.0.203"},
+		{Name: "syn", Version: "1.0.109"},
+		{Name: "syn", Version: "2.0.66"},
+		{Name: "tokio", Version: "1.38.0"},
+		{Name: "winapi", Version: "0.3.9"},
+	}
+	err = cargoTOMLMatcher.Match(sourceFile, packages)
+	if err != nil {
+		t.Errorf("Got unexpected error: %v", err)
+	}
+
+	expectPackages(t, packages, []lockfile.PackageDetails{
+		{
+			Name:            "cc",
+			Version:         "1.0.99",
+			IsDirect:        true,
+			DepGroups:       []string{"build"},
+			BlockLocation:   cargoPosition(manifest, 21, 21, 1, 11),
+			NameLocation:    cargoPositionPointer(manifest, 21, 1, 3),
+			VersionLocation: cargoPositionPointer(manifest, 21, 7, 10),
+		},
+		{
+			// Renamed dependencies are located by the name of their crate
+			Name:            "http-body-legacy",
+			Version:         "0.4.6",
+			IsDirect:        true,
+			DepGroups:       []string{"prod"},
+			BlockLocation:   cargoPosition(manifest, 9, 9, 1, 62),
+			NameLocation:    cargoPositionPointer(manifest, 9, 43, 59),
+			VersionLocation: cargoPositionPointer(manifest, 9, 26, 29),
+		},
+		{
+			Name:            "insta",
+			Version:         "1.39.0",
+			IsDirect:        true,
+			DepGroups:       []string{"dev"},
+			BlockLocation:   cargoPosition(manifest, 18, 18, 1, 15),
+			NameLocation:    cargoPositionPointer(manifest, 18, 1, 6),
+			VersionLocation: cargoPositionPointer(manifest, 18, 10, 14),
+		},
+		{
+			Name:          "local-rust-pkg",
+			Version:       "0.1.0",
+			IsDirect:      true,
+			DepGroups:     []string{"prod"},
+			BlockLocation: cargoPosition(manifest, 10, 10, 1, 48),
+			NameLocation:  cargoPositionPointer(manifest, 10, 1, 15),
+		},
+		{
+			Name:            "nix",
+			Version:         "0.29.0",
+			IsDirect:        true,
+			DepGroups:       []string{"dev"},
+			BlockLocation:   cargoPosition(manifest, 27, 27, 1, 13),
+			NameLocation:    cargoPositionPointer(manifest, 27, 1, 4),
+			VersionLocation: cargoPositionPointer(manifest, 27, 8, 12),
+		},
+		{Name: "proc-macro2", Version: "1.0.86"},
+		{Name: "sample", Version: "0.1.0"},
+		{
+			// The location of the production dependency is kept over the one of the dev dependency
+			Name:            "serde",
+			Version:         "1.0.203",
+			IsDirect:        true,
+			DepGroups:       []string{"prod", "dev"},
+			BlockLocation:   cargoPosition(manifest, 7, 7, 1, 51),
+			NameLocation:    cargoPositionPointer(manifest, 7, 1, 6),
+			VersionLocation: cargoPositionPointer(manifest, 7, 22, 25),
+		},
+		{Name: "syn", Version: "1.0.109"},
+		{
+			Name:            "syn",
+			Version:         "2.0.66",
+			IsDirect:        true,
+			DepGroups:       []string{"prod"},
+			BlockLocation:   cargoPosition(manifest, 8, 8, 1, 12),
+			NameLocation:    cargoPositionPointer(manifest, 8, 1, 4),
+			VersionLocation: cargoPositionPointer(manifest, 8, 8, 11),
+		},
+		{
+			Name:            "tokio",
+			Version:         "1.38.0",
+			IsDirect:        true,
+			DepGroups:       []string{"prod"},
+			BlockLocation:   cargoPosition(manifest, 12, 14, 1, 20),
+			NameLocation:    cargoPositionPointer(manifest, 12, 15, 20),
+			VersionLocation: cargoPositionPointer(manifest, 13, 12, 16),
+		},
+		{
+			Name:            "winapi",
+			Version:         "0.3.9",
+			IsDirect:        true,
+			DepGroups:       []string{"prod"},
+			BlockLocation:   cargoPosition(manifest, 24, 24, 1, 53),
+			NameLocation:    cargoPositionPointer(manifest, 24, 1, 7),
+			VersionLocation: cargoPositionPointer(manifest, 24, 23, 26),
+		},
+	})
+}
+
+func TestCargoTOMLMatcher_Match_Workspace(t *testing.T) {
+	t.Parallel()
+
+	sourceFile, err := lockfile.OpenLocalDepFile("fixtures/cargo/workspace/Cargo.toml")
+	if err != nil {
+		t.Errorf("Got unexpected error: %v", err)
+	}
+	workspace := sourceFile.Path()
+	app := filepath.Join(filepath.Dir(workspace), "crates", "app", "Cargo.toml")
+	cli := filepath.Join(filepath.Dir(workspace), "crates", "cli", "Cargo.toml")
+
+	packages := []lockfile.PackageDetails{
+		{Name: "anyhow", Version: "1.0.86"},
+		{Name: "app", Version: "0.1.0"},
+		{Name: "clap", Version: "4.5.7"},
+		{Name: "cli", Version: "0.1.0"},
+		{Name: "log", Version: "0.4.21"},
+		{Name: "serde", Version: "1.0.203"},
+	}
+	err = cargoTOMLMatcher.Match(sourceFile, packages)
+	if err != nil {
+		t.Errorf("Got unexpected error: %v", err)
+	}
+
+	expectPackages(t, packages, []lockfile.PackageDetails{
+		{
+			// Inherited dependencies are located in the members, with the version declared by the workspace
+			Name:            "anyhow",
+			Version:         "1.0.86",
+			IsDirect:        true,
+			DepGroups:       []string{"prod", "dev"},
+			BlockLocation:   cargoPosition(app, 8, 8, 1, 30),
+			NameLocation:    cargoPositionPointer(app, 8, 1, 7),
+			VersionLocation: cargoPositionPointer(workspace, 8, 11, 14),
+		},
+		{
+			Name:          "app",
+			Version:       "0.1.0",
+			IsDirect:      true,
+			DepGroups:     []string{"prod"},
+			BlockLocation: cargoPosition(cli, 7, 7, 1, 26),
+			NameLocation:  cargoPositionPointer(cli, 7, 1, 4),
+		},
+		{
+			Name:            "clap",
+			Version:         "4.5.7",
+			IsDirect:        true,
+			DepGroups:       []string{"prod"},
+			BlockLocation:   cargoPosition(cli, 8, 8, 1, 13),
+			NameLocation:    cargoPositionPointer(cli, 8, 1, 5),
+			VersionLocation: cargoPositionPointer(cli, 8, 9, 12),
+		},
+		{Name: "cli", Version: "0.1.0"},
+		// The dependencies of excluded members are not direct dependencies of the workspace
+		{Name: "log", Version: "0.4.21"},
+		{
+			Name:            "serde",
+			Version:         "1.0.203",
+			IsDirect:        true,
+			DepGroups:       []string{"prod"},
+			BlockLocation:   cargoPosition(app, 7, 7, 1, 23),
+			NameLocation:    cargoPositionPointer(app, 7, 1, 6),
+			VersionLocation: cargoPositionPointer(workspace, 7, 22, 25),
+		},
+	})
+}
+
+func TestCargoTOMLMatcher_Match_WorkspaceNotListed(t *testing.T) {
+	t.Parallel()
+
+	sourceFile, err := lockfile.OpenLocalDepFile("fixtures/cargo/workspace/Cargo.toml")
+	if err != nil {
+		t.Errorf("Got unexpected error: %v", err)
+	}
+	defer sourceFile.Close()
+
+	packages := []lockfile.PackageDetails{
+		{Name: "clap", Version: "4.5.7"},
+		{Name: "serde", Version: "1.0.203"},
+	}
+	err = cargoTOMLMatcher.Match(unlistedDepFile{DepFile: sourceFile}, packages)
+	if err != nil {
+		t.Errorf("Got unexpected error: %v", err)
+	}
+
+	// The globs of the members are not expanded on the host when the filesystem of the manifest cannot be listed
+	expectPackages(t, packages, []lockfile.PackageDetails{
+		{Name: "clap", Version: "4.5.7"},
+		{Name: "serde", Version: "1.0.203"},
+	})
+}
@@ -130,7 +130,7 @@ func TestNodeModulesExtractor_Extract_npm_v2_NestedDependencies(t *testing.T) {
 		t.Errorf("Got unexpected error: %v", err)
 	}
 
-	expectPackages(t, packages, []lockfile.PackageDetails{
+	expected := []lockfile.PackageDetails{
 		{
 			Name:           "postcss",
 			Version:        "6.0.23",
@@ -166,7 +166,12 @@ func TestNodeModulesExtractor_Extract_npm_v2_NestedDependencies(t *testing.T) {
 			Ecosystem:      models.EcosystemNPM,
 			DepGroups:      []string{"prod"},
 		},
-	})
+	}
+	expected[0].Dependencies = append(expected[0].Dependencies, &expected[4])
+	expected[1].Dependencies = append(expected[1].Dependencies, &expected[3])
+	expected[2].Dependencies = append(expected[2].Dependencies, &expected[1])
+
+	expectPackages(t, packages, expected)
 }
 
 func TestNodeModulesExtractor_Extract_npm_v2_NestedDependenciesDup(t *testing.T) {
@@ -3,6 +3,7 @@ package lockfile
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/DataDog/datadog-sbom-generator/pkg/models"
 
@@ -12,14 +13,22 @@ import (
 type CargoLockPackage struct {
 	Name    string `toml:"name"`
 	Version string `toml:"version"`
+	// Source is empty for path crates, such as the members of the workspace
+	Source       string   `toml:"source"`
+	Checksum     string   `toml:"checksum"`
+	Dependencies []string `toml:"dependencies"`
 }
 
 type CargoLockFile struct {
 	Version  int                `toml:"version"`
 	Packages []CargoLockPackage `toml:"package"`
+	// Metadata holds the checksums of the lockfiles of version 1, keyed by "checksum <name> <version> (<source>)"
+	Metadata map[string]string `toml:"metadata"`
 }
 
-type CargoLockExtractor struct{}
+type CargoLockExtractor struct {
+	WithMatcher
+}
 
 func (e CargoLockExtractor) ShouldExtract(path string) bool {
 	return filepath.Base(path) == "Cargo.lock"
@@ -34,27 +43,87 @@ func (e CargoLockExtractor) Extract(f DepFile) ([]PackageDetails, error) {
 		return []PackageDetails{}, fmt.Errorf("could not extract from %s: %w", f.Path(), err)
 	}
 
-	packages := make([]PackageDetails, 0, len(parsedLockfile.Packages))
+	packages := make(map[string]PackageDetails, len(parsedLockfile.Packages))
+	keysByName := make(map[string][]string)
 
 	for _, lockPackage := range parsedLockfile.Packages {
-		packages = append(packages, PackageDetails{
+		key := cargoPackageKey(lockPackage.Name, lockPackage.Version, lockPackage.Source)
+		keysByName[lockPackage.Name] = append(keysByName[lockPackage.Name], key)
+
+		pkgDetails := PackageDetails{
 			Name:           lockPackage.Name,
 			Version:        lockPackage.Version,
 			PackageManager: models.Crates,
 			Ecosystem:      models.EcosystemCratesIO,
-		})
+		}
+		if strings.HasPrefix(lockPackage.Source, "git+") {
+			// Git sources are pinned to the commit they are locked at: git+https://github.com/org/repo?branch=main#<commit>
+			_, pkgDetails.Commit, _ = strings.Cut(lockPackage.Source, "#")
+		}
+
+		checksum := lockPackage.Checksum
+		if checksum == "" {
+			checksum = parsedLockfile.Metadata[fmt.Sprintf("checksum %s %s (%s)", lockPackage.Name, lockPackage.Version, lockPackage.Source)]
+		}
+		// Only the crates downloaded from registries have a checksum, the ones of git or path sources do not
+		if checksum != "" && checksum != "<none>" {
+			pkgDetails.Hashes = []models.Hash{{Algorithm: models.HashSHA256, Value: checksum}}
+		}
+
+		packages[key] = pkgDetails
 	}
 
-	return packages, nil
+	edges := dependencyEdges{}
+	for _, lockPackage := range parsedLockfile.Packages {
+		parentKey := cargoPackageKey(lockPackage.Name, lockPackage.Version, lockPackage.Source)
+		for _, dependency := range lockPackage.Dependencies {
+			if childKey, ok := resolveCargoDependency(dependency, keysByName, packages); ok {
+				edges.add(parentKey, childKey)
+			}
+		}
+	}
+
+	return collectWithDependencies(packages, edges), nil
+}
+
+func cargoPackageKey(name string, version string, source string) string {
+	return name + "@" + version + "@" + source
+}
+
+// resolveCargoDependency returns the key of the package targeted by an entry of the dependencies of a package,
+// which is the name of the crate, followed by its version when several versions of the crate are locked,
+// and by its source when several sources provide the same version: "serde", "syn 1.0.109" or
+// "rand 0.8.5 (registry+https://github.com/rust-lang/crates.io-index)"
+func resolveCargoDependency(dependency string, keysByName map[string][]string, packages map[string]PackageDetails) (string, bool) {
+	name, rest, _ := strings.Cut(dependency, " ")
+	version, source, _ := strings.Cut(rest, " ")
+	source = strings.TrimSuffix(strings.TrimPrefix(source, "("), ")")
+
+	for _, key := range keysByName[name] {
+		if version != "" && packages[key].Version != version {
+			continue
+		}
+		if source != "" && key != cargoPackageKey(name, version, source) {
+			continue
+		}
+
+		return key, true
+	}
+
+	return "", false
 }
 
 var _ Extractor = CargoLockExtractor{}
 
+var CargoExtractor = CargoLockExtractor{
+	WithMatcher{Matchers: []Matcher{&CargoTOMLMatcher{}}},
+}
+
 //nolint:gochecknoinits
 func init() {
-	registerExtractor("Cargo.lock", CargoLockExtractor{})
+	registerExtractor("Cargo.lock", CargoExtractor)
 }
 
 func ParseCargoLock(pathToLockfile string) ([]PackageDetails, error) {
-	return ExtractFromFile(pathToLockfile, CargoLockExtractor{})
+	return ExtractFromFile(pathToLockfile, CargoExtractor)
 }
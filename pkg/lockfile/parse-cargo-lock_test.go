@@ -105,6 +105,7 @@ func TestParseCargoLock_OnePackage(t *testing.T) {
 			Version:        "0.15.2",
 			PackageManager: models.Crates,
 			Ecosystem:      models.EcosystemCratesIO,
+			Hashes:         []models.Hash{{Algorithm: models.HashSHA256, Value: "e7a2e47a1fbe209ee101dd6d61285226744c6c8d3c21c8dc878ba6cb9f467f3a"}},
 		},
 	})
 }
@@ -124,12 +125,14 @@ func TestParseCargoLock_TwoPackages(t *testing.T) {
 			Version:        "0.15.2",
 			PackageManager: models.Crates,
 			Ecosystem:      models.EcosystemCratesIO,
+			Hashes:         []models.Hash{{Algorithm: models.HashSHA256, Value: "e7a2e47a1fbe209ee101dd6d61285226744c6c8d3c21c8dc878ba6cb9f467f3a"}},
 		},
 		{
 			Name:           "syn",
 			Version:        "1.0.73",
 			PackageManager: models.Crates,
 			Ecosystem:      models.EcosystemCratesIO,
+			Hashes:         []models.Hash{{Algorithm: models.HashSHA256, Value: "f71489ff30030d2ae598524f61326b902466f72a0fb1a8564c001cc63425bcc7"}},
 		},
 	})
 }
@@ -149,6 +152,7 @@ func TestParseCargoLock_TwoPackagesWithLocal(t *testing.T) {
 			Version:        "0.15.2",
 			PackageManager: models.Crates,
 			Ecosystem:      models.EcosystemCratesIO,
+			Hashes:         []models.Hash{{Algorithm: models.HashSHA256, Value: "e7a2e47a1fbe209ee101dd6d61285226744c6c8d3c21c8dc878ba6cb9f467f3a"}},
 		},
 		{
 			Name:           "local-rust-pkg",
@@ -174,6 +178,90 @@ func TestParseCargoLock_PackageWithBuildString(t *testing.T) {
 			Version:        "0.10.2+wasi-snapshot-preview1",
 			PackageManager: models.Crates,
 			Ecosystem:      models.EcosystemCratesIO,
+			Hashes:         []models.Hash{{Algorithm: models.HashSHA256, Value: "fd6fbd9a79829dd1ad0cc20627bf1ed606756a7f77edff7b66b7064f9cb327c6"}},
 		},
 	})
 }
+
+func TestParseCargoLock_DependencyGraph(t *testing.T) {
+	t.Parallel()
+
+	packages, err := lockfile.ParseCargoLock("fixtures/cargo/dependency-graph.lock")
+
+	if err != nil {
+		t.Errorf("Got unexpected error: %v", err)
+	}
+
+	expected := []lockfile.PackageDetails{
+		{
+			// Path crates have neither source nor checksum
+			Name:           "app",
+			Version:        "0.1.0",
+			PackageManager: models.Crates,
+			Ecosystem:      models.EcosystemCratesIO,
+		},
+		{
+			Name:           "proc-macro2",
+			Version:        "1.0.86",
+			PackageManager: models.Crates,
+			Ecosystem:      models.EcosystemCratesIO,
+			Hashes:         []models.Hash{{Algorithm: models.HashSHA256, Value: "5e719e8df665df0d1c8fbfd238015744736151d4445ec0836b8e628aae103b77"}},
+		},
+		{
+			Name:           "syn",
+			Version:        "1.0.109",
+			PackageManager: models.Crates,
+			Ecosystem:      models.EcosystemCratesIO,
+			Hashes:         []models.Hash{{Algorithm: models.HashSHA256, Value: "72b64191b275b66ffe2469e8af2c1cfe3bafa67b529ead792a6d0160888b4237"}},
+		},
+		{
+			Name:           "syn",
+			Version:        "2.0.66",
+			PackageManager: models.Crates,
+			Ecosystem:      models.EcosystemCratesIO,
+			Hashes:         []models.Hash{{Algorithm: models.HashSHA256, Value: "c42f3f41a2de00b01c0aaad383c5a45241efc8b2d1eda5661812fda5f3cdcff5"}},
+		},
+		{
+			Name:           "tokio-util",
+			Version:        "0.7.11",
+			Commit:         "0cbf1a5adae81e8ff86ca6d040aeee67cf888262",
+			PackageManager: models.Crates,
+			Ecosystem:      models.EcosystemCratesIO,
+		},
+	}
+	expected[0].Dependencies = []*lockfile.PackageDetails{&expected[1], &expected[2], &expected[3], &expected[4]}
+	expected[2].Dependencies = []*lockfile.PackageDetails{&expected[1]}
+	expected[3].Dependencies = []*lockfile.PackageDetails{&expected[1]}
+	expected[4].Dependencies = []*lockfile.PackageDetails{&expected[3]}
+
+	expectPackages(t, packages, expected)
+}
+
+func TestParseCargoLock_V1Metadata(t *testing.T) {
+	t.Parallel()
+
+	packages, err := lockfile.ParseCargoLock("fixtures/cargo/v1-metadata.lock")
+
+	if err != nil {
+		t.Errorf("Got unexpected error: %v", err)
+	}
+
+	expected := []lockfile.PackageDetails{
+		{
+			Name:           "app",
+			Version:        "0.1.0",
+			PackageManager: models.Crates,
+			Ecosystem:      models.EcosystemCratesIO,
+		},
+		{
+			Name:           "proc-macro2",
+			Version:        "1.0.86",
+			PackageManager: models.Crates,
+			Ecosystem:      models.EcosystemCratesIO,
+			Hashes:         []models.Hash{{Algorithm: models.HashSHA256, Value: "5e719e8df665df0d1c8fbfd238015744736151d4445ec0836b8e628aae103b77"}},
+		},
+	}
+	expected[0].Dependencies = []*lockfile.PackageDetails{&expected[1]}
+
+	expectPackages(t, packages, expected)
+}
@@ -163,7 +163,7 @@ func TestParseNpmLock_v1_NestedDependencies(t *testing.T) {
 		t.Errorf("Got unexpected error: %v", err)
 	}
 
-	expectPackages(t, packages, []lockfile.PackageDetails{
+	expected := []lockfile.PackageDetails{
 		{
 			Name:           "postcss",
 			Version:        "6.0.23",
@@ -199,7 +199,12 @@ func TestParseNpmLock_v1_NestedDependencies(t *testing.T) {
 			Ecosystem:      models.EcosystemNPM,
 			DepGroups:      []string{"prod"},
 		},
-	})
+	}
+	expected[0].Dependencies = append(expected[0].Dependencies, &expected[4])
+	expected[1].Dependencies = append(expected[1].Dependencies, &expected[3])
+	expected[2].Dependencies = append(expected[2].Dependencies, &expected[1])
+
+	expectPackages(t, packages, expected)
 }
 
 func TestParseNpmLock_v1_NestedDependenciesDup(t *testing.T) {
@@ -395,7 +400,7 @@ func TestParseNpmLock_v1_Files(t *testing.T) {
 		t.Errorf("Got unexpected error: %v", err)
 	}
 
-	expectPackages(t, packages, []lockfile.PackageDetails{
+	expected := []lockfile.PackageDetails{
 		{
 			Name:           "lodash",
 			Version:        "1.3.1",
@@ -412,7 +417,10 @@ func TestParseNpmLock_v1_Files(t *testing.T) {
 			Commit:         "",
 			DepGroups:      []string{"prod"},
 		},
-	})
+	}
+	expected[1].Dependencies = append(expected[1].Dependencies, &expected[0])
+
+	expectPackages(t, packages, expected)
 }
 
 func TestParseNpmLock_v1_Alias(t *testing.T) {
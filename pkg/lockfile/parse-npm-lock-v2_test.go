@@ -262,7 +262,7 @@ func TestParseNpmLock_v2_NestedDependencies(t *testing.T) {
 		t.Errorf("Got unexpected error: %v", err)
 	}
 
-	expectPackagesWithoutLocations(t, packages, []lockfile.PackageDetails{
+	expected := []lockfile.PackageDetails{
 		{
 			Name:           "postcss",
 			Version:        "6.0.23",
@@ -298,7 +298,12 @@ func TestParseNpmLock_v2_NestedDependencies(t *testing.T) {
 			DepGroups:      []string{"prod"},
 			Ecosystem:      models.EcosystemNPM,
 		},
-	})
+	}
+	expected[0].Dependencies = append(expected[0].Dependencies, &expected[4])
+	expected[1].Dependencies = append(expected[1].Dependencies, &expected[3])
+	expected[2].Dependencies = append(expected[2].Dependencies, &expected[1])
+
+	expectPackagesWithoutLocations(t, packages, expected)
 }
 
 func TestParseNpmLock_v2_NestedDependenciesDup(t *testing.T) {
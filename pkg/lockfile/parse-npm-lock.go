@@ -133,35 +133,9 @@ func parseNpmLockDependencies(dependencies map[string]*NpmLockDependency) map[st
 			}
 		}
 
-		version := detail.Version
-		finalVersion := version
-		commit := ""
-
-		// If the package is aliased, get the name and version
-		if strings.HasPrefix(detail.Version, "npm:") {
-			i := strings.LastIndex(detail.Version, "@")
-			name = detail.Version[4:i]
-			finalVersion = detail.Version[i+1:]
-		}
+		name, finalVersion, commit, key := detail.identify(name)
 
-		// we can't resolve a version from a "file:" dependency
-		if strings.HasPrefix(detail.Version, "file:") {
-			finalVersion = ""
-			version = ""
-		} else {
-			commit = tryExtractCommit(detail.Version)
-
-			// if there is a commit, we want to deduplicate based on that rather than
-			// the version (the versions must match anyway for the commits to match)
-			//
-			// we also don't actually know what the "version" is, so blank it
-			if commit != "" {
-				finalVersion = ""
-				version = commit
-			}
-		}
-
-		details.add(name+"@"+version, PackageDetails{
+		details.add(key, PackageDetails{
 			Name:           name,
 			Version:        finalVersion,
 			PackageManager: models.NPM,
@@ -174,6 +148,76 @@ func parseNpmLockDependencies(dependencies map[string]*NpmLockDependency) map[st
 	return details
 }
 
+// identify returns the name and version under which a dependency of a v1 lockfile is reported, its commit if any,
+// and the key used to deduplicate it across the dependency tree.
+func (dep *NpmLockDependency) identify(name string) (string, string, string, string) {
+	version := dep.Version
+	finalVersion := version
+	commit := ""
+
+	// If the package is aliased, get the name and version
+	if strings.HasPrefix(dep.Version, "npm:") {
+		i := strings.LastIndex(dep.Version, "@")
+		name = dep.Version[4:i]
+		finalVersion = dep.Version[i+1:]
+	}
+
+	// we can't resolve a version from a "file:" dependency
+	if strings.HasPrefix(dep.Version, "file:") {
+		finalVersion = ""
+		version = ""
+	} else {
+		commit = tryExtractCommit(dep.Version)
+
+		// if there is a commit, we want to deduplicate based on that rather than
+		// the version (the versions must match anyway for the commits to match)
+		//
+		// we also don't actually know what the "version" is, so blank it
+		if commit != "" {
+			finalVersion = ""
+			version = commit
+		}
+	}
+
+	return name, finalVersion, commit, name + "@" + version
+}
+
+/*
+collectNpmLockDependenciesEdges records which packages depend on which in a v1 lockfile.
+
+Each dependency lists the packages it requires by name, which are resolved the same way node does:
+first in its own nested dependencies, then in the dependencies of its ancestors, up to the root of the lockfile.
+*/
+func collectNpmLockDependenciesEdges(dependencies map[string]*NpmLockDependency, ancestors []map[string]*NpmLockDependency, edges dependencyEdges) {
+	scopes := append(slices.Clone(ancestors), dependencies)
+
+	for name, detail := range dependencies {
+		_, _, _, parentKey := detail.identify(name)
+
+		for required := range detail.Requires {
+			if child, found := detail.Dependencies[required]; found {
+				_, _, _, childKey := child.identify(required)
+				edges.add(parentKey, childKey)
+
+				continue
+			}
+
+			for i := len(scopes) - 1; i >= 0; i-- {
+				if child, found := scopes[i][required]; found {
+					_, _, _, childKey := child.identify(required)
+					edges.add(parentKey, childKey)
+
+					break
+				}
+			}
+		}
+
+		if detail.Dependencies != nil {
+			collectNpmLockDependenciesEdges(detail.Dependencies, scopes, edges)
+		}
+	}
+}
+
 func extractNpmPackageName(name string) string {
 	maybeScope := path.Base(path.Dir(name))
 	pkgName := path.Base(name)
@@ -208,8 +252,46 @@ func (pkg NpmLockPackage) depGroups() []string {
 	return groups
 }
 
-func parseNpmLockPackages(packages map[string]*NpmLockPackage) map[string]PackageDetails {
+/*
+resolveNpmPackagePath finds the entry of the "packages" section that a dependency of the package installed at fromPath
+resolves to, following the node module resolution: the closest node_modules folder containing the dependency wins.
+*/
+func resolveNpmPackagePath(packages map[string]*NpmLockPackage, fromPath string, name string) (string, bool) {
+	dir := fromPath
+	for {
+		candidate := path.Join(dir, "node_modules", name)
+		if pkg, ok := packages[candidate]; ok {
+			if pkg.Link {
+				return pkg.Resolved, true
+			}
+
+			return candidate, true
+		}
+
+		if dir == "" {
+			return "", false
+		}
+
+		if index := strings.LastIndex(dir, "/node_modules/"); index >= 0 {
+			dir = dir[:index]
+		} else {
+			dir = ""
+		}
+	}
+}
+
+func (pkg NpmLockPackage) dependencyNames() []string {
+	names := make([]string, 0, len(pkg.Dependencies)+len(pkg.OptionalDependencies)+len(pkg.PeerDependencies))
+	names = slices.AppendSeq(names, maps.Keys(pkg.Dependencies))
+	names = slices.AppendSeq(names, maps.Keys(pkg.OptionalDependencies))
+	names = slices.AppendSeq(names, maps.Keys(pkg.PeerDependencies))
+
+	return names
+}
+
+func parseNpmLockPackages(packages map[string]*NpmLockPackage) []PackageDetails {
 	details := npmPackageDetailsMap{}
+	keysByPath := make(map[string]string)
 
 	keys := reflect.ValueOf(packages).MapKeys()
 	keysOrder := func(i, j int) bool { return keys[i].Interface().(string) < keys[j].Interface().(string) }
@@ -275,6 +357,7 @@ func parseNpmLockPackages(packages map[string]*NpmLockPackage) map[string]Packag
 		}
 
 		if !detail.Link {
+			keysByPath[namePath] = finalName + "@" + finalVersion
 			details.add(finalName+"@"+finalVersion, PackageDetails{
 				Name:           finalName,
 				Version:        detail.Version,
@@ -287,10 +370,23 @@ func parseNpmLockPackages(packages map[string]*NpmLockPackage) map[string]Packag
 		}
 	}
 
-	return details
+	edges := dependencyEdges{}
+	for namePath, parentKey := range keysByPath {
+		for _, dependencyName := range packages[namePath].dependencyNames() {
+			childPath, found := resolveNpmPackagePath(packages, namePath, dependencyName)
+			if !found {
+				continue
+			}
+			if childKey, ok := keysByPath[childPath]; ok {
+				edges.add(parentKey, childKey)
+			}
+		}
+	}
+
+	return collectWithDependencies(details, edges)
 }
 
-func parseNpmLock(lockfile NpmLockfile, lines []string) map[string]PackageDetails {
+func parseNpmLock(lockfile NpmLockfile, lines []string) []PackageDetails {
 	if lockfile.Packages != nil {
 		fileposition.InJSON("packages", lockfile.Packages, lines, 0)
 
@@ -299,7 +395,10 @@ func parseNpmLock(lockfile NpmLockfile, lines []string) map[string]PackageDetail
 
 	fileposition.InJSON("dependencies", lockfile.Dependencies, lines, 0)
 
-	return parseNpmLockDependencies(lockfile.Dependencies)
+	edges := dependencyEdges{}
+	collectNpmLockDependenciesEdges(lockfile.Dependencies, nil, edges)
+
+	return collectWithDependencies(parseNpmLockDependencies(lockfile.Dependencies), edges)
 }
 
 type NpmLockExtractor struct {
@@ -326,7 +425,7 @@ func (e NpmLockExtractor) Extract(f DepFile) ([]PackageDetails, error) {
 	}
 	parsedLockfile.SourceFile = f.Path()
 
-	return slices.Collect(maps.Values(parseNpmLock(*parsedLockfile, lines))), nil
+	return parseNpmLock(*parsedLockfile, lines), nil
 }
 
 var NpmExtractor = NpmLockExtractor{
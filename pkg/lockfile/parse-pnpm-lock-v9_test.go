@@ -90,7 +90,7 @@ func TestParsePnpmLock_v9_PeerDependencies(t *testing.T) {
 		t.Errorf("Got unexpected error: %v", err)
 	}
 
-	expectPackagesWithoutLocations(t, packages, []lockfile.PackageDetails{
+	expected := []lockfile.PackageDetails{
 		{
 			Name:           "acorn-jsx",
 			Version:        "5.3.2",
@@ -108,7 +108,10 @@ func TestParsePnpmLock_v9_PeerDependencies(t *testing.T) {
 			IsDirect:       false,
 			DepGroups:      []string{"prod"},
 		},
-	})
+	}
+	expected[0].Dependencies = append(expected[0].Dependencies, &expected[1])
+
+	expectPackagesWithoutLocations(t, packages, expected)
 }
 
 func TestParsePnpmLock_v9_PeerDependenciesAdvanced(t *testing.T) {
@@ -119,7 +122,7 @@ func TestParsePnpmLock_v9_PeerDependenciesAdvanced(t *testing.T) {
 		t.Errorf("Got unexpected error: %v", err)
 	}
 
-	expectPackagesWithoutLocations(t, packages, []lockfile.PackageDetails{
+	expected := []lockfile.PackageDetails{
 		{
 			Name:           "@eslint-community/eslint-utils",
 			Version:        "4.4.0",
@@ -236,7 +239,41 @@ func TestParsePnpmLock_v9_PeerDependenciesAdvanced(t *testing.T) {
 			IsDirect:       false,
 			DepGroups:      []string{"prod"},
 		},
-	})
+	}
+	expected[0].Dependencies = append(expected[0].Dependencies, &expected[8])
+	expected[1].Dependencies = append(expected[1].Dependencies, &expected[7])
+	expected[2].Dependencies = append(expected[2].Dependencies, &expected[3])
+	expected[2].Dependencies = append(expected[2].Dependencies, &expected[4])
+	expected[2].Dependencies = append(expected[2].Dependencies, &expected[6])
+	expected[2].Dependencies = append(expected[2].Dependencies, &expected[7])
+	expected[2].Dependencies = append(expected[2].Dependencies, &expected[8])
+	expected[2].Dependencies = append(expected[2].Dependencies, &expected[11])
+	expected[2].Dependencies = append(expected[2].Dependencies, &expected[12])
+	expected[3].Dependencies = append(expected[3].Dependencies, &expected[5])
+	expected[3].Dependencies = append(expected[3].Dependencies, &expected[7])
+	expected[3].Dependencies = append(expected[3].Dependencies, &expected[8])
+	expected[3].Dependencies = append(expected[3].Dependencies, &expected[12])
+	expected[4].Dependencies = append(expected[4].Dependencies, &expected[5])
+	expected[4].Dependencies = append(expected[4].Dependencies, &expected[6])
+	expected[4].Dependencies = append(expected[4].Dependencies, &expected[7])
+	expected[4].Dependencies = append(expected[4].Dependencies, &expected[8])
+	expected[4].Dependencies = append(expected[4].Dependencies, &expected[11])
+	expected[4].Dependencies = append(expected[4].Dependencies, &expected[12])
+	expected[5].Dependencies = append(expected[5].Dependencies, &expected[7])
+	expected[5].Dependencies = append(expected[5].Dependencies, &expected[11])
+	expected[5].Dependencies = append(expected[5].Dependencies, &expected[12])
+	expected[6].Dependencies = append(expected[6].Dependencies, &expected[0])
+	expected[6].Dependencies = append(expected[6].Dependencies, &expected[5])
+	expected[6].Dependencies = append(expected[6].Dependencies, &expected[8])
+	expected[8].Dependencies = append(expected[8].Dependencies, &expected[0])
+	expected[8].Dependencies = append(expected[8].Dependencies, &expected[1])
+	expected[8].Dependencies = append(expected[8].Dependencies, &expected[13])
+	expected[8].Dependencies = append(expected[8].Dependencies, &expected[7])
+	expected[10].Dependencies = append(expected[10].Dependencies, &expected[9])
+	expected[11].Dependencies = append(expected[11].Dependencies, &expected[12])
+	expected[13].Dependencies = append(expected[13].Dependencies, &expected[10])
+
+	expectPackagesWithoutLocations(t, packages, expected)
 }
 
 func TestParsePnpmLock_v9_MultipleVersions(t *testing.T) {
@@ -247,7 +284,7 @@ func TestParsePnpmLock_v9_MultipleVersions(t *testing.T) {
 		t.Errorf("Got unexpected error: %v", err)
 	}
 
-	expectPackagesWithoutLocations(t, packages, []lockfile.PackageDetails{
+	expected := []lockfile.PackageDetails{
 		{
 			Name:           "uuid",
 			Version:        "8.0.0",
@@ -274,7 +311,10 @@ func TestParsePnpmLock_v9_MultipleVersions(t *testing.T) {
 			IsDirect:       true,
 			DepGroups:      []string{"prod"},
 		},
-	})
+	}
+	expected[2].Dependencies = append(expected[2].Dependencies, &expected[0])
+
+	expectPackagesWithoutLocations(t, packages, expected)
 }
 
 func TestParsePnpmLock_v9_Commits(t *testing.T) {
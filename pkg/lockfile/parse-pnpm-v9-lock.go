@@ -119,15 +119,21 @@ func addDependencyToPackageDetails(dependency PackageDetails, deps map[string]Pa
 	return deps
 }
 
-func extractTransitiveDeps(lockfile PnpmLockfile, root PnpmDirectDependency, targetedKey string, deps map[string]PackageDetails) map[string]PackageDetails {
+type pnpmSnapshotReference struct {
+	Name    string
+	Version string
+}
+
+func extractTransitiveDeps(lockfile PnpmLockfile, root PnpmDirectDependency, deps map[string]PackageDetails, edges dependencyEdges) map[string]PackageDetails {
 	// Need to look at dependencies
 	visitedSnapshots := make(map[string]bool)
-	snapshotQueue := make([]string, 0)
-	snapshotQueue = append(snapshotQueue, targetedKey)
+	snapshotQueue := make([]pnpmSnapshotReference, 0)
+	snapshotQueue = append(snapshotQueue, pnpmSnapshotReference{Name: root.Pkg.Name, Version: root.Dep.Version})
 
 	for len(snapshotQueue) > 0 {
-		targetedKey = snapshotQueue[0]
+		parent := snapshotQueue[0]
 		snapshotQueue = snapshotQueue[1:]
+		targetedKey := parent.Name + "@" + parent.Version
 
 		if _, visited := visitedSnapshots[targetedKey]; visited {
 			continue
@@ -140,34 +146,23 @@ func extractTransitiveDeps(lockfile PnpmLockfile, root PnpmDirectDependency, tar
 			continue
 		}
 
-		for depName, depVersion := range snapshot.Dependencies {
-			transitiveDep := PackageDetails{
-				Name:           depName,
-				Version:        getCleanedVersion(lockfile, depName, depVersion),
-				Commit:         getCommitFromVersion(depVersion),
-				Ecosystem:      models.EcosystemNPM,
-				DepGroups:      root.Pkg.DepGroups,
-				PackageManager: models.Pnpm,
-				IsDirect:       false,
-			}
-			addDependencyToPackageDetails(transitiveDep, deps)
-			childKey := depName + "@" + depVersion
-			snapshotQueue = append(snapshotQueue, childKey)
-		}
-
-		for depName, depVersion := range snapshot.OptionalDependencies {
-			transitiveDep := PackageDetails{
-				Name:           depName,
-				Version:        getCleanedVersion(lockfile, depName, depVersion),
-				Commit:         getCommitFromVersion(depVersion),
-				Ecosystem:      models.EcosystemNPM,
-				DepGroups:      root.Pkg.DepGroups,
-				PackageManager: models.Pnpm,
-				IsDirect:       false,
+		parentKey := parent.Name + "@" + getCleanedVersion(lockfile, parent.Name, parent.Version)
+
+		for _, snapshotDependencies := range []map[string]string{snapshot.Dependencies, snapshot.OptionalDependencies} {
+			for depName, depVersion := range snapshotDependencies {
+				transitiveDep := PackageDetails{
+					Name:           depName,
+					Version:        getCleanedVersion(lockfile, depName, depVersion),
+					Commit:         getCommitFromVersion(depVersion),
+					Ecosystem:      models.EcosystemNPM,
+					DepGroups:      root.Pkg.DepGroups,
+					PackageManager: models.Pnpm,
+					IsDirect:       false,
+				}
+				addDependencyToPackageDetails(transitiveDep, deps)
+				edges.add(parentKey, transitiveDep.Name+"@"+transitiveDep.Version)
+				snapshotQueue = append(snapshotQueue, pnpmSnapshotReference{Name: depName, Version: depVersion})
 			}
-			addDependencyToPackageDetails(transitiveDep, deps)
-			childKey := depName + "@" + depVersion
-			snapshotQueue = append(snapshotQueue, childKey)
 		}
 	}
 
@@ -209,12 +204,13 @@ func parsePnpmLock(lockfile PnpmLockfile) []PackageDetails {
 	}
 
 	packages := make(map[string]PackageDetails)
+	edges := dependencyEdges{}
 	for _, direct := range directDependencies {
 		packages = addDependencyToPackageDetails(direct.Pkg, packages)
-		packages = extractTransitiveDeps(lockfile, direct, direct.Pkg.Name+"@"+direct.Dep.Version, packages)
+		packages = extractTransitiveDeps(lockfile, direct, packages, edges)
 	}
 
-	return slices.Collect(maps.Values(packages))
+	return collectWithDependencies(packages, edges)
 }
 
 func (e PnpmLockExtractor) Extract(f DepFile) ([]PackageDetails, error) {
@@ -0,0 +1,55 @@
+package lockfile
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// A RootFSFile represents a file of a root filesystem (such as the one of a container image) extracted
+// in a directory of the local filesystem. Absolute paths opened from it are resolved inside the root directory.
+type RootFSFile struct {
+	LocalFile
+
+	root string
+}
+
+func (f RootFSFile) Open(path string) (NestedDepFile, error) {
+	if filepath.IsAbs(path) {
+		return OpenRootFSDepFile(f.root, filepath.Join(f.root, path))
+	}
+
+	return OpenRootFSDepFile(f.root, filepath.Join(filepath.Dir(f.path), path))
+}
+
+// Glob resolves absolute patterns inside the root directory
+func (f RootFSFile) Glob(pattern string) ([]string, error) {
+	if filepath.IsAbs(filepath.FromSlash(pattern)) {
+		return f.LocalFile.Glob(filepath.Join(f.root, filepath.FromSlash(pattern)))
+	}
+
+	return f.LocalFile.Glob(pattern)
+}
+
+// OpenRootFSDepFile opens the file at the given path, which has to be located in the given root directory
+func OpenRootFSDepFile(root string, path string) (NestedDepFile, error) {
+	file, err := OpenLocalDepFile(path)
+	if err != nil {
+		return RootFSFile{}, err
+	}
+	localFile, _ := file.(LocalFile)
+
+	// Very unlikely to have Abs return an error if the file opens correctly
+	root, _ = filepath.Abs(root)
+
+	return RootFSFile{LocalFile: localFile, root: root}, nil
+}
+
+// hasRootPathSuffix checks if the path targets the given absolute path of a root filesystem,
+// either because it is that path or because the root filesystem is extracted in a parent directory
+func hasRootPathSuffix(path string, rootPath string) bool {
+	return strings.HasSuffix(filepath.ToSlash(path), rootPath)
+}
+
+var _ DepFile = RootFSFile{}
+var _ NestedDepFile = RootFSFile{}
+var _ GlobbableDepFile = RootFSFile{}
@@ -0,0 +1,96 @@
+package lockfile_test
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/lockfile"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+)
+
+func TestOpenRootFSDepFile_ResolvesAbsolutePathsInRoot(t *testing.T) {
+	t.Parallel()
+
+	root := "fixtures/rootfs"
+	file, err := lockfile.OpenRootFSDepFile(root, filepath.Join(root, "lib/apk/db/installed"))
+	if err != nil {
+		t.Fatalf("could not open file %v", err)
+	}
+	defer file.Close()
+
+	release, err := file.Open("/etc/alpine-release")
+	if err != nil {
+		t.Fatalf("could not open file relatively to the root: %v", err)
+	}
+	defer release.Close()
+
+	content, err := io.ReadAll(release)
+	if err != nil {
+		t.Fatalf("could not read file %v", err)
+	}
+	if string(content) != "3.19.1\n" {
+		t.Errorf("got %q, want %q", string(content), "3.19.1\n")
+	}
+
+	sibling, err := file.Open("installed")
+	if err != nil {
+		t.Fatalf("could not open file relatively to the current one: %v", err)
+	}
+	sibling.Close()
+}
+
+func TestApkInstalledExtractor_RootFS(t *testing.T) {
+	t.Parallel()
+
+	root := "fixtures/rootfs"
+	path := filepath.Join(root, "lib/apk/db/installed")
+	if !(lockfile.ApkInstalledExtractor{}).ShouldExtract(path) {
+		t.Fatalf("ShouldExtract(%s) should be true", path)
+	}
+
+	file, err := lockfile.OpenRootFSDepFile(root, path)
+	if err != nil {
+		t.Fatalf("could not open file %v", err)
+	}
+	defer file.Close()
+
+	packages, err := lockfile.ApkInstalledExtractor{}.Extract(file)
+	if err != nil {
+		t.Errorf("Got unexpected error: %v", err)
+	}
+
+	expectPackages(t, packages, []lockfile.PackageDetails{
+		{
+			Name:           "busybox",
+			Version:        "1.36.1-r15",
+			Commit:         "1dbf7a793afae640ea643a055b6dd4f430ac116b",
+			Ecosystem:      models.Ecosystem("Alpine:v3.19"),
+			PackageManager: models.Unknown,
+		},
+	})
+}
+
+func TestOSPackagesExtractors_ShouldExtract(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		extractor lockfile.Extractor
+		path      string
+		want      bool
+	}{
+		{lockfile.DpkgStatusExtractor{}, "/var/lib/dpkg/status", true},
+		{lockfile.DpkgStatusExtractor{}, "/tmp/image/var/lib/dpkg/status", true},
+		{lockfile.DpkgStatusExtractor{}, "/var/lib/dpkg/status-old", false},
+		{lockfile.DpkgStatusExtractor{}, "status", false},
+		{lockfile.ApkInstalledExtractor{}, "/lib/apk/db/installed", true},
+		{lockfile.ApkInstalledExtractor{}, "/tmp/image/lib/apk/db/installed", true},
+		{lockfile.ApkInstalledExtractor{}, "/lib/apk/db/installed.bak", false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.extractor.ShouldExtract(tt.path); got != tt.want {
+			t.Errorf("ShouldExtract(%v) got = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
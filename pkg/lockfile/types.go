@@ -19,6 +19,9 @@ type PackageDetails struct {
 	PackageManager  models.PackageManager `json:"packageManager,omitempty"`
 	IsDirect        bool                  `json:"isDirect,omitempty"`
 	Dependencies    []*PackageDetails     `json:"dependencies,omitempty"`
+	Hashes          []models.Hash         `json:"hashes,omitempty"`
+	// LayerDigest is the digest of the container image layer which introduced the package, when scanning an image
+	LayerDigest string `json:"layerDigest,omitempty"`
 }
 
 type Ecosystem string
@@ -45,6 +45,20 @@ func TestIsDevGroup_Gradle(t *testing.T) {
 	runTestCases(t, models.EcosystemMaven, testCases)
 }
 
+func TestIsDevGroup_Cargo(t *testing.T) {
+	t.Parallel()
+	testCases := []devGroupTestCase{
+		{name: "dependencies", scopes: []string{"prod"}, isDevGroup: false},
+		{name: "dev-dependencies", scopes: []string{"dev"}, isDevGroup: true},
+		// Build dependencies are compiled and run when building the crate
+		{name: "build-dependencies", scopes: []string{"build"}, isDevGroup: false},
+		{name: "dependencies and dev-dependencies", scopes: []string{"prod", "dev"}, isDevGroup: false},
+		{name: "transitive", scopes: []string{}, isDevGroup: false},
+	}
+
+	runTestCases(t, models.EcosystemCratesIO, testCases)
+}
+
 func runTestCases(t *testing.T, ecosystem models.Ecosystem, testCases []devGroupTestCase) {
 	t.Helper()
 	for _, testCase := range testCases {
@@ -8,6 +8,10 @@ const (
 	PackageManagerMetadata     PackageMetadataType = "package-manager"
 	IsDirectDependencyMetadata PackageMetadataType = "is-direct"
 	IsDevDependencyMetadata    PackageMetadataType = "is-dev"
+	LayerDigestMetadata        PackageMetadataType = "layer-digest"
+	// DependencyPathMetadata holds the PURLs of the ancestors of a transitive package with reachable symbols,
+	// from the direct dependency introducing it to its parent, as a JSON array
+	DependencyPathMetadata PackageMetadataType = "dependency-path"
 )
 
 type PackageMetadata map[PackageMetadataType]string
@@ -27,10 +27,22 @@ type ReachableVulnerability struct {
 // vulnerability was determined to be reachable.
 type ReachableSymbolLocations []ReachableSymbolLocation
 
+// SourceType tells whether a source file holds the production code or the tests of a project.
+type SourceType string
+
+const (
+	ProductionSource SourceType = "production"
+	TestSource       SourceType = "test"
+)
+
 // ReachableSymbolLocation details where a vulnerability was deemed reachable.
 type ReachableSymbolLocation struct {
 	PackageLocation
 	Symbol string `json:"symbol"`
+	// Method is the method of a compiled class using the symbol, for the usages found in class files and jar archives
+	Method string `json:"method,omitempty"`
+	// SourceType tells whether the symbol is used by the production code or by the tests
+	SourceType SourceType `json:"source_type,omitempty"`
 }
 
 // MarshalToJSONString marshals the ReachableSymbolLocations list into a JSON string
@@ -52,6 +64,10 @@ type AdvisoryToCheck struct {
 	Symbols    []Symbols
 }
 
+// Symbols is a vulnerable symbol of a package.
+// For "class" and "type_reference" symbols, Value is the package of the class and Name its simple name.
+// For "method", "static_method" and "field" symbols, Value is the fully qualified name of the declaring class
+// and Name the name of the member.
 type Symbols struct {
 	Type  string
 	Value string
@@ -49,6 +49,8 @@ type PackageVulns struct {
 	Vulnerabilities           []Vulnerability    `json:"vulnerabilities,omitempty"`
 	Metadata                  PackageMetadata    `json:"metadata,omitempty"`
 	AdvisoriesForReachability []string           `json:"reachability_advisories,omitempty"`
+	// Dependencies holds the PURLs of the packages this package directly depends on
+	Dependencies []string `json:"dependencies,omitempty"`
 }
 
 type AnalysisInfo struct {
@@ -62,4 +64,18 @@ type PackageInfo struct {
 	Ecosystem string `json:"ecosystem"`
 	Commit    string `json:"commit,omitempty"`
 	Purl      string `json:"purl,omitempty"`
+	Hashes    []Hash `json:"hashes,omitempty"`
+}
+
+// HashAlgorithm is the algorithm of a hash, named as in CycloneDX
+type HashAlgorithm string
+
+const (
+	HashSHA256 HashAlgorithm = "SHA-256"
+)
+
+// Hash is a digest of the archive of a package, such as the checksum of a crate recorded in Cargo.lock
+type Hash struct {
+	Algorithm HashAlgorithm `json:"algorithm"`
+	Value     string        `json:"value"`
 }
@@ -0,0 +1,133 @@
+package reachability
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/utility/fileposition"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+)
+
+// detectionCacheVersion is part of the keys of the cache, it should be bumped whenever the detections change
+const detectionCacheVersion = "2"
+
+// detectionCache stores on disk the detection results of the files whose detections only depend on their content,
+// keyed by the hash of their content and of the advisories checked, so that unchanged files are not parsed again
+type detectionCache struct {
+	dir string
+	// advisoryHashes holds the hash of the advisories to check of each language
+	advisoryHashes map[string]string
+}
+
+// newDetectionCache returns the cache stored in the given directory, or nil when there is no directory
+// or when it cannot be created
+func newDetectionCache(dir string, advisoriesToCheckPerLanguage map[string][]models.AdvisoryToCheck) *detectionCache {
+	if dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Printf("failed to create the reachability cache directory, continuing without cache: %v\n", err)
+		return nil
+	}
+
+	cache := &detectionCache{dir: dir, advisoryHashes: make(map[string]string)}
+	for language, advisoriesToCheck := range advisoriesToCheckPerLanguage {
+		// The advisories are sorted, as their order depends on the response of the API
+		sorted := slices.Clone(advisoriesToCheck)
+		slices.SortFunc(sorted, func(a, b models.AdvisoryToCheck) int {
+			return cmp.Or(cmp.Compare(a.Purl, b.Purl), cmp.Compare(a.AdvisoryID, b.AdvisoryID))
+		})
+		encoded, err := json.Marshal(sorted)
+		if err != nil {
+			log.Printf("failed to hash the advisories of %s, continuing without cache: %v\n", language, err)
+			return nil
+		}
+		hash := sha256.Sum256(encoded)
+		cache.advisoryHashes[language] = hex.EncodeToString(hash[:])
+	}
+
+	return cache
+}
+
+// key returns the key of the detection results of a file of the given language and extension
+func (c *detectionCache) key(language string, extension string, content []byte) string {
+	hash := sha256.New()
+	for _, part := range []string{detectionCacheVersion, language, extension, c.advisoryHashes[language]} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	hash.Write(content)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// load returns the cached detection results of a file, whose locations are moved to the given file
+// as files with the same content share their results (see store)
+func (c *detectionCache) load(key string, file SourceFile) (models.DetectionResults, bool) {
+	content, err := os.ReadFile(filepath.Join(c.dir, key+".json"))
+	if err != nil {
+		return nil, false
+	}
+
+	var detectionResults models.DetectionResults
+	if err := json.Unmarshal(content, &detectionResults); err != nil {
+		return nil, false
+	}
+
+	filename := fileposition.ToRelativePath(file.Dir, file.Path)
+	for _, advisories := range detectionResults {
+		for _, locations := range advisories {
+			for i := range locations {
+				locations[i].PackageLocation.Filename = filename + locations[i].PackageLocation.Filename
+			}
+		}
+	}
+
+	return detectionResults, true
+}
+
+// store writes the detection results of a file, through a temporary file as several workers can store the same key.
+// The locations are stored relatively to the file, keeping only the path of the entries of archives (!/com/App.class).
+func (c *detectionCache) store(key string, file SourceFile, detectionResults models.DetectionResults) {
+	filename := fileposition.ToRelativePath(file.Dir, file.Path)
+	stored := make(models.DetectionResults, len(detectionResults))
+	for purl, advisories := range detectionResults {
+		stored[purl] = make(map[string]models.ReachableSymbolLocations, len(advisories))
+		for advisoryID, locations := range advisories {
+			stored[purl][advisoryID] = slices.Clone(locations)
+			for i := range locations {
+				stored[purl][advisoryID][i].PackageLocation.Filename = strings.TrimPrefix(locations[i].PackageLocation.Filename, filename)
+			}
+		}
+	}
+
+	content, err := json.Marshal(stored)
+	if err != nil {
+		log.Printf("failed to encode reachability cache entry: %v\n", err)
+		return
+	}
+
+	temp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		log.Printf("failed to write reachability cache entry: %v\n", err)
+		return
+	}
+	_, err = temp.Write(content)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), filepath.Join(c.dir, key+".json"))
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+		log.Printf("failed to write reachability cache entry: %v\n", err)
+	}
+}
@@ -0,0 +1,520 @@
+package codefile
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+	tree_sitter_csharp "github.com/tree-sitter/tree-sitter-c-sharp/bindings/go"
+)
+
+var tsQueryForCSharpClass = `
+(object_creation_expression
+	type: (_) @class
+)`
+
+// Methods can be called without object when they are inherited or statically imported
+var tsQueryForCSharpMethod = `
+(invocation_expression
+	function: [
+		(identifier) @name
+		(generic_name) @name
+		(member_access_expression name: (_) @name)
+		(member_binding_expression name: (_) @name)
+	] @function
+)`
+
+// The dotted names are read from their outermost node, which can be a type or an expression
+var tsQueryForCSharpName = `
+[
+	(identifier)
+	(generic_name)
+	(qualified_name)
+	(alias_qualified_name)
+	(member_access_expression)
+] @name`
+
+var tsQueryForCSharpUsing = `(using_directive) @using`
+
+var tsQueryForCSharpNamespace = `
+[
+	(namespace_declaration name: (_) @namespace)
+	(file_scoped_namespace_declaration name: (_) @namespace)
+]`
+
+var tsQueryForCSharpDeclaration = `
+[
+	(class_declaration name: (identifier) @name)
+	(struct_declaration name: (identifier) @name)
+	(interface_declaration name: (identifier) @name)
+	(enum_declaration name: (identifier) @name)
+	(record_declaration name: (identifier) @name)
+]`
+
+var csSymbolTypeToTSQuery = map[string]string{
+	"class":          tsQueryForCSharpClass,
+	"method":         tsQueryForCSharpMethod,
+	"type_reference": tsQueryForCSharpName,
+}
+
+// csSymbolMatcher checks whether the captures of a query match a symbol,
+// and returns the first and last nodes of the code to report when they do
+type csSymbolMatcher func(file *csFile, captures map[string]*treesitter.Node, s models.Symbols) (*treesitter.Node, *treesitter.Node, bool)
+
+var csSymbolTypeToMatcher = map[string]csSymbolMatcher{
+	"class":          matchCSharpClass,
+	"method":         matchCSharpMethod,
+	"type_reference": matchCSharpTypeReference,
+}
+
+// ReachabilityCSharp detects the usages of the vulnerable symbols of NuGet packages in C# files.
+// Like in Java, the Value of "class" and "type_reference" symbols is the namespace of the class and their Name its name,
+// while the Value of "method" symbols is the fully qualified name of the class and their Name the name of the method.
+type ReachabilityCSharp struct {
+	tsParser               *treesitter.Parser
+	tsQueriesPerSymbolType map[string]*treesitter.Query
+	// tsQueries holds the queries indexing the names, usings and declarations of files
+	tsQueries map[string]*treesitter.Query
+	// globalUsings caches the global using directives of the projects analyzed so far, by directory
+	globalUsings map[string]*csScope
+	// projects caches the directory of the project of the directories analyzed so far
+	projects map[string]string
+}
+
+// NewCSharpReachableDetector creates a detector for C# files, Close should be called once all the files are parsed
+func NewCSharpReachableDetector() (*ReachabilityCSharp, error) {
+	tsLanguage := treesitter.NewLanguage(tree_sitter_csharp.Language())
+
+	tsParser := treesitter.NewParser()
+
+	err := tsParser.SetLanguage(tsLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set tree-sitter C# language on parser: %w", err)
+	}
+
+	tsQueriesPerSymbolType, err := newQueries(tsLanguage, csSymbolTypeToTSQuery)
+	if err != nil {
+		return nil, err
+	}
+	tsQueries, err := newQueries(tsLanguage, map[string]string{
+		"names":        tsQueryForCSharpName,
+		"usings":       tsQueryForCSharpUsing,
+		"namespaces":   tsQueryForCSharpNamespace,
+		"declarations": tsQueryForCSharpDeclaration,
+	})
+	if err != nil {
+		closeQueries(tsQueriesPerSymbolType)
+
+		return nil, err
+	}
+
+	return &ReachabilityCSharp{
+		tsParser:               tsParser,
+		tsQueriesPerSymbolType: tsQueriesPerSymbolType,
+		tsQueries:              tsQueries,
+		globalUsings:           make(map[string]*csScope),
+		projects:               make(map[string]string),
+	}, nil
+}
+
+// Close closes all hanging tree-sitter related resources, and releases the using directives kept in cache.
+func (r *ReachabilityCSharp) Close() {
+	r.tsParser.Close()
+	closeQueries(r.tsQueriesPerSymbolType)
+	closeQueries(r.tsQueries)
+	clear(r.globalUsings)
+	clear(r.projects)
+}
+
+// csScope holds the names brought in scope by the using and namespace declarations of a C# file
+type csScope struct {
+	// namespaces lists the namespaces imported by `using Namespace;`
+	namespaces []string
+	// aliases maps the aliases declared by `using Alias = Name;` to their target
+	aliases map[string]string
+	// staticClasses lists the classes imported by `using static Class;`
+	staticClasses []string
+	// fileNamespaces lists the namespaces declared by the file, whose types and parent namespaces are in scope
+	fileNamespaces []string
+	// declared holds the simple names of the types declared by the file
+	declared map[string]bool
+}
+
+// csName is a dotted name of the code, such as Newtonsoft.Json.JsonConvert.DeserializeObject
+type csName struct {
+	segments []string
+	// prefixes holds the nodes spanning the first segments of the name, up to each of its segments
+	prefixes []*treesitter.Node
+	absolute bool
+}
+
+// csFile is a parsed C# file, along with the names it brings in scope and the names it uses
+type csFile struct {
+	*SourceContext
+
+	scope *csScope
+	names []csName
+}
+
+func (r *ReachabilityCSharp) Detect(dir string, path string, detectionResults models.DetectionResults, advisoriesToCheck []models.AdvisoryToCheck) error {
+	source, err := NewSourceContext(r.tsParser, dir, path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	scope := r.newCSharpScope(source, false)
+	scope.merge(r.projectGlobalUsings(dir, filepath.Dir(path)))
+
+	file := &csFile{SourceContext: source, scope: scope}
+	for _, node := range source.Captures(r.tsQueries["names"]) {
+		if name, ok := csNameOf(source, node); ok && isCSharpNameRoot(node) {
+			file.names = append(file.names, name)
+		}
+	}
+
+	for _, advisoryToCheck := range advisoriesToCheck {
+		for _, s := range advisoryToCheck.Symbols {
+			query := r.tsQueriesPerSymbolType[s.Type]
+			if query == nil {
+				log.Printf("No query found for symbol type %s\n", s.Type)
+				continue
+			}
+			matcher := csSymbolTypeToMatcher[s.Type]
+
+			var hits [][2]*treesitter.Node
+			source.Matches(query, func(captures map[string]*treesitter.Node) {
+				if from, to, ok := matcher(file, captures, s); ok {
+					hits = append(hits, [2]*treesitter.Node{from, to})
+				}
+			})
+
+			for _, hit := range hits {
+				if err := source.Report(detectionResults, advisoryToCheck, hit[0], hit[1]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func matchCSharpClass(file *csFile, captures map[string]*treesitter.Node, s models.Symbols) (*treesitter.Node, *treesitter.Node, bool) {
+	node := captures["class"]
+	name, ok := csNameOf(file.SourceContext, node)
+
+	return node, node, ok && file.scope.refersTo(name.segments, name.absolute, s.Value+"."+s.Name)
+}
+
+// matchCSharpMethod matches the calls of the methods of a class qualified by the class or statically imported.
+// Objects are not typed, so the calls of instance methods are matched by name in the files using their class.
+func matchCSharpMethod(file *csFile, captures map[string]*treesitter.Node, s models.Symbols) (*treesitter.Node, *treesitter.Node, bool) {
+	function, name := captures["function"], captures["name"]
+	if file.Text(csIdentifierOf(name)) != s.Name {
+		return nil, nil, false
+	}
+
+	switch function.Kind() {
+	case "identifier", "generic_name":
+		return function, function, slices.Contains(file.scope.staticClasses, s.Value)
+	case "member_access_expression":
+		object, ok := csNameOf(file.SourceContext, function.ChildByFieldName("expression"))
+		if ok && file.scope.refersTo(object.segments, object.absolute, s.Value) {
+			return function, function, true
+		}
+	}
+
+	return name, name, file.referencesType(s.Value)
+}
+
+func matchCSharpTypeReference(file *csFile, captures map[string]*treesitter.Node, s models.Symbols) (*treesitter.Node, *treesitter.Node, bool) {
+	node := captures["name"]
+	if !isCSharpNameRoot(node) {
+		return nil, nil, false
+	}
+	name, ok := csNameOf(file.SourceContext, node)
+	if !ok {
+		return nil, nil, false
+	}
+
+	// Only the longest prefix of the name which is a type is reported, e.g. Newtonsoft.Json.JsonConvert,
+	// and the name of a called method is not a type
+	last := len(name.segments) - 1
+	if parent := node.Parent(); parent != nil && parent.Kind() == "invocation_expression" {
+		last--
+	}
+	for i := last; i >= 0; i-- {
+		if file.scope.refersTo(name.segments[:i+1], name.absolute, s.Value+"."+s.Name) {
+			return name.prefixes[i], name.prefixes[i], true
+		}
+	}
+
+	return nil, nil, false
+}
+
+// referencesType returns whether one of the names used by the file refers to the given type
+func (f *csFile) referencesType(qualifiedName string) bool {
+	return slices.ContainsFunc(f.names, func(name csName) bool {
+		for i := range name.segments {
+			if f.scope.refersTo(name.segments[:i+1], name.absolute, qualifiedName) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// isCSharpNameRoot returns whether a node is a whole dotted name rather than a part of one,
+// skipping the names of the using, namespace and type declarations
+func isCSharpNameRoot(node *treesitter.Node) bool {
+	parent := node.Parent()
+	if parent == nil {
+		return true
+	}
+
+	switch parent.Kind() {
+	case "qualified_name", "alias_qualified_name", "member_access_expression", "member_binding_expression", "generic_name",
+		"using_directive", "namespace_declaration", "file_scoped_namespace_declaration":
+		return false
+	case "class_declaration", "struct_declaration", "interface_declaration", "enum_declaration", "record_declaration":
+		name := parent.ChildByFieldName("name")
+
+		return name == nil || name.StartByte() != node.StartByte()
+	}
+
+	return true
+}
+
+// csNameOf reads the dotted name of a node, which is not a name when it involves anything else than identifiers,
+// such as an invocation or `this`. global::Namespace.Type is an absolute name, and Alias::Type is read like Alias.Type.
+func csNameOf(source *SourceContext, node *treesitter.Node) (csName, bool) {
+	if node == nil {
+		return csName{}, false
+	}
+
+	switch node.Kind() {
+	case "identifier", "generic_name":
+		return csName{segments: []string{source.Text(csIdentifierOf(node))}, prefixes: []*treesitter.Node{node}}, true
+	case "alias_qualified_name":
+		alias, name := node.ChildByFieldName("alias"), node.ChildByFieldName("name")
+		if alias == nil || name == nil {
+			return csName{}, false
+		}
+		segment := source.Text(csIdentifierOf(name))
+		if source.Text(alias) == "global" {
+			return csName{segments: []string{segment}, prefixes: []*treesitter.Node{node}, absolute: true}, true
+		}
+
+		return csName{segments: []string{source.Text(alias), segment}, prefixes: []*treesitter.Node{alias, node}}, true
+	case "qualified_name", "member_access_expression":
+		qualifierField := "qualifier"
+		if node.Kind() == "member_access_expression" {
+			qualifierField = "expression"
+		}
+		qualifier, ok := csNameOf(source, node.ChildByFieldName(qualifierField))
+		name := node.ChildByFieldName("name")
+		if !ok || name == nil {
+			return csName{}, false
+		}
+		qualifier.segments = append(qualifier.segments, source.Text(csIdentifierOf(name)))
+		qualifier.prefixes = append(qualifier.prefixes, node)
+
+		return qualifier, true
+	}
+
+	return csName{}, false
+}
+
+// csIdentifierOf returns the identifier of a name, without its type arguments
+func csIdentifierOf(node *treesitter.Node) *treesitter.Node {
+	if node.Kind() == "generic_name" && node.NamedChildCount() > 0 {
+		return node.NamedChild(0)
+	}
+
+	return node
+}
+
+// newCSharpScope reads the using directives and the declarations of a file, only keeping the global usings if asked to
+func (r *ReachabilityCSharp) newCSharpScope(source *SourceContext, globalOnly bool) *csScope {
+	scope := &csScope{aliases: make(map[string]string), declared: make(map[string]bool)}
+
+	for _, node := range source.Captures(r.tsQueries["usings"]) {
+		scope.addUsing(source, node, globalOnly)
+	}
+	if globalOnly {
+		return scope
+	}
+
+	for _, node := range source.Captures(r.tsQueries["namespaces"]) {
+		if name, ok := csNameOf(source, node); ok {
+			scope.fileNamespaces = append(scope.fileNamespaces, strings.Join(name.segments, "."))
+		}
+	}
+	for _, node := range source.Captures(r.tsQueries["declarations"]) {
+		scope.declared[source.Text(node)] = true
+	}
+
+	return scope
+}
+
+// addUsing reads a using directive, such as `global using static Namespace.Class;` or `using Alias = Namespace;`
+func (s *csScope) addUsing(source *SourceContext, node *treesitter.Node, globalOnly bool) {
+	var global, static bool
+	for i := range node.ChildCount() {
+		switch node.Child(i).Kind() {
+		case "global":
+			global = true
+		case "static":
+			static = true
+		}
+	}
+	if (globalOnly && !global) || node.NamedChildCount() == 0 {
+		return
+	}
+
+	// The target is the last name of the directive, following the alias if any
+	name, ok := csNameOf(source, node.NamedChild(node.NamedChildCount()-1))
+	if !ok {
+		return
+	}
+	target := strings.Join(name.segments, ".")
+
+	alias := node.ChildByFieldName("name")
+	switch {
+	case alias != nil:
+		s.aliases[source.Text(alias)] = target
+	case static:
+		s.staticClasses = append(s.staticClasses, target)
+	default:
+		s.namespaces = append(s.namespaces, target)
+	}
+}
+
+// merge adds the using directives of another scope, such as the global usings of the project
+func (s *csScope) merge(other *csScope) {
+	if other == nil {
+		return
+	}
+
+	s.namespaces = append(s.namespaces, other.namespaces...)
+	s.staticClasses = append(s.staticClasses, other.staticClasses...)
+	for alias, target := range other.aliases {
+		if _, ok := s.aliases[alias]; !ok {
+			s.aliases[alias] = target
+		}
+	}
+}
+
+// refersTo returns whether a dotted name can refer to the given fully qualified name,
+// directly, through an alias, a using directive or the namespaces of the file
+func (s *csScope) refersTo(segments []string, absolute bool, qualifiedName string) bool {
+	if len(segments) == 0 {
+		return false
+	}
+
+	name := strings.Join(segments, ".")
+	if absolute || name == qualifiedName {
+		return name == qualifiedName
+	}
+
+	if target, ok := s.aliases[segments[0]]; ok {
+		return strings.Join(append([]string{target}, segments[1:]...), ".") == qualifiedName
+	}
+
+	// Types declared in the file shadow the imported ones
+	if !s.declared[segments[0]] {
+		for _, namespace := range s.namespaces {
+			if namespace+"."+name == qualifiedName {
+				return true
+			}
+		}
+	}
+
+	// The types of the namespaces enclosing the file are in scope
+	for _, namespace := range s.fileNamespaces {
+		for prefix := namespace; prefix != ""; prefix, _ = splitQualifiedName(prefix) {
+			if prefix+"."+name == qualifiedName {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// projectGlobalUsings returns the global using directives of the project of a directory, which apply to all its files.
+// The project is the nearest directory holding a .csproj file within the scanned directory.
+func (r *ReachabilityCSharp) projectGlobalUsings(dir string, fileDir string) *csScope {
+	project := r.projectOf(dir, fileDir)
+	if project == "" {
+		return nil
+	}
+	if scope, ok := r.globalUsings[project]; ok {
+		return scope
+	}
+
+	scope := &csScope{aliases: make(map[string]string), declared: make(map[string]bool)}
+	err := filepath.WalkDir(project, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// The build outputs are not part of the code of the project, unlike the usings generated in obj
+		if d.IsDir() && d.Name() == "bin" {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || filepath.Ext(path) != ".cs" {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if !bytes.Contains(content, []byte("global")) {
+			return nil
+		}
+
+		source, err := NewSourceContext(r.tsParser, project, path)
+		if err != nil {
+			return err
+		}
+		defer source.Close()
+		scope.merge(r.newCSharpScope(source, true))
+
+		return nil
+	})
+	if err != nil {
+		log.Printf("failed to read the global usings of %s: %v\n", project, err)
+	}
+	r.globalUsings[project] = scope
+
+	return scope
+}
+
+// projectOf returns the directory of the project of a directory, or an empty string when it is not part of a project
+func (r *ReachabilityCSharp) projectOf(dir string, fileDir string) string {
+	if project, ok := r.projects[fileDir]; ok {
+		return project
+	}
+
+	project := ""
+	if matches, _ := filepath.Glob(filepath.Join(fileDir, "*.csproj")); len(matches) > 0 {
+		project = fileDir
+	} else if parent := filepath.Dir(fileDir); fileDir != filepath.Clean(dir) && parent != fileDir {
+		project = r.projectOf(dir, parent)
+	}
+	r.projects[fileDir] = project
+
+	return project
+}
+
+var _ Detector = &ReachabilityCSharp{}
@@ -0,0 +1,142 @@
+package codefile
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewCSharpReachableDetector(t *testing.T) {
+	t.Parallel()
+	detector, err := NewCSharpReachableDetector()
+	require.NoError(t, err)
+	defer detector.Close()
+
+	assert.NotNil(t, detector)
+}
+
+func Test_DetectCSharp(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		path     string
+		symbol   models.Symbols
+		expected []string
+	}{
+		{
+			name:     "object creation of a type of a global using",
+			path:     "testdata/csharp/App/Program.cs",
+			symbol:   models.Symbols{Type: "class", Value: "Newtonsoft.Json", Name: "JsonSerializerSettings"},
+			expected: []string{"JsonSerializerSettings"},
+		},
+		{
+			name:     "object creation through a global alias",
+			path:     "testdata/csharp/App/Program.cs",
+			symbol:   models.Symbols{Type: "class", Value: "YamlDotNet.Serialization", Name: "DeserializerBuilder"},
+			expected: []string{"Yaml.DeserializerBuilder"},
+		},
+		{
+			name:     "static and statically imported invocations",
+			path:     "testdata/csharp/App/Program.cs",
+			symbol:   models.Symbols{Type: "method", Value: "Newtonsoft.Json.JsonConvert", Name: "DeserializeObject"},
+			expected: []string{"JsonConvert.DeserializeObject<List<string>>", "DeserializeObject"},
+		},
+		{
+			name:     "instance invocation in a file using the class",
+			path:     "testdata/csharp/App/Program.cs",
+			symbol:   models.Symbols{Type: "method", Value: "YamlDotNet.Serialization.DeserializerBuilder", Name: "Build"},
+			expected: []string{"Build"},
+		},
+		{
+			name:     "type reference",
+			path:     "testdata/csharp/App/Program.cs",
+			symbol:   models.Symbols{Type: "type_reference", Value: "Newtonsoft.Json", Name: "JsonConvert"},
+			expected: []string{"JsonConvert"},
+		},
+		{
+			name:     "type reference of a namespace which is not imported",
+			path:     "testdata/csharp/App/Program.cs",
+			symbol:   models.Symbols{Type: "type_reference", Value: "Newtonsoft.Json.Linq", Name: "JToken"},
+			expected: nil,
+		},
+		{
+			name:     "type reference of a type declared by the file",
+			path:     "testdata/csharp/App/Program.cs",
+			symbol:   models.Symbols{Type: "type_reference", Value: "Example.App", Name: "Config"},
+			expected: []string{"Config"},
+		},
+		{
+			name:     "type shadowed by a type declared by the file",
+			path:     "testdata/csharp/App/Program.cs",
+			symbol:   models.Symbols{Type: "type_reference", Value: "Newtonsoft.Json", Name: "Config"},
+			expected: nil,
+		},
+		{
+			name:     "file out of the project declaring the global usings",
+			path:     "testdata/csharp/Standalone.cs",
+			symbol:   models.Symbols{Type: "method", Value: "Newtonsoft.Json.JsonConvert", Name: "DeserializeObject"},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// A detector is not safe for concurrent use
+			detector, err := NewCSharpReachableDetector()
+			require.NoError(t, err)
+			defer detector.Close()
+
+			advisoriesToCheck := []models.AdvisoryToCheck{{Purl: "pkg:nuget/Example@1.0.0", AdvisoryID: "GHSA-csharp", Symbols: []models.Symbols{tc.symbol}}}
+			detectionResults := models.DetectionResults{}
+			err = detector.Detect("testdata/csharp", tc.path, detectionResults, advisoriesToCheck)
+			require.NoError(t, err)
+
+			var found []string
+			for _, location := range detectionResults["pkg:nuget/Example@1.0.0"]["GHSA-csharp"] {
+				found = append(found, location.Symbol)
+			}
+			assert.Equal(t, tc.expected, found)
+		})
+	}
+}
+
+func Test_DetectCSharp_Location(t *testing.T) {
+	t.Parallel()
+	detector, err := NewCSharpReachableDetector()
+	require.NoError(t, err)
+	defer detector.Close()
+
+	advisoriesToCheck := []models.AdvisoryToCheck{
+		{
+			Purl:       "pkg:nuget/Newtonsoft.Json@12.0.1",
+			AdvisoryID: "GHSA-5crp-9r3c-p9vr",
+			Symbols:    []models.Symbols{{Type: "class", Value: "Newtonsoft.Json", Name: "JsonSerializerSettings"}},
+		},
+	}
+	detectionResults := models.DetectionResults{}
+	err = detector.Detect("testdata/csharp", "testdata/csharp/App/Program.cs", detectionResults, advisoriesToCheck)
+	require.NoError(t, err)
+
+	assert.Equal(t, models.DetectionResults{
+		"pkg:nuget/Newtonsoft.Json@12.0.1": {
+			"GHSA-5crp-9r3c-p9vr": {
+				{
+					Symbol: "JsonSerializerSettings",
+					PackageLocation: models.PackageLocation{
+						Filename:    "testdata/csharp/App/Program.cs",
+						LineStart:   12,
+						LineEnd:     12,
+						ColumnStart: 32,
+						ColumnEnd:   54,
+					},
+				},
+			},
+		},
+	}, detectionResults)
+}
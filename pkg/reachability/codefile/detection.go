@@ -0,0 +1,57 @@
+package codefile
+
+import (
+	"bytes"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/utility/converter"
+	"github.com/DataDog/datadog-sbom-generator/internal/utility/fileposition"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// addDetection records that a symbol of an advisory is reachable between the given positions of a file
+func addDetection(detectionResults models.DetectionResults, advisoryToCheck models.AdvisoryToCheck, dir string, path string, start treesitter.Point, end treesitter.Point, symbol string) error {
+	packageLocation := models.PackageLocation{
+		Filename: fileposition.ToRelativePath(dir, path),
+	}
+
+	var err error
+	packageLocation.LineStart, err = converter.SafeUIntToInt(start.Row + 1)
+	if err != nil {
+		return err
+	}
+	packageLocation.LineEnd, err = converter.SafeUIntToInt(end.Row + 1)
+	if err != nil {
+		return err
+	}
+	packageLocation.ColumnStart, err = converter.SafeUIntToInt(start.Column + 1)
+	if err != nil {
+		return err
+	}
+	packageLocation.ColumnEnd, err = converter.SafeUIntToInt(end.Column + 1)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := detectionResults[advisoryToCheck.Purl]; !ok {
+		detectionResults[advisoryToCheck.Purl] = make(map[string]models.ReachableSymbolLocations)
+	}
+
+	detectionResults[advisoryToCheck.Purl][advisoryToCheck.AdvisoryID] = append(
+		detectionResults[advisoryToCheck.Purl][advisoryToCheck.AdvisoryID],
+		models.ReachableSymbolLocation{
+			Symbol:          symbol,
+			PackageLocation: packageLocation,
+		})
+
+	return nil
+}
+
+// offsetPoint converts a byte offset of a file to a tree-sitter point
+func offsetPoint(content []byte, offset int) treesitter.Point {
+	row := bytes.Count(content[:offset], []byte("\n"))
+	lineStart := bytes.LastIndexByte(content[:offset], '\n') + 1
+
+	return treesitter.Point{Row: uint(row), Column: uint(offset - lineStart)} //nolint:gosec // offsets are positive
+}
@@ -0,0 +1,318 @@
+package codefile
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// ReachabilityGo detects the usages of the vulnerable functions and methods of Go modules in Go files.
+// The Value of symbols is the import path of the package defining them (e.g. "golang.org/x/net/html") and their Name
+// is the name of the function (e.g. "Parse") or the name of the type and method (e.g. "Tokenizer.Next").
+// The files are type-checked package by package with go/types, and the import paths are mapped to the modules
+// required by the nearest go.mod file. The types of the methods are only known when the sources of their module are
+// in the vendor directory or in the module cache, and the standard library is read from GOROOT.
+type ReachabilityGo struct {
+	fset     *token.FileSet
+	modCache string
+
+	// modules caches the module of the directories analyzed so far, nil when they are not part of a module
+	modules map[string]*goModule
+	// files holds the files type-checked with their package but not detected yet, by path
+	files map[string]*goFile
+	// dependencies caches the packages of the vulnerable modules type-checked so far, by directory
+	dependencies map[string]*types.Package
+	// emptyPackages caches the packages imported without their sources, by import path
+	emptyPackages map[string]*types.Package
+}
+
+// goFile is a type-checked Go file
+type goFile struct {
+	content []byte
+	ast     *ast.File
+	info    *types.Info
+	module  *goModule
+}
+
+// goReference is a use of a function or method of a package, and the code spanning it
+type goReference struct {
+	from       token.Pos
+	to         token.Pos
+	symbolType string
+	pkgPath    string
+	name       string
+}
+
+// NewGoReachableDetector creates a detector for Go files, Close should be called once all the files are parsed
+func NewGoReachableDetector() (*ReachabilityGo, error) {
+	return &ReachabilityGo{
+		fset:          token.NewFileSet(),
+		modCache:      defaultGoModCache(),
+		modules:       make(map[string]*goModule),
+		files:         make(map[string]*goFile),
+		dependencies:  make(map[string]*types.Package),
+		emptyPackages: make(map[string]*types.Package),
+	}, nil
+}
+
+// Close releases the packages and files kept in cache.
+func (r *ReachabilityGo) Close() {
+	clear(r.modules)
+	clear(r.files)
+	clear(r.dependencies)
+	clear(r.emptyPackages)
+}
+
+func (r *ReachabilityGo) Detect(dir string, path string, detectionResults models.DetectionResults, advisoriesToCheck []models.AdvisoryToCheck) error {
+	vulnerableModules := make(map[string]bool)
+	for _, advisoryToCheck := range advisoriesToCheck {
+		if modulePath, version, ok := goModuleOfPurl(advisoryToCheck.Purl); ok {
+			vulnerableModules[modulePath+"@"+version] = true
+		}
+	}
+
+	file, err := r.checkedFile(dir, path, vulnerableModules)
+	if err != nil || file == nil || file.module == nil {
+		// The packages of files out of a module cannot be mapped to the modules found in go.mod files
+		return err
+	}
+
+	for _, reference := range goReferences(file) {
+		modulePath, ok := file.module.owner(reference.pkgPath)
+		if !ok {
+			continue
+		}
+
+		for _, advisoryToCheck := range advisoriesToCheck {
+			advisoryModulePath, version, ok := goModuleOfPurl(advisoryToCheck.Purl)
+			if !ok || advisoryModulePath != modulePath || file.module.versions[modulePath] != version {
+				continue
+			}
+
+			for _, s := range advisoryToCheck.Symbols {
+				if s.Type != reference.symbolType || s.Value != reference.pkgPath || s.Name != reference.name {
+					continue
+				}
+				if err := r.report(detectionResults, advisoryToCheck, dir, path, file, reference); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkedFile returns a file type-checked along with the other files of its directory,
+// which are kept until they are detected in turn
+func (r *ReachabilityGo) checkedFile(dir string, path string, vulnerableModules map[string]bool) (*goFile, error) {
+	if file, ok := r.files[path]; ok {
+		delete(r.files, path)
+
+		return file, nil
+	}
+
+	pkgDir := filepath.Dir(path)
+
+	entries, err := os.ReadDir(pkgDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", pkgDir, err)
+	}
+
+	mod := r.moduleOf(dir, pkgDir)
+
+	// Test files can declare an external package, so the files are type-checked per package name
+	filesPerPackage := make(map[string][]*ast.File)
+	contents := make(map[string][]byte)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".go" {
+			continue
+		}
+		filename := filepath.Join(pkgDir, entry.Name())
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+		}
+		file, err := parser.ParseFile(r.fset, filename, content, parser.SkipObjectResolution)
+		if file == nil {
+			log.Printf("failed to parse %s: %v\n", filename, err)
+			continue
+		}
+		contents[filename] = content
+		filesPerPackage[file.Name.Name] = append(filesPerPackage[file.Name.Name], file)
+	}
+
+	importPath := filepath.ToSlash(pkgDir)
+	if mod != nil && mod.path != "" {
+		if rel, err := filepath.Rel(mod.dir, pkgDir); err == nil {
+			importPath = strings.TrimSuffix(mod.path+"/"+filepath.ToSlash(rel), "/.")
+		}
+	}
+
+	for _, files := range filesPerPackage {
+		info := &types.Info{Uses: make(map[*ast.Ident]types.Object)}
+		config := types.Config{
+			Importer: goImporter(func(importPath string) (*types.Package, error) {
+				return r.importPackage(mod, importPath, vulnerableModules), nil
+			}),
+			Error:       func(error) {},
+			FakeImportC: true,
+		}
+		_, _ = config.Check(importPath, r.fset, files, info)
+
+		for _, file := range files {
+			filename := r.fset.File(file.Pos()).Name()
+			r.files[filename] = &goFile{content: contents[filename], ast: file, info: info, module: mod}
+		}
+	}
+
+	file := r.files[path]
+	delete(r.files, path)
+
+	return file, nil
+}
+
+// parseFiles parses the given files of a directory, skipping the ones which cannot be parsed
+func (r *ReachabilityGo) parseFiles(dir string, names []string) []*ast.File {
+	files := make([]*ast.File, 0, len(names))
+	for _, name := range names {
+		file, err := parser.ParseFile(r.fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if file == nil {
+			log.Printf("failed to parse %s: %v\n", filepath.Join(dir, name), err)
+			continue
+		}
+		files = append(files, file)
+	}
+
+	return files
+}
+
+// moduleOf returns the module of a directory, defined by the nearest go.mod file within the scanned directory
+func (r *ReachabilityGo) moduleOf(dir string, pkgDir string) *goModule {
+	if mod, ok := r.modules[pkgDir]; ok {
+		return mod
+	}
+
+	var mod *goModule
+	if _, err := os.Stat(filepath.Join(pkgDir, "go.mod")); err == nil {
+		mod, err = loadGoModule(pkgDir)
+		if err != nil {
+			log.Printf("skipping the Go module of %s: %v\n", pkgDir, err)
+		}
+	} else if parent := filepath.Dir(pkgDir); pkgDir != filepath.Clean(dir) && parent != pkgDir {
+		mod = r.moduleOf(dir, parent)
+	}
+	r.modules[pkgDir] = mod
+
+	return mod
+}
+
+// goReferences returns the uses of functions and methods of other packages in a file
+func goReferences(file *goFile) []goReference {
+	var references []goReference
+	selected := make(map[*ast.Ident]bool)
+
+	ast.Inspect(file.ast, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.SelectorExpr:
+			selected[node.Sel] = true
+			if symbolType, pkgPath, name, ok := resolveGoFunc(file.info, node.Sel); ok {
+				// Methods are reported without the expression of their receiver, which can span several calls
+				from := node.Pos()
+				if symbolType == "method" {
+					from = node.Sel.Pos()
+				}
+				references = append(references, goReference{from: from, to: node.End(), symbolType: symbolType, pkgPath: pkgPath, name: name})
+			} else if pkgName, ok := selectedGoPackage(file.info, node); ok {
+				// The declarations of packages imported without their sources are unknown, so functions are matched by name
+				references = append(references, goReference{from: node.Pos(), to: node.End(), symbolType: "function", pkgPath: pkgName.Imported().Path(), name: node.Sel.Name})
+			}
+		case *ast.Ident:
+			// Functions of dot imports are used without selector
+			if selected[node] {
+				return true
+			}
+			if symbolType, pkgPath, name, ok := resolveGoFunc(file.info, node); ok {
+				references = append(references, goReference{from: node.Pos(), to: node.End(), symbolType: symbolType, pkgPath: pkgPath, name: name})
+			}
+		}
+
+		return true
+	})
+
+	return references
+}
+
+// resolveGoFunc returns the type of symbol, the package and the name of the function or method an identifier refers to
+func resolveGoFunc(info *types.Info, ident *ast.Ident) (string, string, string, bool) {
+	fn, ok := info.Uses[ident].(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return "", "", "", false
+	}
+
+	signature, ok := fn.Type().(*types.Signature)
+	if !ok {
+		return "", "", "", false
+	}
+	if signature.Recv() == nil {
+		return "function", fn.Pkg().Path(), fn.Name(), true
+	}
+
+	receiver := signature.Recv().Type()
+	if pointer, ok := receiver.(*types.Pointer); ok {
+		receiver = pointer.Elem()
+	}
+	named, ok := types.Unalias(receiver).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return "", "", "", false
+	}
+	typeName := named.Origin().Obj()
+
+	return "method", typeName.Pkg().Path(), typeName.Name() + "." + fn.Name(), true
+}
+
+// selectedGoPackage returns the imported package a selector expression refers to, if any
+func selectedGoPackage(info *types.Info, selector *ast.SelectorExpr) (*types.PkgName, bool) {
+	ident, ok := selector.X.(*ast.Ident)
+	if !ok {
+		return nil, false
+	}
+	pkgName, ok := info.Uses[ident].(*types.PkgName)
+
+	return pkgName, ok
+}
+
+// report records the use of a symbol of an advisory by a reference of a file
+func (r *ReachabilityGo) report(detectionResults models.DetectionResults, advisoryToCheck models.AdvisoryToCheck, dir string, path string, file *goFile, reference goReference) error {
+	from := r.fset.Position(reference.from)
+	to := r.fset.Position(reference.to)
+
+	return addDetection(detectionResults, advisoryToCheck, dir, path, goPoint(from), goPoint(to), string(file.content[from.Offset:to.Offset]))
+}
+
+// goPoint converts a position of a Go file, whose line and column start at 1, to a tree-sitter point
+func goPoint(position token.Position) treesitter.Point {
+	return treesitter.Point{Row: uint(position.Line - 1), Column: uint(position.Column - 1)} //nolint:gosec // valid positions are positive
+}
+
+// goModuleOfPurl returns the path and version of the Go module of a PURL, "stdlib" being the standard library
+func goModuleOfPurl(purl string) (string, string, bool) {
+	parsed, err := packageurl.FromString(purl)
+	if err != nil || parsed.Type != packageurl.TypeGolang {
+		return "", "", false
+	}
+
+	return strings.TrimPrefix(parsed.Namespace+"/"+parsed.Name, "/"), parsed.Version, true
+}
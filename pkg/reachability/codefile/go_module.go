@@ -0,0 +1,262 @@
+package codefile
+
+import (
+	"errors"
+	"fmt"
+	"go/build"
+	"go/types"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/lockfile"
+)
+
+// goStdlib is the name given to the standard library by the Go extractor
+const goStdlib = "stdlib"
+
+// goModule is a Go module of the scanned code, along with the modules it requires
+type goModule struct {
+	path string
+	dir  string
+	// versions maps the paths of the modules found by the Go extractor in the go.mod file to their version
+	versions map[string]string
+	// packages caches the packages of the module type-checked so far, by import path
+	packages map[string]*types.Package
+}
+
+// loadGoModule reads the go.mod file of the given directory
+func loadGoModule(dir string) (*goModule, error) {
+	goModPath := filepath.Join(dir, "go.mod")
+
+	content, err := os.ReadFile(goModPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", goModPath, err)
+	}
+
+	depFile, err := lockfile.OpenLocalDepFile(goModPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", goModPath, err)
+	}
+	defer depFile.Close()
+
+	packages, err := lockfile.GoLockExtractor{}.Extract(depFile)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]string, len(packages))
+	for _, pkg := range packages {
+		versions[pkg.Name] = pkg.Version
+	}
+
+	return &goModule{
+		path:     modfile.ModulePath(content),
+		dir:      dir,
+		versions: versions,
+		packages: make(map[string]*types.Package),
+	}, nil
+}
+
+// isLocal returns whether a package belongs to the module itself
+func (m *goModule) isLocal(importPath string) bool {
+	return m.path != "" && (importPath == m.path || strings.HasPrefix(importPath, m.path+"/"))
+}
+
+// owner returns the path of the module providing a package: the standard library,
+// or the required module having the longest matching path
+func (m *goModule) owner(importPath string) (string, bool) {
+	if isGoStdlibPackage(importPath) {
+		_, ok := m.versions[goStdlib]
+
+		return goStdlib, ok
+	}
+
+	owner := ""
+	for modulePath := range m.versions {
+		if (importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/")) && len(modulePath) > len(owner) {
+			owner = modulePath
+		}
+	}
+
+	return owner, owner != ""
+}
+
+// sourceDir returns the directory holding the sources of a package of a required module,
+// looking for them in the vendor directory, then in the module cache
+func (m *goModule) sourceDir(importPath string, modulePath string, modCache string) string {
+	if modulePath == goStdlib {
+		return existingDir(filepath.Join(build.Default.GOROOT, "src", filepath.FromSlash(importPath)))
+	}
+
+	if dir := existingDir(filepath.Join(m.dir, "vendor", filepath.FromSlash(importPath))); dir != "" {
+		return dir
+	}
+
+	version := m.versions[modulePath]
+	escapedPath, errPath := module.EscapePath(modulePath)
+	escapedVersion, errVersion := module.EscapeVersion("v" + version)
+	if version == "" || modCache == "" || errPath != nil || errVersion != nil {
+		return ""
+	}
+
+	return existingDir(filepath.Join(modCache, escapedPath+"@"+escapedVersion, filepath.FromSlash(strings.TrimPrefix(importPath, modulePath))))
+}
+
+// isGoStdlibPackage returns whether an import path belongs to the standard library,
+// whose first element is the only one not to contain a dot
+func isGoStdlibPackage(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+
+	return !strings.Contains(first, ".")
+}
+
+// existingDir returns the given path if it is a directory, and an empty string otherwise
+func existingDir(dir string) string {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return ""
+	}
+
+	return dir
+}
+
+// defaultGoModCache returns the module cache of the environment, where the sources of the modules are downloaded
+func defaultGoModCache() string {
+	if modCache := os.Getenv("GOMODCACHE"); modCache != "" {
+		return modCache
+	}
+	if gopath := filepath.SplitList(build.Default.GOPATH); len(gopath) > 0 {
+		return filepath.Join(gopath[0], "pkg", "mod")
+	}
+
+	return ""
+}
+
+// goImporter imports the packages needed by the type-checker
+type goImporter func(importPath string) (*types.Package, error)
+
+func (i goImporter) Import(importPath string) (*types.Package, error) {
+	return i(importPath)
+}
+
+// importPackage returns the package imported by the code of a module. The packages of the module itself are
+// type-checked from their sources, and so are the packages of the vulnerable modules when their sources are available,
+// so that the types of their methods are known. Other packages are left empty.
+func (r *ReachabilityGo) importPackage(mod *goModule, importPath string, vulnerableModules map[string]bool) *types.Package {
+	if importPath == "unsafe" {
+		return types.Unsafe
+	}
+	if mod == nil {
+		return r.emptyPackage(importPath)
+	}
+
+	if mod.isLocal(importPath) {
+		if pkg, ok := mod.packages[importPath]; ok {
+			return pkg
+		}
+		// Import cycles are invalid, but should not prevent the analysis
+		mod.packages[importPath] = r.emptyPackage(importPath)
+
+		dir := filepath.Join(mod.dir, filepath.FromSlash(strings.TrimPrefix(importPath, mod.path)))
+		pkg := r.checkPackage(importPath, dir, goImporter(func(importPath string) (*types.Package, error) {
+			return r.importPackage(mod, importPath, vulnerableModules), nil
+		}))
+		mod.packages[importPath] = pkg
+
+		return pkg
+	}
+
+	modulePath, ok := mod.owner(importPath)
+	if !ok || !vulnerableModules[modulePath+"@"+mod.versions[modulePath]] {
+		return r.emptyPackage(importPath)
+	}
+
+	dir := mod.sourceDir(importPath, modulePath, r.modCache)
+	if dir == "" {
+		return r.emptyPackage(importPath)
+	}
+	if pkg, ok := r.dependencies[dir]; ok {
+		return pkg
+	}
+
+	// Only the declarations of the package itself are needed, so its own imports are left empty
+	pkg := r.checkPackage(importPath, dir, goImporter(func(importPath string) (*types.Package, error) {
+		if importPath == "unsafe" {
+			return types.Unsafe, nil
+		}
+
+		return r.emptyPackage(importPath), nil
+	}))
+	r.dependencies[dir] = pkg
+
+	return pkg
+}
+
+// checkPackage type-checks the files of a package built for the current platform, ignoring the type errors
+func (r *ReachabilityGo) checkPackage(importPath string, dir string, importer types.Importer) *types.Package {
+	buildPackage, err := build.Default.ImportDir(dir, 0)
+	if err != nil {
+		var noGoError *build.NoGoError
+		if !errors.As(err, &noGoError) {
+			log.Printf("failed to load Go package %s from %s: %v\n", importPath, dir, err)
+		}
+
+		return r.emptyPackage(importPath)
+	}
+
+	files := r.parseFiles(dir, append(buildPackage.GoFiles, buildPackage.CgoFiles...))
+
+	config := types.Config{
+		Importer:    importer,
+		Error:       func(error) {},
+		FakeImportC: true,
+	}
+	pkg, _ := config.Check(importPath, r.fset, files, nil)
+
+	return pkg
+}
+
+// emptyPackage returns a package without declarations, used when the sources of a package are not needed or not found
+func (r *ReachabilityGo) emptyPackage(importPath string) *types.Package {
+	if pkg, ok := r.emptyPackages[importPath]; ok {
+		return pkg
+	}
+
+	pkg := types.NewPackage(importPath, assumedGoPackageName(importPath))
+	pkg.MarkComplete()
+	r.emptyPackages[importPath] = pkg
+
+	return pkg
+}
+
+// assumedGoPackageName returns the name a package most likely declares, based on its import path:
+// the last element, without its major version (e.g. "/v2" or ".v3") nor a "go-" prefix
+func assumedGoPackageName(importPath string) string {
+	name := path.Base(importPath)
+	if isGoMajorVersion(name) && path.Dir(importPath) != "." {
+		name = path.Base(path.Dir(importPath))
+	}
+	name = strings.TrimPrefix(name, "go-")
+
+	if i := strings.IndexFunc(name, func(r rune) bool {
+		return r != '_' && !('a' <= r && r <= 'z') && !('A' <= r && r <= 'Z') && !('0' <= r && r <= '9')
+	}); i > 0 {
+		name = name[:i]
+	}
+
+	return name
+}
+
+// isGoMajorVersion returns whether an element of an import path is a major version suffix, such as "v2"
+func isGoMajorVersion(element string) bool {
+	if len(element) < 2 || element[0] != 'v' {
+		return false
+	}
+
+	return strings.Trim(element[1:], "0123456789") == ""
+}
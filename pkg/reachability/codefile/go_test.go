@@ -0,0 +1,194 @@
+package codefile
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewGoReachableDetector(t *testing.T) {
+	t.Parallel()
+	detector, err := NewGoReachableDetector()
+	require.NoError(t, err)
+	defer detector.Close()
+
+	assert.NotNil(t, detector)
+}
+
+func Test_DetectGo(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		dir      string
+		path     string
+		modCache string
+		purl     string
+		symbol   models.Symbols
+		expected []string
+	}{
+		{
+			name:     "function of an aliased import",
+			path:     "testdata/go/main.go",
+			purl:     "pkg:golang/example.com/vulnerable@1.2.3",
+			symbol:   models.Symbols{Type: "function", Value: "example.com/vulnerable/parser", Name: "Parse"},
+			expected: []string{"p.Parse"},
+		},
+		{
+			name:     "function of a package without sources",
+			path:     "testdata/go/main.go",
+			purl:     "pkg:golang/gopkg.in/yaml.v2@2.2.1",
+			symbol:   models.Symbols{Type: "function", Value: "gopkg.in/yaml.v2", Name: "Unmarshal"},
+			expected: []string{"yaml.Unmarshal"},
+		},
+		{
+			name:     "function of a dot import",
+			path:     "testdata/go/internal/decoding/decoding.go",
+			purl:     "pkg:golang/example.com/vulnerable@1.2.3",
+			symbol:   models.Symbols{Type: "function", Value: "example.com/vulnerable/parser", Name: "NewStrictDecoder"},
+			expected: []string{"NewStrictDecoder"},
+		},
+		{
+			name:     "method of a type returned by a local package",
+			path:     "testdata/go/main.go",
+			purl:     "pkg:golang/example.com/vulnerable@1.2.3",
+			symbol:   models.Symbols{Type: "method", Value: "example.com/vulnerable/parser", Name: "Decoder.Decode"},
+			expected: []string{"Decode"},
+		},
+		{
+			name:   "method of a module not in the module cache",
+			path:   "testdata/go/main.go",
+			purl:   "pkg:golang/example.com/vulnerable@1.2.3",
+			symbol: models.Symbols{Type: "method", Value: "example.com/vulnerable/parser", Name: "Decoder.Decode"},
+			// Without the sources of the module, the type of the receiver is unknown
+			modCache: "testdata/go/missing",
+			expected: nil,
+		},
+		{
+			name:     "method of the standard library",
+			path:     "testdata/go/main.go",
+			purl:     "pkg:golang/stdlib@1.22",
+			symbol:   models.Symbols{Type: "method", Value: "net/http", Name: "Request.ParseMultipartForm"},
+			expected: []string{"ParseMultipartForm"},
+		},
+		{
+			name:     "function of the standard library",
+			path:     "testdata/go/main.go",
+			purl:     "pkg:golang/stdlib@1.22",
+			symbol:   models.Symbols{Type: "function", Value: "net/http", Name: "HandleFunc"},
+			expected: []string{"http.HandleFunc"},
+		},
+		{
+			name:     "function not used",
+			path:     "testdata/go/main.go",
+			purl:     "pkg:golang/example.com/vulnerable@1.2.3",
+			symbol:   models.Symbols{Type: "function", Value: "example.com/vulnerable/parser", Name: "ParseString"},
+			expected: nil,
+		},
+		{
+			name:     "function of another version of the module",
+			path:     "testdata/go/main.go",
+			purl:     "pkg:golang/example.com/vulnerable@1.0.0",
+			symbol:   models.Symbols{Type: "function", Value: "example.com/vulnerable/parser", Name: "Parse"},
+			expected: nil,
+		},
+		{
+			name:     "function of a package out of the module",
+			path:     "testdata/go/main.go",
+			purl:     "pkg:golang/gopkg.in/yaml.v2@2.2.1",
+			symbol:   models.Symbols{Type: "function", Value: "example.com/vulnerable/parser", Name: "Parse"},
+			expected: nil,
+		},
+		{
+			name:     "file out of a module",
+			dir:      "testdata/go/nomodule",
+			path:     "testdata/go/nomodule/main.go",
+			purl:     "pkg:golang/example.com/vulnerable@1.2.3",
+			symbol:   models.Symbols{Type: "function", Value: "example.com/vulnerable/parser", Name: "Parse"},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// A detector is not safe for concurrent use
+			detector, err := NewGoReachableDetector()
+			require.NoError(t, err)
+			defer detector.Close()
+
+			detector.modCache = "testdata/go/modcache"
+			if tc.modCache != "" {
+				detector.modCache = tc.modCache
+			}
+			dir := "."
+			if tc.dir != "" {
+				dir = tc.dir
+			}
+
+			advisoriesToCheck := []models.AdvisoryToCheck{{Purl: tc.purl, AdvisoryID: "GO-2025-0001", Symbols: []models.Symbols{tc.symbol}}}
+			detectionResults := models.DetectionResults{}
+			err = detector.Detect(dir, tc.path, detectionResults, advisoriesToCheck)
+			require.NoError(t, err)
+
+			var found []string
+			for _, location := range detectionResults[tc.purl]["GO-2025-0001"] {
+				found = append(found, location.Symbol)
+			}
+			assert.Equal(t, tc.expected, found)
+		})
+	}
+}
+
+func Test_DetectGo_Location(t *testing.T) {
+	t.Parallel()
+	detector, err := NewGoReachableDetector()
+	require.NoError(t, err)
+	defer detector.Close()
+	detector.modCache = "testdata/go/modcache"
+
+	advisoriesToCheck := []models.AdvisoryToCheck{
+		{
+			Purl:       "pkg:golang/example.com/vulnerable@1.2.3",
+			AdvisoryID: "GO-2025-0001",
+			Symbols: []models.Symbols{
+				{Type: "function", Value: "example.com/vulnerable/parser", Name: "Parse"},
+				{Type: "method", Value: "example.com/vulnerable/parser", Name: "Decoder.Decode"},
+			},
+		},
+	}
+	detectionResults := models.DetectionResults{}
+	err = detector.Detect(".", "testdata/go/main.go", detectionResults, advisoriesToCheck)
+	require.NoError(t, err)
+
+	assert.Equal(t, models.DetectionResults{
+		"pkg:golang/example.com/vulnerable@1.2.3": {
+			"GO-2025-0001": {
+				{
+					Symbol: "p.Parse",
+					PackageLocation: models.PackageLocation{
+						Filename:    "testdata/go/main.go",
+						LineStart:   19,
+						LineEnd:     19,
+						ColumnStart: 19,
+						ColumnEnd:   26,
+					},
+				},
+				{
+					Symbol: "Decode",
+					PackageLocation: models.PackageLocation{
+						Filename:    "testdata/go/main.go",
+						LineStart:   27,
+						LineEnd:     27,
+						ColumnStart: 24,
+						ColumnEnd:   30,
+					},
+				},
+			},
+		},
+	}, detectionResults)
+}
@@ -4,10 +4,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
-	"github.com/DataDog/datadog-sbom-generator/internal/utility/converter"
-
-	"github.com/DataDog/datadog-sbom-generator/internal/utility/fileposition"
 	"github.com/DataDog/datadog-sbom-generator/pkg/models"
 
 	treesitter "github.com/tree-sitter/go-tree-sitter"
@@ -19,13 +17,55 @@ var tsQueryForJavaClass = `
 	type: (_) @class
 )`
 
+// Methods can be called without object when they are inherited or statically imported
+var tsQueryForJavaMethod = `
+(method_invocation
+	object: (_)? @object
+	name: (identifier) @name
+)`
+
+var tsQueryForJavaField = `
+(field_access
+	object: (_) @object
+	field: (identifier) @name
+)`
+
+var tsQueryForJavaTypeReference = `
+[
+	(type_identifier)
+	(scoped_type_identifier)
+] @type`
+
+var tsQueryForJavaImport = `
+(import_declaration
+	(scoped_identifier) @import
+)`
+
 var symbolTypeToTSQuery = map[string]string{
-	"class": tsQueryForJavaClass,
+	"class":          tsQueryForJavaClass,
+	"method":         tsQueryForJavaMethod,
+	"static_method":  tsQueryForJavaMethod,
+	"field":          tsQueryForJavaField,
+	"type_reference": tsQueryForJavaTypeReference,
+}
+
+// javaSymbolMatcher checks whether the captures of a query match a symbol,
+// and returns the first and last nodes of the code to report when they do
+type javaSymbolMatcher func(file *javaFile, captures map[string]*treesitter.Node, s models.Symbols) (*treesitter.Node, *treesitter.Node, bool)
+
+var symbolTypeToMatcher = map[string]javaSymbolMatcher{
+	"class":          matchJavaClass,
+	"method":         matchJavaMember(false),
+	"static_method":  matchJavaMember(true),
+	"field":          matchJavaMember(false),
+	"type_reference": matchJavaTypeReference,
 }
 
 type ReachabilityJava struct {
 	tsParser               *treesitter.Parser
 	tsQueriesPerSymbolType map[string]*treesitter.Query
+	// tsQueries holds the queries indexing the types, imports and declarations of files
+	tsQueries map[string]*treesitter.Query
 }
 
 // NewJavaReachableDetector creates a new JavaReachableDetector instance that once
@@ -42,18 +82,25 @@ func NewJavaReachableDetector() (*ReachabilityJava, error) {
 	}
 
 	// Create each once query and place them in a map for quick access during parsing.
-	tsQueriesPerSymbolType := make(map[string]*treesitter.Query, len(symbolTypeToTSQuery))
-	for symbolType, tsQuery := range symbolTypeToTSQuery {
-		query, err := treesitter.NewQuery(tsLanguage, tsQuery)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create tree-sitter query for %s: %w", symbolType, err)
-		}
-		tsQueriesPerSymbolType[symbolType] = query
+	tsQueriesPerSymbolType, err := newQueries(tsLanguage, symbolTypeToTSQuery)
+	if err != nil {
+		return nil, err
+	}
+	tsQueries, err := newQueries(tsLanguage, map[string]string{
+		"types":        tsQueryForJavaTypeReference,
+		"imports":      tsQueryForJavaImport,
+		"declarations": tsQueryForJavaDeclaration,
+	})
+	if err != nil {
+		closeQueries(tsQueriesPerSymbolType)
+
+		return nil, err
 	}
 
 	return &ReachabilityJava{
 		tsParser:               tsParser,
 		tsQueriesPerSymbolType: tsQueriesPerSymbolType,
+		tsQueries:              tsQueries,
 	}, nil
 }
 
@@ -61,22 +108,22 @@ func NewJavaReachableDetector() (*ReachabilityJava, error) {
 // This should only be called once you're finished parsing all Java files.
 func (r *ReachabilityJava) Close() {
 	r.tsParser.Close()
-	for _, query := range r.tsQueriesPerSymbolType {
-		query.Close()
-	}
+	closeQueries(r.tsQueriesPerSymbolType)
+	closeQueries(r.tsQueries)
 }
 
 func (r *ReachabilityJava) Detect(dir string, path string, detectionResults models.DetectionResults, advisoriesToCheck []models.AdvisoryToCheck) error {
-	fileContent, err := readFileContent(path)
+	source, err := NewSourceContext(r.tsParser, dir, path)
 	if err != nil {
 		return err
 	}
+	defer source.Close()
 
-	tree := r.tsParser.Parse(fileContent, nil)
-	defer tree.Close()
-
-	queryCursor := treesitter.NewQueryCursor()
-	defer queryCursor.Close()
+	file := &javaFile{
+		SourceContext: source,
+		detector:      r,
+		scope:         newJavaScope(source, r.tsQueries["declarations"]),
+	}
 
 	// Loop over all an advisories symbols; making a TS query for each instance.
 	for _, advisoryToCheck := range advisoriesToCheck {
@@ -86,62 +133,19 @@ func (r *ReachabilityJava) Detect(dir string, path string, detectionResults mode
 				log.Printf("No query found for symbol type %s\n", s.Type)
 				continue
 			}
+			matcher := symbolTypeToMatcher[s.Type]
+
+			// Run the TS query against the TS tree, and filter out the matches that are not relevant.
+			var hits [][2]*treesitter.Node
+			source.Matches(query, func(captures map[string]*treesitter.Node) {
+				if from, to, ok := matcher(file, captures, s); ok {
+					hits = append(hits, [2]*treesitter.Node{from, to})
+				}
+			})
 
-			// Run the TS query against the TS tree
-			captures := queryCursor.Captures(query, tree.RootNode(), fileContent)
-
-			// Iterate over all the matches from TS; we need to filter out the ones that are not relevant.
-			for match, index := captures.Next(); match != nil; match, index = captures.Next() {
-				// The class query can have multiple matches, but only one capture (@class).
-				matchedText := match.Captures[index].Node.Utf8Text(fileContent)
-
-				/*
-					Our TS query can return class creations in two formats that we need to check here:
-					1. <name>
-					2. <package>.<name>
-					Example:
-					1. CodebaseAwareObjectInputStream
-					2. org.springframework.remoting.rmi.CodebaseAwareObjectInputStream
-					Note: This logic is specific to class type and will need to be updated in the future when we build out further symbols.
-				*/
-				if matchedText == s.Name || matchedText == fmt.Sprintf("%s.%s", s.Value, s.Name) {
-					startPosition := match.Captures[index].Node.StartPosition()
-					endPosition := match.Captures[index].Node.EndPosition()
-
-					if _, ok := detectionResults[advisoryToCheck.Purl]; !ok {
-						detectionResults[advisoryToCheck.Purl] = make(map[string]models.ReachableSymbolLocations)
-					}
-
-					if _, ok := detectionResults[advisoryToCheck.Purl][advisoryToCheck.AdvisoryID]; !ok {
-						detectionResults[advisoryToCheck.Purl][advisoryToCheck.AdvisoryID] = make(models.ReachableSymbolLocations, 0)
-					}
-
-					packageLocation := models.PackageLocation{
-						Filename: fileposition.ToRelativePath(dir, path),
-					}
-					packageLocation.LineStart, err = converter.SafeUIntToInt(startPosition.Row + 1)
-					if err != nil {
-						return err
-					}
-					packageLocation.LineEnd, err = converter.SafeUIntToInt(endPosition.Row + 1)
-					if err != nil {
-						return err
-					}
-					packageLocation.ColumnStart, err = converter.SafeUIntToInt(startPosition.Column + 1)
-					if err != nil {
-						return err
-					}
-					packageLocation.ColumnEnd, err = converter.SafeUIntToInt(endPosition.Column + 1)
-					if err != nil {
-						return err
-					}
-
-					detectionResults[advisoryToCheck.Purl][advisoryToCheck.AdvisoryID] = append(
-						detectionResults[advisoryToCheck.Purl][advisoryToCheck.AdvisoryID],
-						models.ReachableSymbolLocation{
-							Symbol:          matchedText,
-							PackageLocation: packageLocation,
-						})
+			for _, hit := range hits {
+				if err := source.Report(detectionResults, advisoryToCheck, hit[0], hit[1]); err != nil {
+					return err
 				}
 			}
 		}
@@ -150,6 +154,97 @@ func (r *ReachabilityJava) Detect(dir string, path string, detectionResults mode
 	return nil
 }
 
+// javaFile is a parsed Java file, which lazily indexes the types it references
+type javaFile struct {
+	*SourceContext
+
+	detector *ReachabilityJava
+	scope    *javaScope
+
+	references map[string]bool
+}
+
+// refersToClass returns whether the text refers to the given class, either by its fully qualified name
+// or by a simple name resolving to its package
+func (f *javaFile) refersToClass(text string, pkg string, name string) bool {
+	return text == pkg+"."+name || (text == name && f.scope.resolvesTo(name, pkg))
+}
+
+// referencesClass returns whether the file imports or uses the given class
+func (f *javaFile) referencesClass(qualifiedName string) bool {
+	if f.references == nil {
+		f.references = make(map[string]bool)
+		for _, query := range []*treesitter.Query{f.detector.tsQueries["types"], f.detector.tsQueries["imports"]} {
+			for _, node := range f.Captures(query) {
+				f.references[f.Text(node)] = true
+			}
+		}
+	}
+
+	pkg, simpleName := splitQualifiedName(qualifiedName)
+
+	return f.references[qualifiedName] || (f.references[simpleName] && f.scope.resolvesTo(simpleName, pkg))
+}
+
+// splitQualifiedName splits a fully qualified class name into its package and its simple name
+func splitQualifiedName(qualifiedName string) (string, string) {
+	index := strings.LastIndex(qualifiedName, ".")
+	if index < 0 {
+		return "", qualifiedName
+	}
+
+	return qualifiedName[:index], qualifiedName[index+1:]
+}
+
+func matchJavaClass(file *javaFile, captures map[string]*treesitter.Node, s models.Symbols) (*treesitter.Node, *treesitter.Node, bool) {
+	/*
+		Our TS query can return class creations in two formats that we need to check here:
+		1. <name>
+		2. <package>.<name>
+		Example:
+		1. CodebaseAwareObjectInputStream
+		2. org.springframework.remoting.rmi.CodebaseAwareObjectInputStream
+	*/
+	node := captures["class"]
+
+	return node, node, file.refersToClass(file.Text(node), s.Value, s.Name)
+}
+
+// matchJavaMember matches the methods and fields of a class. Static members must be qualified by their class
+// or statically imported, while instance members are matched by name in the files referencing their class,
+// as their objects are not typed.
+func matchJavaMember(static bool) javaSymbolMatcher {
+	return func(file *javaFile, captures map[string]*treesitter.Node, s models.Symbols) (*treesitter.Node, *treesitter.Node, bool) {
+		name := captures["name"]
+		if file.Text(name) != s.Name {
+			return nil, nil, false
+		}
+
+		pkg, class := splitQualifiedName(s.Value)
+		object := captures["object"]
+		if object != nil && file.refersToClass(file.Text(object), pkg, class) {
+			return object, name, true
+		}
+
+		if static {
+			return name, name, object == nil && file.scope.importsStaticMember(s.Value, s.Name)
+		}
+
+		return name, name, file.referencesClass(s.Value)
+	}
+}
+
+func matchJavaTypeReference(file *javaFile, captures map[string]*treesitter.Node, s models.Symbols) (*treesitter.Node, *treesitter.Node, bool) {
+	node := captures["type"]
+
+	// Only the outermost identifier of a qualified type is reported, e.g. org.example.Greeter rather than Greeter
+	if parent := node.Parent(); parent != nil && parent.Kind() == "scoped_type_identifier" {
+		return nil, nil, false
+	}
+
+	return node, node, file.refersToClass(file.Text(node), s.Value, s.Name)
+}
+
 // readFileContent is a thin wrapper over os.ReadFile that reads the content of a file
 // and returns it as a byte slice while logging any errors.
 // TODO(daniel.strong): find a better place for this function
@@ -162,3 +257,5 @@ func readFileContent(filePath string) ([]byte, error) {
 
 	return data, nil
 }
+
+var _ Detector = &ReachabilityJava{}
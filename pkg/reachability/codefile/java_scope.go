@@ -0,0 +1,113 @@
+package codefile
+
+import (
+	"slices"
+
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+var tsQueryForJavaDeclaration = `
+[
+	(class_declaration name: (identifier) @name)
+	(interface_declaration name: (identifier) @name)
+	(enum_declaration name: (identifier) @name)
+	(record_declaration name: (identifier) @name)
+	(annotation_type_declaration name: (identifier) @name)
+]`
+
+// javaScope resolves the simple names used by a Java file to the classes they can refer to, from the package
+// of the file, its imports and the classes it declares. As the classes of the other files are not known,
+// a name can resolve to several packages when it is neither declared nor explicitly imported.
+type javaScope struct {
+	pkg string
+	// singleTypeImports maps the simple names of the classes imported with `import a.b.C;` to their qualified names
+	singleTypeImports map[string]string
+	// onDemandImports lists the packages imported with `import a.b.*;`
+	onDemandImports []string
+	// staticImports maps the members imported with `import static a.b.C.m;` to the qualified names of their classes
+	staticImports map[string]string
+	// staticOnDemandImports lists the classes whose members are imported with `import static a.b.C.*;`
+	staticOnDemandImports []string
+	// declared lists the classes declared in the file, which shadow the imported ones
+	declared map[string]bool
+}
+
+func newJavaScope(source *SourceContext, declarationQuery *treesitter.Query) *javaScope {
+	scope := &javaScope{
+		singleTypeImports: make(map[string]string),
+		staticImports:     make(map[string]string),
+		declared:          make(map[string]bool),
+	}
+
+	root := source.Root()
+	for i := range root.NamedChildCount() {
+		node := root.NamedChild(i)
+		switch node.Kind() {
+		case "package_declaration":
+			for j := range node.NamedChildCount() {
+				if child := node.NamedChild(j); child.Kind() == "scoped_identifier" || child.Kind() == "identifier" {
+					scope.pkg = source.Text(child)
+				}
+			}
+		case "import_declaration":
+			scope.addImport(source, node)
+		}
+	}
+
+	for _, node := range source.Captures(declarationQuery) {
+		scope.declared[source.Text(node)] = true
+	}
+
+	return scope
+}
+
+func (s *javaScope) addImport(source *SourceContext, node *treesitter.Node) {
+	var name string
+	var static, onDemand bool
+	for i := range node.ChildCount() {
+		child := node.Child(i)
+		switch child.Kind() {
+		case "static":
+			static = true
+		case "asterisk":
+			onDemand = true
+		case "scoped_identifier", "identifier":
+			name = source.Text(child)
+		}
+	}
+
+	switch {
+	case static && onDemand:
+		s.staticOnDemandImports = append(s.staticOnDemandImports, name)
+	case static:
+		class, member := splitQualifiedName(name)
+		s.staticImports[member] = class
+	case onDemand:
+		s.onDemandImports = append(s.onDemandImports, name)
+	default:
+		_, simpleName := splitQualifiedName(name)
+		s.singleTypeImports[simpleName] = name
+	}
+}
+
+// resolvesTo returns whether the simple name of a class, as used in the file, can refer to the class of the given package
+func (s *javaScope) resolvesTo(simpleName string, pkg string) bool {
+	if s.declared[simpleName] {
+		return pkg == s.pkg
+	}
+	if qualifiedName, ok := s.singleTypeImports[simpleName]; ok {
+		return qualifiedName == pkg+"."+simpleName
+	}
+
+	// Classes of the same package and of java.lang are implicitly imported
+	return pkg == s.pkg || pkg == "java.lang" || slices.Contains(s.onDemandImports, pkg)
+}
+
+// importsStaticMember returns whether a member of the given class can be used without being qualified by its class
+func (s *javaScope) importsStaticMember(qualifiedClassName string, member string) bool {
+	if class, ok := s.staticImports[member]; ok {
+		return class == qualifiedClassName
+	}
+
+	return slices.Contains(s.staticOnDemandImports, qualifiedClassName)
+}
@@ -107,3 +107,130 @@ func Test_Detect_ClassSymbolsFound(t *testing.T) {
 	assert.Equal(t, 29, reachableSymbols[0].ColumnStart)
 	assert.Equal(t, 48, reachableSymbols[0].ColumnEnd)
 }
+
+func Test_Detect_SymbolTypes(t *testing.T) {
+	t.Parallel()
+	detector, err := NewJavaReachableDetector()
+	require.NoError(t, err)
+	defer detector.Close()
+
+	advisoriesToCheck := []models.AdvisoryToCheck{
+		{
+			Purl:       "pkg:maven/com.fasterxml.jackson.core/jackson-databind@2.9.0",
+			AdvisoryID: "GHSA-method",
+			Symbols: []models.Symbols{
+				{Type: "method", Value: "com.fasterxml.jackson.databind.ObjectMapper", Name: "readValue"},
+				// The class is not referenced by the file, so its methods cannot be called
+				{Type: "method", Value: "com.fasterxml.jackson.databind.ObjectReader", Name: "readValue"},
+			},
+		},
+		{
+			Purl:       "pkg:maven/org.example/example@1.0.0",
+			AdvisoryID: "GHSA-members",
+			Symbols: []models.Symbols{
+				{Type: "static_method", Value: "org.example.Settings", Name: "load"},
+				{Type: "static_method", Value: "org.example.Other", Name: "load"},
+				{Type: "field", Value: "org.example.Settings", Name: "DEFAULT_LIMIT"},
+				{Type: "method", Value: "org.example.VulnerableBase", Name: "inherited"},
+			},
+		},
+		{
+			Purl:       "pkg:maven/org.example/types@1.0.0",
+			AdvisoryID: "GHSA-types",
+			Symbols: []models.Symbols{
+				{Type: "type_reference", Value: "org.example", Name: "VulnerableBase"},
+				{Type: "type_reference", Value: "org.example", Name: "Cache"},
+				{Type: "type_reference", Value: "org.other", Name: "Cache"},
+			},
+		},
+	}
+
+	detectionResults := models.DetectionResults{}
+	err = detector.Detect("testdata/symbol-types", "testdata/symbol-types/App.java", detectionResults, advisoriesToCheck)
+	require.NoError(t, err)
+
+	location := func(symbol string, line int, columnStart int, columnEnd int) models.ReachableSymbolLocation {
+		return models.ReachableSymbolLocation{
+			Symbol: symbol,
+			PackageLocation: models.PackageLocation{
+				Filename:    "testdata/symbol-types/App.java",
+				LineStart:   line,
+				LineEnd:     line,
+				ColumnStart: columnStart,
+				ColumnEnd:   columnEnd,
+			},
+		}
+	}
+
+	assert.Equal(t, models.DetectionResults{
+		"pkg:maven/com.fasterxml.jackson.core/jackson-databind@2.9.0": {
+			"GHSA-method": {location("readValue", 15, 19, 28)},
+		},
+		"pkg:maven/org.example/example@1.0.0": {
+			"GHSA-members": {
+				location("Settings.load", 13, 25, 38),
+				location("Settings.DEFAULT_LIMIT", 14, 17, 39),
+				location("inherited", 12, 5, 14),
+			},
+		},
+		"pkg:maven/org.example/types@1.0.0": {
+			"GHSA-types": {
+				location("VulnerableBase", 7, 26, 40),
+				location("org.example.Cache", 9, 11, 28),
+			},
+		},
+	}, detectionResults)
+}
+
+func Test_Detect_ImportResolution(t *testing.T) {
+	t.Parallel()
+
+	greeter := []models.Symbols{
+		{Type: "class", Value: "org.example", Name: "Greeter"},
+		{Type: "method", Value: "org.example.Greeter", Name: "sayHello"},
+	}
+
+	tests := []struct {
+		path     string
+		symbols  []models.Symbols
+		expected []string
+	}{
+		{path: "testdata/CVE-2025-1234/explicit-import/class.java", symbols: greeter, expected: []string{"Greeter", "sayHello"}},
+		{path: "testdata/CVE-2025-1234/wildcard-import/class.java", symbols: greeter, expected: []string{"Greeter", "sayHello"}},
+		{path: "testdata/CVE-2025-1234/same-package/class.java", symbols: greeter, expected: []string{"Greeter", "sayHello"}},
+		{path: "testdata/CVE-2025-1234/local-class/class.java", symbols: greeter, expected: nil},
+		{path: "testdata/CVE-2025-1234/other-import/class.java", symbols: greeter, expected: nil},
+		{
+			path: "testdata/imports/App.java",
+			symbols: []models.Symbols{
+				{Type: "class", Value: "java.lang", Name: "ProcessBuilder"},
+				{Type: "class", Value: "org.example", Name: "ProcessBuilder"},
+				{Type: "static_method", Value: "org.example.Settings", Name: "load"},
+				{Type: "static_method", Value: "org.example.Defaults", Name: "reset"},
+				{Type: "static_method", Value: "org.example.Other", Name: "load"},
+			},
+			expected: []string{"ProcessBuilder", "load", "reset"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			// The detector holds a single parser, it cannot be shared by parallel tests
+			detector, err := NewJavaReachableDetector()
+			require.NoError(t, err)
+			defer detector.Close()
+
+			detectionResults := models.DetectionResults{}
+			advisoriesToCheck := []models.AdvisoryToCheck{{Purl: "pkg:maven/org.example/example@1.0.0", AdvisoryID: "CVE-2025-1234", Symbols: tt.symbols}}
+			err = detector.Detect(".", tt.path, detectionResults, advisoriesToCheck)
+			require.NoError(t, err)
+
+			var symbols []string
+			for _, location := range detectionResults["pkg:maven/org.example/example@1.0.0"]["CVE-2025-1234"] {
+				symbols = append(symbols, location.Symbol)
+			}
+			assert.Equal(t, tt.expected, symbols)
+		})
+	}
+}
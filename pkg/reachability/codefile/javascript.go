@@ -0,0 +1,478 @@
+package codefile
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+	tree_sitter_javascript "github.com/tree-sitter/tree-sitter-javascript/bindings/go"
+	tree_sitter_typescript "github.com/tree-sitter/tree-sitter-typescript/bindings/go"
+)
+
+var tsQueryForJavaScriptModule = `
+[
+	(import_statement)
+	(export_statement)
+	(variable_declarator)
+] @statement`
+
+var tsQueryForJavaScriptCall = `
+[
+	(call_expression function: (_) @function)
+	(new_expression constructor: (_) @function)
+]`
+
+// javaScriptExtensions lists the extensions tried, in order, to find the file of a local module
+var javaScriptExtensions = []string{".js", ".mjs", ".cjs", ".jsx", ".ts", ".tsx", ".mts", ".cts"}
+
+// typeScriptGrammars maps the extensions of TypeScript files to the grammar they are parsed with,
+// the other files being parsed with the JavaScript grammar
+var typeScriptGrammars = map[string]string{".ts": "typescript", ".mts": "typescript", ".cts": "typescript", ".tsx": "tsx"}
+
+// maxReExportDepth bounds the chains of re-exports followed to resolve an import
+const maxReExportDepth = 10
+
+// ReachabilityJavaScript detects the calls of the vulnerable functions of npm packages in JavaScript and TypeScript
+// files. The Value of symbols is the package, or a file of a package (e.g. "lodash/fp"), and their Name is the path
+// of the export (e.g. "merge", or "default.merge" for a property of the default export).
+// TypeScript and TSX files are parsed with their own grammars, which extend the one of JavaScript.
+type ReachabilityJavaScript struct {
+	// grammars holds the parser and queries of the javascript, typescript and tsx grammars
+	grammars map[string]*jsGrammar
+
+	// modules caches the imports and exports of the files parsed so far, by path
+	modules map[string]*jsModule
+}
+
+// jsGrammar is the parser of a dialect of JavaScript, along with the queries compiled for its language
+type jsGrammar struct {
+	tsParser  *treesitter.Parser
+	tsQueries map[string]*treesitter.Query
+}
+
+// NewJavaScriptReachableDetector creates a detector for JavaScript and TypeScript files,
+// Close should be called once all the files are parsed
+func NewJavaScriptReachableDetector() (*ReachabilityJavaScript, error) {
+	r := &ReachabilityJavaScript{
+		grammars: make(map[string]*jsGrammar),
+		modules:  make(map[string]*jsModule),
+	}
+
+	for name, language := range map[string]unsafe.Pointer{
+		"javascript": tree_sitter_javascript.Language(),
+		"typescript": tree_sitter_typescript.LanguageTypescript(),
+		"tsx":        tree_sitter_typescript.LanguageTSX(),
+	} {
+		grammar, err := newJSGrammar(name, treesitter.NewLanguage(language))
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		r.grammars[name] = grammar
+	}
+
+	return r, nil
+}
+
+func newJSGrammar(name string, tsLanguage *treesitter.Language) (*jsGrammar, error) {
+	tsParser := treesitter.NewParser()
+
+	err := tsParser.SetLanguage(tsLanguage)
+	if err != nil {
+		tsParser.Close()
+		return nil, fmt.Errorf("failed to set tree-sitter %s language on parser: %w", name, err)
+	}
+
+	tsQueries, err := newQueries(tsLanguage, map[string]string{
+		"module": tsQueryForJavaScriptModule,
+		"calls":  tsQueryForJavaScriptCall,
+	})
+	if err != nil {
+		tsParser.Close()
+		return nil, err
+	}
+
+	return &jsGrammar{tsParser: tsParser, tsQueries: tsQueries}, nil
+}
+
+// Close closes all hanging tree-sitter related resources.
+func (r *ReachabilityJavaScript) Close() {
+	for _, grammar := range r.grammars {
+		grammar.tsParser.Close()
+		closeQueries(grammar.tsQueries)
+	}
+}
+
+// grammarOf returns the grammar a file is parsed with, depending on its extension
+func (r *ReachabilityJavaScript) grammarOf(path string) *jsGrammar {
+	if name, ok := typeScriptGrammars[filepath.Ext(path)]; ok {
+		return r.grammars[name]
+	}
+
+	return r.grammars["javascript"]
+}
+
+func (r *ReachabilityJavaScript) Detect(dir string, path string, detectionResults models.DetectionResults, advisoriesToCheck []models.AdvisoryToCheck) error {
+	source, err := NewSourceContext(r.grammarOf(path).tsParser, dir, path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	module := r.moduleOf(source)
+	if len(module.bindings) == 0 {
+		// Nothing can refer to the functions of packages which are neither imported nor required
+		return nil
+	}
+
+	type call struct {
+		function *treesitter.Node
+		binding  jsBinding
+	}
+	var calls []call
+	source.Matches(r.grammarOf(source.Path).tsQueries["calls"], func(captures map[string]*treesitter.Node) {
+		function := captures["function"]
+		if binding, ok := r.resolve(source, module, function); ok && !binding.local {
+			calls = append(calls, call{function: function, binding: binding})
+		}
+	})
+
+	for _, advisoryToCheck := range advisoriesToCheck {
+		for _, s := range advisoryToCheck.Symbols {
+			if s.Type != "function" {
+				log.Printf("No query found for symbol type %s\n", s.Type)
+				continue
+			}
+
+			for _, c := range calls {
+				if c.binding.module != s.Value || c.binding.path != s.Name {
+					continue
+				}
+				if err := source.Report(detectionResults, advisoryToCheck, c.function, c.function); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// jsBinding is what a name refers to: a package, a local module, or one of their exports
+type jsBinding struct {
+	// module is the name of a package, or the path of the file of a local module
+	module string
+	local  bool
+	// path is the export the name refers to, e.g. "merge" or "default.merge", or empty for the module itself
+	path string
+}
+
+func (b jsBinding) member(name string) jsBinding {
+	b.path = strings.TrimPrefix(b.path+"."+name, ".")
+
+	return b
+}
+
+// jsModule holds the names bound by the imports of a file, and the names it exports
+type jsModule struct {
+	bindings map[string]jsBinding
+	exports  map[string]jsBinding
+	// stars lists the modules re-exported with `export * from "module"`
+	stars []jsBinding
+}
+
+// moduleOf returns the imports and exports of a parsed file
+func (r *ReachabilityJavaScript) moduleOf(source *SourceContext) *jsModule {
+	if module, ok := r.modules[source.Path]; ok {
+		return module
+	}
+
+	module := &jsModule{bindings: make(map[string]jsBinding), exports: make(map[string]jsBinding)}
+	// Cycles of imports end on this module being empty while it is indexed
+	r.modules[source.Path] = module
+
+	for _, node := range source.Captures(r.grammarOf(source.Path).tsQueries["module"]) {
+		switch node.Kind() {
+		case "import_statement":
+			r.addImport(source, module, node)
+		case "export_statement":
+			r.addExport(source, module, node)
+		case "variable_declarator":
+			r.addDeclarator(source, module, node)
+		}
+	}
+
+	return module
+}
+
+// loadModule returns the imports and exports of the file of a local module, or nil if it cannot be parsed
+func (r *ReachabilityJavaScript) loadModule(path string) *jsModule {
+	if module, ok := r.modules[path]; ok {
+		return module
+	}
+
+	source, err := NewSourceContext(r.grammarOf(path).tsParser, filepath.Dir(path), path)
+	if err != nil {
+		r.modules[path] = nil
+		return nil
+	}
+	defer source.Close()
+
+	return r.moduleOf(source)
+}
+
+// addImport binds the names imported by an ESM import statement, or by a TypeScript `import name = require("module")`
+func (r *ReachabilityJavaScript) addImport(source *SourceContext, module *jsModule, node *treesitter.Node) {
+	for i := range node.NamedChildCount() {
+		clause := node.NamedChild(i)
+		if clause.Kind() != "import_require_clause" {
+			continue
+		}
+		if from, ok := r.moduleBinding(source, clause.ChildByFieldName("source")); ok && clause.NamedChild(0).Kind() == "identifier" {
+			module.bindings[source.Text(clause.NamedChild(0))] = from
+		}
+
+		return
+	}
+
+	from, ok := r.moduleBinding(source, node.ChildByFieldName("source"))
+	if !ok {
+		return
+	}
+
+	for i := range node.NamedChildCount() {
+		clause := node.NamedChild(i)
+		if clause.Kind() != "import_clause" {
+			continue
+		}
+
+		for j := range clause.NamedChildCount() {
+			child := clause.NamedChild(j)
+			switch child.Kind() {
+			case "identifier":
+				// Default imports of packages refer to the package itself, as most of them are CommonJS modules
+				if from.local {
+					module.bindings[source.Text(child)] = from.member("default")
+				} else {
+					module.bindings[source.Text(child)] = from
+				}
+			case "namespace_import":
+				if child.NamedChildCount() > 0 {
+					module.bindings[source.Text(child.NamedChild(0))] = from
+				}
+			case "named_imports":
+				for k := range child.NamedChildCount() {
+					specifier := child.NamedChild(k)
+					name, alias := specifier.ChildByFieldName("name"), specifier.ChildByFieldName("alias")
+					if name == nil {
+						continue
+					}
+					if alias == nil {
+						alias = name
+					}
+					module.bindings[source.Text(alias)] = from.member(unquote(source.Text(name)))
+				}
+			}
+		}
+	}
+}
+
+// addExport records the names re-exported by an export statement
+func (r *ReachabilityJavaScript) addExport(source *SourceContext, module *jsModule, node *treesitter.Node) {
+	from, hasSource := r.moduleBinding(source, node.ChildByFieldName("source"))
+	if node.ChildByFieldName("source") != nil && !hasSource {
+		return
+	}
+
+	namespace := false
+	for i := range node.ChildCount() {
+		child := node.Child(i)
+		switch child.Kind() {
+		case "*":
+			namespace = true
+		case "namespace_export":
+			// export * as name from "module"
+			if hasSource && child.NamedChildCount() > 0 {
+				module.exports[unquote(source.Text(child.NamedChild(0)))] = from
+			}
+
+			return
+		case "export_clause":
+			for j := range child.NamedChildCount() {
+				specifier := child.NamedChild(j)
+				name, alias := specifier.ChildByFieldName("name"), specifier.ChildByFieldName("alias")
+				if name == nil {
+					continue
+				}
+				if alias == nil {
+					alias = name
+				}
+
+				switch {
+				case hasSource:
+					module.exports[unquote(source.Text(alias))] = from.member(unquote(source.Text(name)))
+				default:
+					// export { name }: the name can be an import of the module
+					if binding, ok := module.bindings[source.Text(name)]; ok {
+						module.exports[unquote(source.Text(alias))] = binding
+					}
+				}
+			}
+
+			return
+		}
+	}
+
+	if namespace && hasSource {
+		module.stars = append(module.stars, from)
+	}
+}
+
+// addDeclarator binds the names of a variable declared with a value referring to a module, such as
+// `const _ = require("lodash")`, `const { merge } = require("lodash")` or `const merge = _.merge`
+func (r *ReachabilityJavaScript) addDeclarator(source *SourceContext, module *jsModule, node *treesitter.Node) {
+	name, value := node.ChildByFieldName("name"), node.ChildByFieldName("value")
+	if name == nil || value == nil {
+		return
+	}
+
+	binding, ok := r.resolve(source, module, value)
+	if !ok {
+		return
+	}
+
+	switch name.Kind() {
+	case "identifier":
+		module.bindings[source.Text(name)] = binding
+	case "object_pattern":
+		for i := range name.NamedChildCount() {
+			property := name.NamedChild(i)
+			switch property.Kind() {
+			case "shorthand_property_identifier_pattern":
+				module.bindings[source.Text(property)] = binding.member(source.Text(property))
+			case "pair_pattern":
+				key, value := property.ChildByFieldName("key"), property.ChildByFieldName("value")
+				if key != nil && value != nil && value.Kind() == "identifier" {
+					module.bindings[source.Text(value)] = binding.member(unquote(source.Text(key)))
+				}
+			}
+		}
+	}
+}
+
+// resolve returns what an expression refers to, following the re-exports of local modules
+func (r *ReachabilityJavaScript) resolve(source *SourceContext, module *jsModule, node *treesitter.Node) (jsBinding, bool) {
+	var binding jsBinding
+	var ok bool
+
+	switch node.Kind() {
+	case "identifier":
+		binding, ok = module.bindings[source.Text(node)]
+	case "member_expression":
+		property := node.ChildByFieldName("property")
+		if binding, ok = r.resolve(source, module, node.ChildByFieldName("object")); ok && property != nil {
+			binding = binding.member(source.Text(property))
+		}
+	case "call_expression":
+		// require("module")
+		function, arguments := node.ChildByFieldName("function"), node.ChildByFieldName("arguments")
+		if function != nil && source.Text(function) == "require" && arguments != nil && arguments.NamedChildCount() == 1 {
+			binding, ok = r.moduleBinding(source, arguments.NamedChild(0))
+		}
+	case "parenthesized_expression":
+		if node.NamedChildCount() == 1 {
+			binding, ok = r.resolve(source, module, node.NamedChild(0))
+		}
+	case "as_expression", "satisfies_expression", "non_null_expression":
+		// TypeScript assertions do not change what the expression refers to
+		if node.NamedChildCount() > 0 {
+			binding, ok = r.resolve(source, module, node.NamedChild(0))
+		}
+	}
+
+	if !ok {
+		return jsBinding{}, false
+	}
+
+	return r.followExports(binding, 0)
+}
+
+// followExports resolves the exports of local modules to the packages they re-export, when they do
+func (r *ReachabilityJavaScript) followExports(binding jsBinding, depth int) (jsBinding, bool) {
+	if !binding.local || binding.path == "" {
+		return binding, true
+	}
+	if depth > maxReExportDepth {
+		return jsBinding{}, false
+	}
+
+	module := r.loadModule(binding.module)
+	if module == nil {
+		return jsBinding{}, false
+	}
+
+	name, rest, _ := strings.Cut(binding.path, ".")
+	if exported, ok := module.exports[name]; ok {
+		if rest != "" {
+			exported = exported.member(rest)
+		}
+
+		return r.followExports(exported, depth+1)
+	}
+
+	for _, star := range module.stars {
+		if resolved, ok := r.followExports(star.member(binding.path), depth+1); ok && !resolved.local {
+			return resolved, true
+		}
+	}
+
+	// The export is declared by the local module itself
+	return binding, true
+}
+
+// moduleBinding returns the binding of the module named by a string, resolving the files of local modules
+func (r *ReachabilityJavaScript) moduleBinding(source *SourceContext, node *treesitter.Node) (jsBinding, bool) {
+	if node == nil || node.Kind() != "string" {
+		return jsBinding{}, false
+	}
+
+	specifier := unquote(source.Text(node))
+	if !strings.HasPrefix(specifier, ".") && !strings.HasPrefix(specifier, "/") {
+		return jsBinding{module: specifier}, true
+	}
+
+	path, ok := resolveLocalModule(filepath.Join(filepath.Dir(source.Path), filepath.FromSlash(specifier)))
+
+	return jsBinding{module: path, local: true}, ok
+}
+
+// resolveLocalModule returns the file of a local module, trying the extensions and index files
+// the way Node.js and TypeScript do
+func resolveLocalModule(path string) (string, bool) {
+	candidates := []string{path}
+	// TypeScript sources import the JavaScript files they are compiled to
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	for _, extension := range javaScriptExtensions {
+		candidates = append(candidates, path+extension, base+extension, filepath.Join(path, "index"+extension))
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, true
+		}
+	}
+
+	return "", false
+}
+
+func unquote(text string) string {
+	return strings.Trim(text, "\"'`")
+}
+
+var _ Detector = &ReachabilityJavaScript{}
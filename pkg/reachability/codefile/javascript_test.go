@@ -0,0 +1,128 @@
+package codefile
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewJavaScriptReachableDetector(t *testing.T) {
+	t.Parallel()
+	detector, err := NewJavaScriptReachableDetector()
+	require.NoError(t, err)
+	defer detector.Close()
+
+	assert.NotNil(t, detector)
+}
+
+func Test_DetectJavaScript(t *testing.T) {
+	t.Parallel()
+	detector, err := NewJavaScriptReachableDetector()
+	require.NoError(t, err)
+	defer detector.Close()
+
+	advisoriesToCheck := []models.AdvisoryToCheck{
+		{
+			Purl:       "pkg:npm/lodash@4.17.20",
+			AdvisoryID: "GHSA-lodash",
+			Symbols: []models.Symbols{
+				{Type: "function", Value: "lodash", Name: "template"},
+				{Type: "function", Value: "lodash", Name: "merge"},
+				{Type: "function", Value: "lodash", Name: "set"},
+				{Type: "function", Value: "lodash", Name: "pick"},
+				{Type: "function", Value: "lodash", Name: "Template"},
+				{Type: "function", Value: "lodash", Name: "clone"},
+			},
+		},
+		{
+			Purl:       "pkg:npm/qs@6.5.0",
+			AdvisoryID: "GHSA-qs",
+			Symbols: []models.Symbols{
+				{Type: "function", Value: "qs", Name: "parse"},
+				{Type: "function", Value: "qs", Name: "stringify"},
+			},
+		},
+		{
+			Purl:       "pkg:npm/yaml@2.0.0",
+			AdvisoryID: "GHSA-yaml",
+			Symbols:    []models.Symbols{{Type: "function", Value: "yaml", Name: "parse"}},
+		},
+	}
+
+	tests := map[string]map[string][]string{
+		"testdata/javascript/app.js": {
+			"GHSA-lodash": {"_.template", "tpl", "merge", "set"},
+			"GHSA-qs":     {"qs.parse"},
+		},
+		"testdata/javascript/esm.mjs": {
+			"GHSA-lodash": {"deepMerge", "pick", "_.Template"},
+			"GHSA-qs":     {"qs.parse"},
+			"GHSA-yaml":   {"parse"},
+		},
+		"testdata/javascript/service.ts": {
+			"GHSA-qs": {"stringify"},
+		},
+		"testdata/javascript/typed.ts": {
+			"GHSA-lodash": {"merge"},
+			"GHSA-qs":     {"(qs as typeof import(\"qs\")).stringify"},
+		},
+		"testdata/javascript/view.tsx": {
+			"GHSA-lodash": {"template"},
+		},
+		"testdata/javascript/local.js": {},
+	}
+
+	for path, expected := range tests {
+		detectionResults := models.DetectionResults{}
+		err := detector.Detect(".", path, detectionResults, advisoriesToCheck)
+		require.NoError(t, err, path)
+
+		found := make(map[string][]string)
+		for _, advisories := range detectionResults {
+			for advisoryID, locations := range advisories {
+				for _, location := range locations {
+					found[advisoryID] = append(found[advisoryID], location.Symbol)
+				}
+			}
+		}
+		assert.Equal(t, expected, found, path)
+	}
+}
+
+func Test_DetectJavaScript_Location(t *testing.T) {
+	t.Parallel()
+	detector, err := NewJavaScriptReachableDetector()
+	require.NoError(t, err)
+	defer detector.Close()
+
+	advisoriesToCheck := []models.AdvisoryToCheck{
+		{
+			Purl:       "pkg:npm/qs@6.5.0",
+			AdvisoryID: "GHSA-qs",
+			Symbols:    []models.Symbols{{Type: "function", Value: "qs", Name: "stringify"}},
+		},
+	}
+	detectionResults := models.DetectionResults{}
+	err = detector.Detect(".", "testdata/javascript/service.ts", detectionResults, advisoriesToCheck)
+	require.NoError(t, err)
+
+	assert.Equal(t, models.DetectionResults{
+		"pkg:npm/qs@6.5.0": {
+			"GHSA-qs": {
+				{
+					Symbol: "stringify",
+					PackageLocation: models.PackageLocation{
+						Filename:    "testdata/javascript/service.ts",
+						LineStart:   8,
+						LineEnd:     8,
+						ColumnStart: 10,
+						ColumnEnd:   19,
+					},
+				},
+			},
+		},
+	}, detectionResults)
+}
@@ -0,0 +1,276 @@
+package codefile
+
+import (
+	"archive/zip"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/utility/fileposition"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+)
+
+// jvmReferenceKind is the way compiled code refers to a class or to one of its members
+type jvmReferenceKind int
+
+const (
+	// jvmNew is the creation of an object, by a new instruction or a constructor reference (Greeter::new)
+	jvmNew jvmReferenceKind = iota
+	// jvmInvoke is a call of an instance method, or a reference to it (yaml::load)
+	jvmInvoke
+	// jvmInvokeStatic is a call of a static method, or a reference to it (Settings::load)
+	jvmInvokeStatic
+	jvmField
+	// jvmType is any use of a class, including the ones of the signatures and the declarations of the class
+	jvmType
+)
+
+// jvmReference is a reference of a compiled class to a class or to one of its members
+type jvmReference struct {
+	kind jvmReferenceKind
+	// class is the Java name of the referenced class, or of the class declaring the referenced member
+	class  string
+	member string
+	// method is the Java name of the method using the reference, empty for the declarations of the class
+	method string
+	line   int
+}
+
+// maxJVMClassSize is the size above which the entries of jar archives are not read, as they are not valid classes
+const maxJVMClassSize = 64 << 20
+
+// ReachabilityBytecode detects the usages of the vulnerable symbols of Maven packages in compiled class files and in
+// the classes of jar archives, with the symbol types of Java. The usages are read from the instructions of the methods,
+// so the ones of compile-time constants, which are inlined, are not found.
+// The classes of nested archives, such as the dependencies bundled in BOOT-INF/lib, are not analyzed.
+type ReachabilityBytecode struct{}
+
+// NewBytecodeReachableDetector creates a detector for class files and jar archives, Close should be called once all
+// the files are parsed
+func NewBytecodeReachableDetector() (*ReachabilityBytecode, error) {
+	return &ReachabilityBytecode{}, nil
+}
+
+// Close does nothing, as the detector does not keep any resource.
+func (r *ReachabilityBytecode) Close() {}
+
+func (r *ReachabilityBytecode) Detect(dir string, path string, detectionResults models.DetectionResults, advisoriesToCheck []models.AdvisoryToCheck) error {
+	filename := fileposition.ToRelativePath(dir, path)
+
+	if !strings.EqualFold(filepath.Ext(path), ".jar") {
+		content, err := readFileContent(path)
+		if err != nil {
+			return err
+		}
+
+		return detectJVMClass(content, filename, detectionResults, advisoriesToCheck)
+	}
+
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		log.Printf("Failed to open jar archive %s: %v\n", path, err)
+		return nil
+	}
+	defer archive.Close()
+
+	for _, entry := range archive.File {
+		if !strings.HasSuffix(entry.Name, ".class") || entry.UncompressedSize64 > maxJVMClassSize {
+			continue
+		}
+		content, err := readZipEntry(entry)
+		if err != nil {
+			log.Printf("Failed to read %s of jar archive %s: %v\n", entry.Name, path, err)
+			continue
+		}
+
+		// The classes of archives are located as in the jar URLs of Java, such as app.jar!/com/sample/App.class
+		err = detectJVMClass(content, filename+"!/"+entry.Name, detectionResults, advisoriesToCheck)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readZipEntry(entry *zip.File) ([]byte, error) {
+	reader, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+// detectJVMClass detects the usages of the symbols of the advisories in a class file, reported at the line of the
+// instruction using them, or without line for the declarations of the class
+func detectJVMClass(content []byte, filename string, detectionResults models.DetectionResults, advisoriesToCheck []models.AdvisoryToCheck) error {
+	class, err := parseJVMClass(content)
+	if err != nil {
+		// Invalid classes, such as the ones of obfuscated or corrupted archives, should not stop the analysis
+		log.Printf("Failed to parse class file %s: %v\n", filename, err)
+		return nil
+	}
+
+	className := javaClassName(class.name)
+	references := class.references()
+
+	for _, advisoryToCheck := range advisoriesToCheck {
+		for _, s := range advisoryToCheck.Symbols {
+			kinds, ok := jvmReferenceKinds[s.Type]
+			if !ok {
+				log.Printf("No matcher found for symbol type %s\n", s.Type)
+				continue
+			}
+
+			// The classes of the vulnerable package itself, such as the ones of shaded archives, are not its usages
+			pkg := s.Value
+			if s.Type != "class" && s.Type != "type_reference" {
+				pkg = pkg[:max(strings.LastIndexByte(pkg, '.'), 0)]
+			}
+			if pkg != "" && strings.HasPrefix(className, pkg+".") {
+				continue
+			}
+
+			class, member := s.Value+"."+s.Name, ""
+			if s.Type != "class" && s.Type != "type_reference" {
+				class, member = s.Value, s.Name
+			}
+
+			seen := make(map[jvmReference]bool)
+			for _, reference := range references {
+				if !kinds[reference.kind] || reference.class != class || reference.member != member {
+					continue
+				}
+				// Several references of a symbol at the same line, such as the ones of a class and its constructor,
+				// are reported once
+				key := jvmReference{method: reference.method, line: reference.line}
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+
+				symbol := reference.class
+				if member != "" {
+					symbol += "." + member
+				}
+				addBytecodeDetection(detectionResults, advisoryToCheck, filename, reference, symbol)
+			}
+		}
+	}
+
+	return nil
+}
+
+// jvmReferenceKinds lists the kinds of references matching each symbol type
+var jvmReferenceKinds = map[string]map[jvmReferenceKind]bool{
+	"class":          {jvmNew: true},
+	"method":         {jvmInvoke: true, jvmInvokeStatic: true},
+	"static_method":  {jvmInvokeStatic: true},
+	"field":          {jvmField: true},
+	"type_reference": {jvmType: true},
+}
+
+// addBytecodeDetection records that a symbol of an advisory is used by a compiled class. Class files have no columns,
+// and only have lines when they are compiled with debug information, which javac adds by default.
+func addBytecodeDetection(detectionResults models.DetectionResults, advisoryToCheck models.AdvisoryToCheck, filename string, reference jvmReference, symbol string) {
+	if _, ok := detectionResults[advisoryToCheck.Purl]; !ok {
+		detectionResults[advisoryToCheck.Purl] = make(map[string]models.ReachableSymbolLocations)
+	}
+
+	detectionResults[advisoryToCheck.Purl][advisoryToCheck.AdvisoryID] = append(
+		detectionResults[advisoryToCheck.Purl][advisoryToCheck.AdvisoryID],
+		models.ReachableSymbolLocation{
+			Symbol: symbol,
+			Method: reference.method,
+			PackageLocation: models.PackageLocation{
+				Filename:  filename,
+				LineStart: reference.line,
+				LineEnd:   reference.line,
+			},
+		})
+}
+
+// references returns the references of the class to other classes and their members, in the order of the class file
+func (c *jvmClass) references() []jvmReference {
+	var references []jvmReference
+	addTypes := func(method string, line int, binaryNames ...string) {
+		for _, binaryName := range binaryNames {
+			if name := javaClassName(binaryName); name != "" {
+				references = append(references, jvmReference{kind: jvmType, class: name, method: method, line: line})
+			}
+		}
+	}
+
+	addTypes("", 0, c.superClass)
+	addTypes("", 0, c.interfaces...)
+	for _, descriptor := range c.descriptors {
+		addTypes("", 0, descriptorClasses(descriptor)...)
+	}
+
+	className := javaClassName(c.name)
+	for _, method := range c.methods {
+		methodName := className + "." + method.name
+
+		for _, instruction := range method.instructions() {
+			line := method.line(instruction.pc)
+			addMember := func(kind jvmReferenceKind, index uint16) {
+				class, name, descriptor := c.memberRef(index)
+				if class == "" {
+					return
+				}
+				addTypes(methodName, line, class)
+				addTypes(methodName, line, descriptorClasses(descriptor)...)
+				if name == "<init>" {
+					// Constructors are called after the new instruction creating the object, which is the one reported
+					if kind != jvmNew {
+						return
+					}
+					name = ""
+				}
+				references = append(references, jvmReference{kind: kind, class: javaClassName(class), member: name, method: methodName, line: line})
+			}
+
+			switch opcode := instruction.opcode; {
+			case opcode == opNew:
+				addTypes(methodName, line, c.className(instruction.operand))
+				references = append(references, jvmReference{kind: jvmNew, class: javaClassName(c.className(instruction.operand)), method: methodName, line: line})
+			case opcode == opInvokeVirtual, opcode == opInvokeSpecial, opcode == opInvokeInterface:
+				addMember(jvmInvoke, instruction.operand)
+			case opcode == opInvokeStatic:
+				addMember(jvmInvokeStatic, instruction.operand)
+			case opcode >= opGetStatic && opcode <= opPutField:
+				addMember(jvmField, instruction.operand)
+			case opcode == opCheckCast, opcode == opInstanceOf, opcode == opANewArray, opcode == opMultiANewArray,
+				opcode == opLdc, opcode == opLdcW:
+				addTypes(methodName, line, c.className(instruction.operand))
+			case opcode == opInvokeDynamic:
+				// Method references are invokedynamic instructions whose bootstrap arguments are method handles
+				bootstrap := int(c.constant(instruction.operand).first)
+				if c.constant(instruction.operand).tag != constantInvokeDynamic || bootstrap >= len(c.bootstrapMethods) {
+					continue
+				}
+				for _, argument := range c.bootstrapMethods[bootstrap].arguments {
+					handle := c.constant(argument)
+					if handle.tag != constantMethodHandle {
+						continue
+					}
+					addMember(jvmMethodHandleKinds[handle.first], handle.second)
+				}
+			}
+		}
+	}
+
+	return references
+}
+
+// jvmMethodHandleKinds maps the kinds of the method handles to the kinds of the references they make, see
+// https://docs.oracle.com/javase/specs/jvms/se21/html/jvms-5.html#jvms-5.4.3.5
+var jvmMethodHandleKinds = map[uint16]jvmReferenceKind{
+	1: jvmField, 2: jvmField, 3: jvmField, 4: jvmField,
+	5: jvmInvoke, 6: jvmInvokeStatic, 7: jvmInvoke, 8: jvmNew, 9: jvmInvoke,
+}
+
+var _ Detector = &ReachabilityBytecode{}
@@ -0,0 +1,345 @@
+package codefile
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+)
+
+// classBuilder assembles class files, as the tests cannot rely on a Java compiler
+type classBuilder struct {
+	pool      []byte
+	count     uint16
+	indexes   map[string]uint16
+	methods   [][]byte
+	bootstrap [][]uint16
+}
+
+// testMethod is a method of a built class, lines holding pairs of bytecode offsets and source lines
+type testMethod struct {
+	name       string
+	descriptor string
+	code       []byte
+	lines      [][2]uint16
+}
+
+func newClassBuilder() *classBuilder {
+	return &classBuilder{count: 1, indexes: make(map[string]uint16)}
+}
+
+func u2(value uint16) []byte {
+	return binary.BigEndian.AppendUint16(nil, value)
+}
+
+func u4(value int) []byte {
+	return binary.BigEndian.AppendUint32(nil, uint32(value)) //nolint:gosec // sizes of test classes are small
+}
+
+// constant adds an entry to the constant pool, unless an identical one already exists
+func (b *classBuilder) constant(entry ...[]byte) uint16 {
+	var data []byte
+	for _, part := range entry {
+		data = append(data, part...)
+	}
+	if index, ok := b.indexes[string(data)]; ok {
+		return index
+	}
+
+	index := b.count
+	b.indexes[string(data)] = index
+	b.pool = append(b.pool, data...)
+	b.count++
+	if data[0] == constantLong || data[0] == constantDouble {
+		b.count++
+	}
+
+	return index
+}
+
+func (b *classBuilder) utf8(value string) uint16 {
+	return b.constant([]byte{constantUtf8}, u2(uint16(len(value))), []byte(value)) //nolint:gosec // names are short
+}
+
+func (b *classBuilder) class(name string) uint16 {
+	return b.constant([]byte{constantClass}, u2(b.utf8(name)))
+}
+
+func (b *classBuilder) member(tag byte, class string, name string, descriptor string) uint16 {
+	nameAndType := b.constant([]byte{constantNameAndType}, u2(b.utf8(name)), u2(b.utf8(descriptor)))
+
+	return b.constant([]byte{tag}, u2(b.class(class)), u2(nameAndType))
+}
+
+func (b *classBuilder) long(value uint64) uint16 {
+	return b.constant([]byte{constantLong}, binary.BigEndian.AppendUint64(nil, value))
+}
+
+// methodReference adds a method handle to a bootstrap method, as done by javac for method references such as Yaml::load
+func (b *classBuilder) methodReference(kind byte, reference uint16) uint16 {
+	handle := b.constant([]byte{constantMethodHandle, kind}, u2(reference))
+	b.bootstrap = append(b.bootstrap, []uint16{handle})
+	nameAndType := b.constant([]byte{constantNameAndType}, u2(b.utf8("apply")), u2(b.utf8("()Ljava/util/function/Function;")))
+
+	//nolint:gosec // bootstrap methods are few
+	return b.constant([]byte{constantInvokeDynamic}, u2(uint16(len(b.bootstrap)-1)), u2(nameAndType))
+}
+
+func (b *classBuilder) method(method testMethod) {
+	var code []byte
+	code = append(code, u2(4)...) // max stack
+	code = append(code, u2(4)...) // max locals
+	code = append(code, u4(len(method.code))...)
+	code = append(code, method.code...)
+	code = append(code, u2(0)...) // exception table
+	if len(method.lines) == 0 {
+		code = append(code, u2(0)...)
+	} else {
+		lines := u2(uint16(len(method.lines))) //nolint:gosec // test methods are short
+		for _, line := range method.lines {
+			lines = append(lines, u2(line[0])...)
+			lines = append(lines, u2(line[1])...)
+		}
+		code = append(code, u2(1)...)
+		code = append(code, u2(b.utf8("LineNumberTable"))...)
+		code = append(code, u4(len(lines))...)
+		code = append(code, lines...)
+	}
+
+	var data []byte
+	data = append(data, u2(0x0009)...) // public static
+	data = append(data, u2(b.utf8(method.name))...)
+	data = append(data, u2(b.utf8(method.descriptor))...)
+	data = append(data, u2(1)...)
+	data = append(data, u2(b.utf8("Code"))...)
+	data = append(data, u4(len(code))...)
+	data = append(data, code...)
+	b.methods = append(b.methods, data)
+}
+
+// build returns the class file of a class extending the given class, declaring the methods added to the builder
+func (b *classBuilder) build(name string, superClass string) []byte {
+	this, super := b.class(name), b.class(superClass)
+
+	var attributes []byte
+	attributesCount := uint16(0)
+	if len(b.bootstrap) > 0 {
+		bootstrap := b.member(constantMethodref, "java/lang/invoke/LambdaMetafactory", "metafactory", "()V")
+		handle := b.constant([]byte{constantMethodHandle, 6}, u2(bootstrap))
+		methods := u2(uint16(len(b.bootstrap))) //nolint:gosec // bootstrap methods are few
+		for _, arguments := range b.bootstrap {
+			methods = append(methods, u2(handle)...)
+			methods = append(methods, u2(uint16(len(arguments)))...) //nolint:gosec // arguments are few
+			for _, argument := range arguments {
+				methods = append(methods, u2(argument)...)
+			}
+		}
+		attributes = append(attributes, u2(b.utf8("BootstrapMethods"))...)
+		attributes = append(attributes, u4(len(methods))...)
+		attributes = append(attributes, methods...)
+		attributesCount++
+	}
+
+	data := []byte{0xca, 0xfe, 0xba, 0xbe, 0, 0, 0, 52}
+	data = append(data, u2(b.count)...)
+	data = append(data, b.pool...)
+	data = append(data, u2(0x0021)...) // public super
+	data = append(data, u2(this)...)
+	data = append(data, u2(super)...)
+	data = append(data, u2(0)...)                      // interfaces
+	data = append(data, u2(0)...)                      // fields
+	data = append(data, u2(uint16(len(b.methods)))...) //nolint:gosec // test classes are small
+	for _, method := range b.methods {
+		data = append(data, method...)
+	}
+	data = append(data, u2(attributesCount)...)
+	data = append(data, attributes...)
+
+	return data
+}
+
+// buildLoaderClass assembles the class javac compiles from:
+//
+//	package com.sample;
+//
+//	import org.yaml.snakeyaml.Yaml;
+//
+//	public class Loader {
+//	  public static Object load(String document) {
+//	    Yaml yaml = new Yaml();
+//	    return yaml.load(document);
+//	  }
+//	}
+func buildLoaderClass(name string) []byte {
+	b := newClassBuilder()
+	yaml := b.class("org/yaml/snakeyaml/Yaml")
+	constructor := b.member(constantMethodref, "org/yaml/snakeyaml/Yaml", "<init>", "()V")
+	load := b.member(constantMethodref, "org/yaml/snakeyaml/Yaml", "load", "(Ljava/lang/String;)Ljava/lang/Object;")
+
+	code := []byte{opNew}
+	code = append(code, u2(yaml)...)
+	code = append(code, 0x59, opInvokeSpecial) // dup
+	code = append(code, u2(constructor)...)
+	code = append(code, 0x4c, 0x2b, 0x2a, opInvokeVirtual) // astore_1, aload_1, aload_0
+	code = append(code, u2(load)...)
+	code = append(code, 0xb0) // areturn
+	b.method(testMethod{
+		name:       "load",
+		descriptor: "(Ljava/lang/String;)Ljava/lang/Object;",
+		code:       code,
+		lines:      [][2]uint16{{0, 7}, {8, 8}},
+	})
+
+	return b.build(name, "java/lang/Object")
+}
+
+var yamlAdvisory = models.AdvisoryToCheck{
+	Purl:       "pkg:maven/org.yaml/snakeyaml@1.33",
+	AdvisoryID: "GHSA-mjmj-j48q-9wg2",
+	Symbols: []models.Symbols{
+		{Type: "class", Value: "org.yaml.snakeyaml", Name: "Yaml"},
+		{Type: "method", Value: "org.yaml.snakeyaml.Yaml", Name: "load"},
+	},
+}
+
+func TestBytecodeReachableDetector_ClassFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "com", "sample", "Loader.class")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, buildLoaderClass("com/sample/Loader"), 0o600))
+
+	detector, err := NewBytecodeReachableDetector()
+	require.NoError(t, err)
+	defer detector.Close()
+
+	detectionResults := make(models.DetectionResults)
+	require.NoError(t, detector.Detect(dir, path, detectionResults, []models.AdvisoryToCheck{yamlAdvisory}))
+
+	assert.Equal(t, models.ReachableSymbolLocations{
+		{
+			Symbol:          "org.yaml.snakeyaml.Yaml",
+			Method:          "com.sample.Loader.load",
+			PackageLocation: models.PackageLocation{Filename: "com/sample/Loader.class", LineStart: 7, LineEnd: 7},
+		},
+		{
+			Symbol:          "org.yaml.snakeyaml.Yaml.load",
+			Method:          "com.sample.Loader.load",
+			PackageLocation: models.PackageLocation{Filename: "com/sample/Loader.class", LineStart: 8, LineEnd: 8},
+		},
+	}, detectionResults[yamlAdvisory.Purl][yamlAdvisory.AdvisoryID])
+}
+
+func TestBytecodeReachableDetector_Jar(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.jar")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	archive := zip.NewWriter(file)
+	for name, content := range map[string][]byte{
+		"com/sample/Loader.class": buildLoaderClass("com/sample/Loader"),
+		// The classes of the vulnerable package itself, shaded in the archive, are not usages
+		"org/yaml/snakeyaml/internal/Loader.class": buildLoaderClass("org/yaml/snakeyaml/internal/Loader"),
+		"com/sample/Invalid.class":                 []byte("not a class"),
+		"META-INF/MANIFEST.MF":                     []byte("Manifest-Version: 1.0\n"),
+	} {
+		writer, err := archive.Create(name)
+		require.NoError(t, err)
+		_, err = writer.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, archive.Close())
+	require.NoError(t, file.Close())
+
+	detector, err := NewBytecodeReachableDetector()
+	require.NoError(t, err)
+	defer detector.Close()
+
+	detectionResults := make(models.DetectionResults)
+	require.NoError(t, detector.Detect(dir, path, detectionResults, []models.AdvisoryToCheck{yamlAdvisory}))
+
+	locations := detectionResults[yamlAdvisory.Purl][yamlAdvisory.AdvisoryID]
+	require.Len(t, locations, 2)
+	for _, location := range locations {
+		assert.Equal(t, "app.jar!/com/sample/Loader.class", location.Filename)
+	}
+}
+
+func TestBytecodeReachableDetector_SymbolTypes(t *testing.T) {
+	t.Parallel()
+
+	b := newClassBuilder()
+	limit := b.long(1 << 40)
+	settings := b.class("com/example/Settings")
+	parse := b.member(constantMethodref, "com/example/Settings", "parse", "(Ljava/lang/String;)Lcom/example/Settings;")
+	defaults := b.member(constantFieldref, "com/example/Settings", "DEFAULTS", "Lcom/example/Settings;")
+	reference := b.methodReference(6, parse)
+	constructorReference := b.methodReference(8, b.member(constantMethodref, "com/example/Settings", "<init>", "()V"))
+
+	code := []byte{0x14} // ldc2_w
+	code = append(code, u2(limit)...)
+	code = append(code, 0x58, 0x1a, opTableSwitch, 0, 0) // pop2, iload_0, padded tableswitch
+	code = append(code, u4(13)...)                       // default offset
+	code = append(code, u4(0)...)                        // low
+	code = append(code, u4(0)...)                        // high
+	code = append(code, u4(13)...)
+	code = append(code, opGetStatic)
+	code = append(code, u2(defaults)...)
+	code = append(code, opCheckCast)
+	code = append(code, u2(settings)...)
+	code = append(code, opInvokeStatic)
+	code = append(code, u2(parse)...)
+	code = append(code, opInvokeDynamic)
+	code = append(code, u2(reference)...)
+	code = append(code, 0, 0, opInvokeDynamic)
+	code = append(code, u2(constructorReference)...)
+	code = append(code, 0, 0, 0xb1) // return
+	b.method(testMethod{name: "run", descriptor: "(I)V", code: code})
+	content := b.build("com/sample/Runner", "com/example/Settings")
+
+	advisory := models.AdvisoryToCheck{
+		Purl:       "pkg:maven/com.example/settings@1.0.0",
+		AdvisoryID: "GHSA-aaaa-bbbb-cccc",
+		Symbols: []models.Symbols{
+			{Type: "static_method", Value: "com.example.Settings", Name: "parse"},
+			{Type: "field", Value: "com.example.Settings", Name: "DEFAULTS"},
+			{Type: "class", Value: "com.example", Name: "Settings"},
+			{Type: "type_reference", Value: "com.example", Name: "Settings"},
+		},
+	}
+	detectionResults := make(models.DetectionResults)
+	require.NoError(t, detectJVMClass(content, "Runner.class", detectionResults, []models.AdvisoryToCheck{advisory}))
+
+	var symbols []string
+	for _, location := range detectionResults[advisory.Purl][advisory.AdvisoryID] {
+		// Classes compiled without debug information have no lines
+		assert.Zero(t, location.LineStart)
+		symbols = append(symbols, location.Symbol+" "+location.Method)
+	}
+	// The static method is called and referenced, and the class is created through a constructor reference
+	assert.Equal(t, []string{
+		"com.example.Settings.parse com.sample.Runner.run",
+		"com.example.Settings.DEFAULTS com.sample.Runner.run",
+		"com.example.Settings com.sample.Runner.run",
+		"com.example.Settings ",
+		"com.example.Settings com.sample.Runner.run",
+	}, symbols)
+}
+
+func Test_descriptorClasses(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"java/lang/String", "java/util/List"}, descriptorClasses("(I[Ljava/lang/String;JLjava/util/List;)V"))
+	assert.Empty(t, descriptorClasses("([BZ)J"))
+	assert.Equal(t, "com.sample.App.Inner", javaClassName("com/sample/App$Inner"))
+	assert.Equal(t, "com.sample.App", javaClassName("[[Lcom/sample/App;"))
+}
@@ -0,0 +1,427 @@
+package codefile
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The tags of the constants of a class file used to find the references of its code, see
+// https://docs.oracle.com/javase/specs/jvms/se21/html/jvms-4.html#jvms-4.4
+const (
+	constantUtf8               = 1
+	constantInteger            = 3
+	constantFloat              = 4
+	constantLong               = 5
+	constantDouble             = 6
+	constantClass              = 7
+	constantString             = 8
+	constantFieldref           = 9
+	constantMethodref          = 10
+	constantInterfaceMethodref = 11
+	constantNameAndType        = 12
+	constantMethodHandle       = 15
+	constantMethodType         = 16
+	constantDynamic            = 17
+	constantInvokeDynamic      = 18
+	constantModule             = 19
+	constantPackage            = 20
+)
+
+// The opcodes of the instructions referencing classes and their members
+const (
+	opLdc             = 0x12
+	opLdcW            = 0x13
+	opTableSwitch     = 0xaa
+	opLookupSwitch    = 0xab
+	opGetStatic       = 0xb2
+	opPutField        = 0xb5
+	opInvokeVirtual   = 0xb6
+	opInvokeSpecial   = 0xb7
+	opInvokeStatic    = 0xb8
+	opInvokeInterface = 0xb9
+	opInvokeDynamic   = 0xba
+	opNew             = 0xbb
+	opANewArray       = 0xbd
+	opCheckCast       = 0xc0
+	opInstanceOf      = 0xc1
+	opWide            = 0xc4
+	opIinc            = 0x84
+	opMultiANewArray  = 0xc5
+)
+
+// jvmOperandSizes holds the size of the operands of the instructions, -1 for the switches whose size varies
+// and for the unknown opcodes
+var jvmOperandSizes = func() [256]int {
+	var sizes [256]int
+	for opcode := range sizes {
+		switch {
+		case opcode <= 0x0f, opcode >= 0x1a && opcode <= 0x35, opcode >= 0x3b && opcode <= 0x83,
+			opcode >= 0x85 && opcode <= 0x98, opcode >= 0xac && opcode <= 0xb1,
+			opcode == 0xbe, opcode == 0xbf, opcode == 0xc2, opcode == 0xc3:
+			sizes[opcode] = 0
+		case opcode == 0x10, opcode == opLdc, opcode >= 0x15 && opcode <= 0x19, opcode >= 0x36 && opcode <= 0x3a,
+			opcode == 0xa9, opcode == 0xbc:
+			sizes[opcode] = 1
+		case opcode == 0x11, opcode == opLdcW, opcode == 0x14, opcode == opIinc, opcode >= 0x99 && opcode <= 0xa8,
+			opcode >= opGetStatic && opcode <= opInvokeStatic, opcode == opNew, opcode == opANewArray,
+			opcode == opCheckCast, opcode == opInstanceOf, opcode == 0xc6, opcode == 0xc7:
+			sizes[opcode] = 2
+		case opcode == opMultiANewArray:
+			sizes[opcode] = 3
+		case opcode == opInvokeInterface, opcode == opInvokeDynamic, opcode == 0xc8, opcode == 0xc9:
+			sizes[opcode] = 4
+		default:
+			sizes[opcode] = -1
+		}
+	}
+
+	return sizes
+}()
+
+var errInvalidClassFile = errors.New("invalid class file")
+
+// jvmConstant is an entry of the constant pool of a class file. The indexes of the entries it refers to are held
+// by first and second, such as the class and the name and type of a method reference.
+type jvmConstant struct {
+	tag    byte
+	first  uint16
+	second uint16
+	utf8   string
+}
+
+// jvmBootstrapMethod is an entry of the BootstrapMethods attribute, which links invokedynamic instructions
+// such as the ones of lambdas and method references (Yaml::load) to the methods they call
+type jvmBootstrapMethod struct {
+	arguments []uint16
+}
+
+// jvmMethod is a method of a class file, along with its bytecode
+type jvmMethod struct {
+	name       string
+	descriptor string
+	code       []byte
+	// lines maps the offsets of the instructions starting a source line to this line, from the LineNumberTable attribute
+	lines []jvmLine
+}
+
+type jvmLine struct {
+	pc   int
+	line int
+}
+
+// jvmClass is a parsed class file
+type jvmClass struct {
+	// name is the binary name of the class, such as com/sample/App$Inner
+	name       string
+	superClass string
+	interfaces []string
+	// descriptors lists the descriptors of the fields and methods declared by the class
+	descriptors      []string
+	methods          []jvmMethod
+	constants        []jvmConstant
+	bootstrapMethods []jvmBootstrapMethod
+}
+
+// classReader reads the big-endian values of a class file, recording the first read past its end
+type classReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *classReader) bytes(n int) []byte {
+	if r.err != nil || n < 0 || r.pos+n > len(r.data) {
+		r.err = errInvalidClassFile
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+
+	return b
+}
+
+func (r *classReader) u1() byte {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+
+	return 0
+}
+
+func (r *classReader) u2() uint16 {
+	if b := r.bytes(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+
+	return 0
+}
+
+func (r *classReader) u4() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+
+	return 0
+}
+
+// parseJVMClass parses a class file, keeping the parts needed to find the classes and members it references
+func parseJVMClass(data []byte) (*jvmClass, error) {
+	r := &classReader{data: data}
+	if r.u4() != 0xcafebabe {
+		return nil, errInvalidClassFile
+	}
+	r.bytes(4) // minor and major versions
+
+	class := &jvmClass{}
+	count := int(r.u2())
+	class.constants = make([]jvmConstant, count)
+	for i := 1; i < count && r.err == nil; i++ {
+		constant := jvmConstant{tag: r.u1()}
+		switch constant.tag {
+		case constantUtf8:
+			// Modified UTF-8 only differs from UTF-8 for the null character and the supplementary characters
+			constant.utf8 = string(r.bytes(int(r.u2())))
+		case constantClass, constantString, constantMethodType, constantModule, constantPackage:
+			constant.first = r.u2()
+		case constantMethodHandle:
+			constant.first = uint16(r.u1())
+			constant.second = r.u2()
+		case constantInteger, constantFloat, constantFieldref, constantMethodref, constantInterfaceMethodref,
+			constantNameAndType, constantDynamic, constantInvokeDynamic:
+			constant.first = r.u2()
+			constant.second = r.u2()
+		case constantLong, constantDouble:
+			r.bytes(8)
+		default:
+			return nil, fmt.Errorf("%w: unknown constant tag %d", errInvalidClassFile, constant.tag)
+		}
+		class.constants[i] = constant
+		if constant.tag == constantLong || constant.tag == constantDouble {
+			// Longs and doubles take two entries of the pool
+			i++
+		}
+	}
+
+	r.u2() // access flags
+	class.name = class.className(r.u2())
+	class.superClass = class.className(r.u2())
+	for range int(r.u2()) {
+		class.interfaces = append(class.interfaces, class.className(r.u2()))
+	}
+
+	for range int(r.u2()) {
+		class.descriptors = append(class.descriptors, class.readField(r))
+	}
+	for range int(r.u2()) {
+		method := class.readMethod(r)
+		class.descriptors = append(class.descriptors, method.descriptor)
+		class.methods = append(class.methods, method)
+	}
+
+	for range int(r.u2()) {
+		name := class.utf8(r.u2())
+		attribute := &classReader{data: r.bytes(int(r.u4()))}
+		if name != "BootstrapMethods" {
+			continue
+		}
+		for range int(attribute.u2()) {
+			attribute.u2() // bootstrap method
+			method := jvmBootstrapMethod{}
+			for range int(attribute.u2()) {
+				method.arguments = append(method.arguments, attribute.u2())
+			}
+			class.bootstrapMethods = append(class.bootstrapMethods, method)
+		}
+		if attribute.err != nil {
+			return nil, attribute.err
+		}
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return class, nil
+}
+
+// readField reads a field, returning its descriptor and skipping its attributes
+func (c *jvmClass) readField(r *classReader) string {
+	r.u2() // access flags
+	r.u2() // name
+	descriptor := c.utf8(r.u2())
+	for range int(r.u2()) {
+		r.u2()
+		r.bytes(int(r.u4()))
+	}
+
+	return descriptor
+}
+
+// readMethod reads a method along with the bytecode and the line numbers of its Code attribute
+func (c *jvmClass) readMethod(r *classReader) jvmMethod {
+	r.u2() // access flags
+	method := jvmMethod{name: c.utf8(r.u2()), descriptor: c.utf8(r.u2())}
+
+	for range int(r.u2()) {
+		name := c.utf8(r.u2())
+		attribute := &classReader{data: r.bytes(int(r.u4()))}
+		if name != "Code" {
+			continue
+		}
+
+		attribute.bytes(4) // max stack and max locals
+		method.code = attribute.bytes(int(attribute.u4()))
+		attribute.bytes(int(attribute.u2()) * 8) // exception table
+		for range int(attribute.u2()) {
+			name := c.utf8(attribute.u2())
+			codeAttribute := &classReader{data: attribute.bytes(int(attribute.u4()))}
+			if name != "LineNumberTable" {
+				continue
+			}
+			for range int(codeAttribute.u2()) {
+				method.lines = append(method.lines, jvmLine{pc: int(codeAttribute.u2()), line: int(codeAttribute.u2())})
+			}
+		}
+		if attribute.err != nil {
+			r.err = attribute.err
+		}
+	}
+
+	return method
+}
+
+// constant returns the entry of the constant pool at the given index, or an empty entry when it does not exist
+func (c *jvmClass) constant(index uint16) jvmConstant {
+	if int(index) >= len(c.constants) {
+		return jvmConstant{}
+	}
+
+	return c.constants[index]
+}
+
+func (c *jvmClass) utf8(index uint16) string {
+	return c.constant(index).utf8
+}
+
+// className returns the binary name of the class constant at the given index, such as com/sample/App
+func (c *jvmClass) className(index uint16) string {
+	constant := c.constant(index)
+	if constant.tag != constantClass {
+		return ""
+	}
+
+	return c.utf8(constant.first)
+}
+
+// memberRef returns the class, the name and the descriptor of the field or method reference at the given index
+func (c *jvmClass) memberRef(index uint16) (string, string, string) {
+	constant := c.constant(index)
+	switch constant.tag {
+	case constantFieldref, constantMethodref, constantInterfaceMethodref:
+	default:
+		return "", "", ""
+	}
+	nameAndType := c.constant(constant.second)
+
+	return c.className(constant.first), c.utf8(nameAndType.first), c.utf8(nameAndType.second)
+}
+
+// instruction is an instruction of the bytecode of a method, with the operand referencing the constant pool if any
+type instruction struct {
+	pc      int
+	opcode  byte
+	operand uint16
+}
+
+// instructions returns the instructions of the bytecode of a method, stopping at the first invalid one
+func (m jvmMethod) instructions() []instruction {
+	var instructions []instruction
+	code := m.code
+
+	for pc := 0; pc < len(code); {
+		opcode := code[pc]
+		size := jvmOperandSizes[opcode]
+		switch opcode {
+		case opTableSwitch, opLookupSwitch:
+			// The operands of the switches are aligned on 4 bytes, after a default offset and the bounds or pairs count
+			start := (pc + 4) &^ 3
+			if start+12 > len(code) {
+				return instructions
+			}
+			if opcode == opTableSwitch {
+				low := int32(binary.BigEndian.Uint32(code[start+4:]))  //nolint:gosec // offsets are signed
+				high := int32(binary.BigEndian.Uint32(code[start+8:])) //nolint:gosec // offsets are signed
+				size = start - pc - 1 + 12 + int(high-low+1)*4
+			} else {
+				pairs := int32(binary.BigEndian.Uint32(code[start+4:])) //nolint:gosec // counts are signed
+				size = start - pc - 1 + 8 + int(pairs)*8
+			}
+		case opWide:
+			size = 3
+			if pc+1 < len(code) && code[pc+1] == opIinc {
+				size = 5
+			}
+		}
+		if size < 0 || pc+1+size > len(code) {
+			return instructions
+		}
+
+		current := instruction{pc: pc, opcode: opcode}
+		switch {
+		case opcode == opLdc:
+			current.operand = uint16(code[pc+1])
+		case size >= 2 && opcode != opWide:
+			current.operand = binary.BigEndian.Uint16(code[pc+1:])
+		}
+		instructions = append(instructions, current)
+		pc += 1 + size
+	}
+
+	return instructions
+}
+
+// line returns the source line of the instruction at the given offset, or 0 when the method has no line numbers
+func (m jvmMethod) line(pc int) int {
+	line, start := 0, -1
+	for _, entry := range m.lines {
+		if entry.pc <= pc && entry.pc > start {
+			line, start = entry.line, entry.pc
+		}
+	}
+
+	return line
+}
+
+// descriptorClasses returns the binary names of the classes of a field or method descriptor,
+// such as java/lang/String for (Ljava/lang/String;)V
+func descriptorClasses(descriptor string) []string {
+	var classes []string
+	for {
+		start := strings.IndexByte(descriptor, 'L')
+		if start < 0 {
+			return classes
+		}
+		end := strings.IndexByte(descriptor[start:], ';')
+		if end < 0 {
+			return classes
+		}
+		classes = append(classes, descriptor[start+1:start+end])
+		descriptor = descriptor[start+end+1:]
+	}
+}
+
+// javaClassName converts the binary name of a class, or the descriptor of an array of classes, to its Java name,
+// such as com.sample.App.Inner for com/sample/App$Inner
+func javaClassName(binaryName string) string {
+	if strings.HasPrefix(binaryName, "[") {
+		classes := descriptorClasses(binaryName)
+		if len(classes) == 0 {
+			return ""
+		}
+		binaryName = classes[0]
+	}
+
+	return strings.ReplaceAll(strings.ReplaceAll(binaryName, "/", "."), "$", ".")
+}
@@ -0,0 +1,328 @@
+package codefile
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"unsafe"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// jvmSymbolMatcher checks whether the captures of a query match a symbol,
+// and returns the first and last nodes of the code to report when they do
+type jvmSymbolMatcher func(file *jvmFile, captures map[string]*treesitter.Node, s models.Symbols) (*treesitter.Node, *treesitter.Node, bool)
+
+// jvmSymbolTypeToMatcher holds the matchers of the symbols of Maven packages detected in Kotlin and Scala files.
+// They are the ones of Java, where the Value of "class" and "type_reference" symbols is the package of the class
+// and their Name its name, while the Value of "method" and "static_method" symbols is the fully qualified name
+// of the class and their Name the name of the method.
+var jvmSymbolTypeToMatcher = map[string]jvmSymbolMatcher{
+	"class":          matchJVMClass,
+	"method":         matchJVMMember(false),
+	"static_method":  matchJVMMember(true),
+	"type_reference": matchJVMTypeReference,
+}
+
+// jvmDialect describes a JVM language other than Java, whose queries capture the same names as the ones of the other
+// dialects: "class" for the classes of the object creations, "function", "object" and "name" for the calls of methods,
+// with "type_arguments" when they are not part of the function, and "name" for the dotted names of the code.
+type jvmDialect struct {
+	name     string
+	language func() unsafe.Pointer
+	// symbolTypeToTSQuery holds the queries of the symbol types
+	symbolTypeToTSQuery map[string]string
+	// queries holds the queries indexing the names, packages, imports and declarations of files
+	queries map[string]string
+	// nameOf reads the dotted name of a node, returning false when it is not a name
+	nameOf func(source *SourceContext, node *treesitter.Node) (jvmName, bool)
+	// isNameRoot returns whether a node is a whole dotted name rather than a part of one, or the name of a declaration
+	isNameRoot func(node *treesitter.Node) bool
+	// newScope reads the packages, imports and declarations of a file
+	newScope func(source *SourceContext, tsQueries map[string]*treesitter.Query) *jvmScope
+}
+
+// jvmSourceDetector detects the usages of the vulnerable symbols of Maven packages in the files of a dialect
+type jvmSourceDetector struct {
+	dialect                *jvmDialect
+	tsParser               *treesitter.Parser
+	tsQueriesPerSymbolType map[string]*treesitter.Query
+	tsQueries              map[string]*treesitter.Query
+}
+
+// jvmScope holds the names brought in scope by the package and import declarations of a Kotlin or Scala file
+type jvmScope struct {
+	// packages lists the packages of the file, Scala files chaining package clauses being in several packages
+	packages []string
+	// imports maps the names imported by `import a.b.C`, or their aliases, to their fully qualified name,
+	// which can be a class or a member of a class
+	imports map[string]string
+	// wildcards lists the packages and classes whose members are all imported, including the default imports
+	wildcards []string
+	// declared holds the simple names of the classes declared by the file, which shadow the wildcard imports
+	declared map[string]bool
+}
+
+// jvmName is a dotted name of the code, such as com.fasterxml.jackson.databind.ObjectMapper
+type jvmName struct {
+	segments []string
+	// nodes holds the nodes of the segments, without their type arguments
+	nodes []*treesitter.Node
+}
+
+// jvmFile is a parsed Kotlin or Scala file, which lazily indexes the classes it references
+type jvmFile struct {
+	*SourceContext
+
+	dialect *jvmDialect
+	scope   *jvmScope
+	names   []jvmName
+
+	references map[string]bool
+}
+
+func newJVMScope(defaultImports []string) *jvmScope {
+	return &jvmScope{
+		imports:   make(map[string]string),
+		wildcards: slices.Clone(defaultImports),
+		declared:  make(map[string]bool),
+	}
+}
+
+// newJVMSourceDetector creates the parser and the queries of a dialect, Close should be called once all the files are parsed
+func newJVMSourceDetector(dialect *jvmDialect) (jvmSourceDetector, error) {
+	tsLanguage := treesitter.NewLanguage(dialect.language())
+
+	tsParser := treesitter.NewParser()
+
+	err := tsParser.SetLanguage(tsLanguage)
+	if err != nil {
+		return jvmSourceDetector{}, fmt.Errorf("failed to set tree-sitter %s language on parser: %w", dialect.name, err)
+	}
+
+	tsQueriesPerSymbolType, err := newQueries(tsLanguage, dialect.symbolTypeToTSQuery)
+	if err != nil {
+		return jvmSourceDetector{}, err
+	}
+	tsQueries, err := newQueries(tsLanguage, dialect.queries)
+	if err != nil {
+		closeQueries(tsQueriesPerSymbolType)
+
+		return jvmSourceDetector{}, err
+	}
+
+	return jvmSourceDetector{
+		dialect:                dialect,
+		tsParser:               tsParser,
+		tsQueriesPerSymbolType: tsQueriesPerSymbolType,
+		tsQueries:              tsQueries,
+	}, nil
+}
+
+// Close closes all hanging tree-sitter related resources.
+func (r *jvmSourceDetector) Close() {
+	r.tsParser.Close()
+	closeQueries(r.tsQueriesPerSymbolType)
+	closeQueries(r.tsQueries)
+}
+
+func (r *jvmSourceDetector) Detect(dir string, path string, detectionResults models.DetectionResults, advisoriesToCheck []models.AdvisoryToCheck) error {
+	source, err := NewSourceContext(r.tsParser, dir, path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	file := &jvmFile{
+		SourceContext: source,
+		dialect:       r.dialect,
+		scope:         r.dialect.newScope(source, r.tsQueries),
+		references:    make(map[string]bool),
+	}
+	for _, node := range source.Captures(r.tsQueries["names"]) {
+		if name, ok := r.dialect.nameOf(source, node); ok && r.dialect.isNameRoot(node) {
+			file.names = append(file.names, name)
+		}
+	}
+
+	for _, advisoryToCheck := range advisoriesToCheck {
+		for _, s := range advisoryToCheck.Symbols {
+			query := r.tsQueriesPerSymbolType[s.Type]
+			if query == nil {
+				log.Printf("No query found for symbol type %s\n", s.Type)
+				continue
+			}
+			matcher := jvmSymbolTypeToMatcher[s.Type]
+
+			var hits [][2]*treesitter.Node
+			source.Matches(query, func(captures map[string]*treesitter.Node) {
+				if from, to, ok := matcher(file, captures, s); ok {
+					hits = append(hits, [2]*treesitter.Node{from, to})
+				}
+			})
+
+			for _, hit := range hits {
+				if err := source.Report(detectionResults, advisoryToCheck, hit[0], hit[1]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func matchJVMClass(file *jvmFile, captures map[string]*treesitter.Node, s models.Symbols) (*treesitter.Node, *treesitter.Node, bool) {
+	name, ok := file.dialect.nameOf(file.SourceContext, captures["class"])
+	if !ok {
+		return nil, nil, false
+	}
+
+	return name.nodes[0], name.nodes[len(name.nodes)-1], file.scope.refersTo(name.segments, s.Value+"."+s.Name)
+}
+
+// matchJVMMember matches the calls of methods. Static methods must be qualified by their class or imported,
+// while instance methods are matched by name in the files referencing their class, as their objects are not typed.
+func matchJVMMember(static bool) jvmSymbolMatcher {
+	return func(file *jvmFile, captures map[string]*treesitter.Node, s models.Symbols) (*treesitter.Node, *treesitter.Node, bool) {
+		name := captures["name"]
+		if file.Text(name) != s.Name {
+			return nil, nil, false
+		}
+
+		function := captures["function"]
+		end := function
+		if typeArguments := captures["type_arguments"]; typeArguments != nil {
+			end = typeArguments
+		}
+
+		object := captures["object"]
+		if objectName, ok := file.dialect.nameOf(file.SourceContext, object); ok && file.scope.refersTo(objectName.segments, s.Value) {
+			return function, end, true
+		}
+
+		if static {
+			return name, end, object == nil && file.scope.importsMember(s.Value, s.Name)
+		}
+
+		return name, end, file.referencesClass(s.Value)
+	}
+}
+
+func matchJVMTypeReference(file *jvmFile, captures map[string]*treesitter.Node, s models.Symbols) (*treesitter.Node, *treesitter.Node, bool) {
+	node := captures["name"]
+	if !file.dialect.isNameRoot(node) {
+		return nil, nil, false
+	}
+	name, ok := file.dialect.nameOf(file.SourceContext, node)
+	if !ok {
+		return nil, nil, false
+	}
+
+	// Only the longest prefix of the name which is a class is reported, e.g. com.example.Greeter
+	for i := len(name.segments) - 1; i >= 0; i-- {
+		if file.scope.refersTo(name.segments[:i+1], s.Value+"."+s.Name) {
+			return name.nodes[0], name.nodes[i], true
+		}
+	}
+
+	return nil, nil, false
+}
+
+// referencesClass returns whether the file imports or uses the given class
+func (f *jvmFile) referencesClass(qualifiedName string) bool {
+	if referenced, ok := f.references[qualifiedName]; ok {
+		return referenced
+	}
+
+	referenced := slices.Contains(f.scope.wildcards, qualifiedName)
+	for _, target := range f.scope.imports {
+		referenced = referenced || target == qualifiedName || strings.HasPrefix(target, qualifiedName+".")
+	}
+	referenced = referenced || slices.ContainsFunc(f.names, func(name jvmName) bool {
+		for i := range name.segments {
+			if f.scope.refersTo(name.segments[:i+1], qualifiedName) {
+				return true
+			}
+		}
+
+		return false
+	})
+	f.references[qualifiedName] = referenced
+
+	return referenced
+}
+
+// addImport records the import of a fully qualified name, under its alias if any
+func (s *jvmScope) addImport(segments []string, alias string) {
+	if len(segments) == 0 {
+		return
+	}
+	if alias == "" {
+		alias = segments[len(segments)-1]
+	}
+
+	s.imports[alias] = strings.Join(segments, ".")
+}
+
+// refersTo returns whether a dotted name can refer to the given fully qualified name, directly,
+// through an import or an alias, or as a member of the packages of the file or of a wildcard import.
+// As the classes of the other files are not known, a name can refer to the members of several packages.
+func (s *jvmScope) refersTo(segments []string, qualifiedName string) bool {
+	if len(segments) == 0 {
+		return false
+	}
+
+	name := strings.Join(segments, ".")
+	if name == qualifiedName {
+		return true
+	}
+	if target, ok := s.imports[segments[0]]; ok {
+		return strings.Join(append([]string{target}, segments[1:]...), ".") == qualifiedName
+	}
+
+	for _, pkg := range s.packages {
+		if pkg+"."+name == qualifiedName {
+			return true
+		}
+	}
+	if s.declared[segments[0]] {
+		return false
+	}
+	for _, wildcard := range s.wildcards {
+		if wildcard+"."+name == qualifiedName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// importsMember returns whether a member of the given class can be used without being qualified by its class
+func (s *jvmScope) importsMember(qualifiedClassName string, member string) bool {
+	if target, ok := s.imports[member]; ok {
+		return target == qualifiedClassName+"."+member
+	}
+
+	return slices.Contains(s.wildcards, qualifiedClassName)
+}
+
+// isDeclarationName returns whether a node is the name of the declaration holding it, in the given field
+func isDeclarationName(node *treesitter.Node, field string) bool {
+	name := node.Parent().ChildByFieldName(field)
+
+	return name != nil && name.StartByte() == node.StartByte() && name.EndByte() == node.EndByte()
+}
+
+// identifierTexts returns the texts of the named children of a node, such as the segments of a qualified identifier
+func identifierTexts(source *SourceContext, node *treesitter.Node) []string {
+	var texts []string
+	for i := range node.NamedChildCount() {
+		texts = append(texts, source.Text(node.NamedChild(i)))
+	}
+
+	return texts
+}
@@ -0,0 +1,215 @@
+package codefile
+
+import (
+	"strings"
+
+	tree_sitter_kotlin "github.com/tree-sitter-grammars/tree-sitter-kotlin/bindings/go"
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// As Kotlin creates objects without the new keyword, the calls of a class are its object creations
+var tsQueryForKotlinClass = `
+(call_expression
+	.
+	[
+		(identifier)
+		(navigation_expression)
+	] @class
+)`
+
+// Methods can be called without object when they are inherited or imported
+var tsQueryForKotlinMethod = `
+(call_expression
+	.
+	[
+		(identifier) @name
+		(navigation_expression
+			.
+			(_) @object
+			(identifier) @name
+			.
+		)
+	] @function
+	.
+	(type_arguments)? @type_arguments
+)`
+
+var tsQueryForKotlinName = `
+[
+	(identifier)
+	(navigation_expression)
+	(user_type)
+] @name`
+
+var tsQueryForKotlinPackage = `
+(package_header
+	(qualified_identifier) @package
+)`
+
+var tsQueryForKotlinImport = `(import) @import`
+
+var tsQueryForKotlinDeclaration = `
+[
+	(class_declaration name: (identifier) @name)
+	(object_declaration name: (identifier) @name)
+	(type_alias type: (identifier) @name)
+]`
+
+// kotlinDefaultImports lists the packages imported by default in every Kotlin file targeting the JVM
+var kotlinDefaultImports = []string{
+	"kotlin", "kotlin.annotation", "kotlin.collections", "kotlin.comparisons", "kotlin.io", "kotlin.ranges",
+	"kotlin.sequences", "kotlin.text", "kotlin.jvm", "java.lang",
+}
+
+var kotlinDialect = &jvmDialect{
+	name:     "Kotlin",
+	language: tree_sitter_kotlin.Language,
+	symbolTypeToTSQuery: map[string]string{
+		"class":          tsQueryForKotlinClass,
+		"method":         tsQueryForKotlinMethod,
+		"static_method":  tsQueryForKotlinMethod,
+		"type_reference": tsQueryForKotlinName,
+	},
+	queries: map[string]string{
+		"names":        tsQueryForKotlinName,
+		"packages":     tsQueryForKotlinPackage,
+		"imports":      tsQueryForKotlinImport,
+		"declarations": tsQueryForKotlinDeclaration,
+	},
+	nameOf:     kotlinNameOf,
+	isNameRoot: isKotlinNameRoot,
+	newScope:   newKotlinScope,
+}
+
+// ReachabilityKotlin detects the usages of the vulnerable symbols of Maven packages in Kotlin files and scripts,
+// with the symbol types of Java. As Kotlin creates objects without the new keyword, the calls of the constructors
+// of a class are its calls by name.
+type ReachabilityKotlin struct {
+	jvmSourceDetector
+}
+
+// NewKotlinReachableDetector creates a detector for Kotlin files, Close should be called once all the files are parsed
+func NewKotlinReachableDetector() (*ReachabilityKotlin, error) {
+	detector, err := newJVMSourceDetector(kotlinDialect)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReachabilityKotlin{jvmSourceDetector: detector}, nil
+}
+
+// kotlinNameOf reads the dotted name of an identifier, a type or a chain of member accesses, which is not a name
+// when it involves anything else than identifiers, such as a call, `this` or a safe call (a?.b)
+func kotlinNameOf(source *SourceContext, node *treesitter.Node) (jvmName, bool) {
+	if node == nil {
+		return jvmName{}, false
+	}
+
+	switch node.Kind() {
+	case "identifier":
+		return jvmName{segments: []string{source.Text(node)}, nodes: []*treesitter.Node{node}}, true
+	case "user_type":
+		var name jvmName
+		for i := range node.NamedChildCount() {
+			if child := node.NamedChild(i); child.Kind() == "identifier" {
+				name.segments = append(name.segments, source.Text(child))
+				name.nodes = append(name.nodes, child)
+			}
+		}
+
+		return name, len(name.segments) > 0
+	case "navigation_expression":
+		member := node.NamedChild(node.NamedChildCount() - 1)
+		if kotlinNavigationOperator(node) != "." || member == nil || member.Kind() != "identifier" {
+			return jvmName{}, false
+		}
+		name, ok := kotlinNameOf(source, node.NamedChild(0))
+		if !ok {
+			return jvmName{}, false
+		}
+		name.segments = append(name.segments, source.Text(member))
+		name.nodes = append(name.nodes, member)
+
+		return name, true
+	}
+
+	return jvmName{}, false
+}
+
+// kotlinNavigationOperator returns the operator of a navigation expression, such as . ?. or ::
+func kotlinNavigationOperator(node *treesitter.Node) string {
+	for i := range node.ChildCount() {
+		if child := node.Child(i); !child.IsNamed() {
+			return child.Kind()
+		}
+	}
+
+	return ""
+}
+
+// isKotlinNameRoot returns whether a node is a whole dotted name, outside of the package, imports, annotations
+// and names of the declarations
+func isKotlinNameRoot(node *treesitter.Node) bool {
+	parent := node.Parent()
+	if parent == nil {
+		return true
+	}
+
+	switch parent.Kind() {
+	case "navigation_expression":
+		// The receivers of safe calls (a?.b) and of references (A::b) are names on their own
+		return parent.NamedChild(0).StartByte() == node.StartByte() && kotlinNavigationOperator(parent) != "." &&
+			parent.NamedChild(parent.NamedChildCount()-1).StartByte() != node.StartByte()
+	case "user_type", "qualified_identifier", "import", "package_header", "annotation", "file_annotation":
+		return false
+	case "constructor_invocation":
+		grandparent := parent.Parent()
+
+		return grandparent == nil || (grandparent.Kind() != "annotation" && grandparent.Kind() != "file_annotation")
+	case "class_declaration", "object_declaration", "function_declaration":
+		return !isDeclarationName(node, "name")
+	case "type_alias":
+		return !isDeclarationName(node, "type")
+	}
+
+	return true
+}
+
+// newKotlinScope reads the package, the imports, with their aliases (import a.b.C as D), and the declarations of a file
+func newKotlinScope(source *SourceContext, tsQueries map[string]*treesitter.Query) *jvmScope {
+	scope := newJVMScope(kotlinDefaultImports)
+
+	for _, node := range source.Captures(tsQueries["packages"]) {
+		scope.packages = append(scope.packages, strings.Join(identifierTexts(source, node), "."))
+	}
+
+	for _, node := range source.Captures(tsQueries["imports"]) {
+		var segments []string
+		var alias string
+		wildcard := false
+		for i := range node.ChildCount() {
+			switch child := node.Child(i); child.Kind() {
+			case "qualified_identifier":
+				segments = identifierTexts(source, child)
+			case "identifier":
+				alias = source.Text(child)
+			case "*":
+				wildcard = true
+			}
+		}
+
+		if wildcard {
+			scope.wildcards = append(scope.wildcards, strings.Join(segments, "."))
+		} else {
+			scope.addImport(segments, alias)
+		}
+	}
+
+	for _, node := range source.Captures(tsQueries["declarations"]) {
+		scope.declared[source.Text(node)] = true
+	}
+
+	return scope
+}
+
+var _ Detector = &ReachabilityKotlin{}
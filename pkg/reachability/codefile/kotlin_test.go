@@ -0,0 +1,141 @@
+package codefile
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewKotlinReachableDetector(t *testing.T) {
+	t.Parallel()
+	detector, err := NewKotlinReachableDetector()
+	require.NoError(t, err)
+	defer detector.Close()
+
+	assert.NotNil(t, detector)
+}
+
+func Test_DetectKotlin(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		symbol   models.Symbols
+		expected []string
+	}{
+		{
+			name:     "constructor call without new",
+			symbol:   models.Symbols{Type: "class", Value: "com.fasterxml.jackson.databind", Name: "ObjectMapper"},
+			expected: []string{"ObjectMapper"},
+		},
+		{
+			name:     "constructor call through an import alias",
+			symbol:   models.Symbols{Type: "class", Value: "org.yaml.snakeyaml", Name: "Yaml"},
+			expected: []string{"SnakeYaml"},
+		},
+		{
+			name:     "constructor call with a fully qualified name",
+			symbol:   models.Symbols{Type: "class", Value: "com.google.gson", Name: "Gson"},
+			expected: []string{"com.google.gson.Gson"},
+		},
+		{
+			name:     "constructor call of a class declared by the file",
+			symbol:   models.Symbols{Type: "class", Value: "com.example.app", Name: "Config"},
+			expected: []string{"Config"},
+		},
+		{
+			name:     "class shadowed by a class declared by the file",
+			symbol:   models.Symbols{Type: "class", Value: "org.apache.commons.collections4", Name: "Config"},
+			expected: nil,
+		},
+		{
+			name:     "instance method calls in a file using the class",
+			symbol:   models.Symbols{Type: "method", Value: "com.fasterxml.jackson.databind.ObjectMapper", Name: "writeValueAsString"},
+			expected: []string{"writeValueAsString"},
+		},
+		{
+			name:     "instance method call with type arguments",
+			symbol:   models.Symbols{Type: "method", Value: "org.yaml.snakeyaml.Yaml", Name: "load"},
+			expected: []string{"load<Map<String, Any>>"},
+		},
+		{
+			name:     "static method call of a class of a wildcard import",
+			symbol:   models.Symbols{Type: "static_method", Value: "org.apache.commons.collections4.CollectionUtils", Name: "emptyCollection"},
+			expected: []string{"CollectionUtils.emptyCollection<String>"},
+		},
+		{
+			name:     "imported static method",
+			symbol:   models.Symbols{Type: "static_method", Value: "org.apache.commons.text.StringSubstitutor", Name: "replaceSystemProperties"},
+			expected: []string{"replaceSystemProperties"},
+		},
+		{
+			name:     "static method of a class which is not imported",
+			symbol:   models.Symbols{Type: "static_method", Value: "org.apache.commons.text.StringSubstitutor", Name: "createInterpolator"},
+			expected: nil,
+		},
+		{
+			name:     "type reference outside of comments and imports",
+			symbol:   models.Symbols{Type: "type_reference", Value: "com.fasterxml.jackson.databind", Name: "ObjectMapper"},
+			expected: []string{"ObjectMapper"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			detector, err := NewKotlinReachableDetector()
+			require.NoError(t, err)
+			defer detector.Close()
+
+			advisoriesToCheck := []models.AdvisoryToCheck{{Purl: "pkg:maven/com.example/example@1.0.0", AdvisoryID: "GHSA-kotlin", Symbols: []models.Symbols{tc.symbol}}}
+			detectionResults := models.DetectionResults{}
+			err = detector.Detect("testdata/kotlin", "testdata/kotlin/App.kt", detectionResults, advisoriesToCheck)
+			require.NoError(t, err)
+
+			var found []string
+			for _, location := range detectionResults["pkg:maven/com.example/example@1.0.0"]["GHSA-kotlin"] {
+				found = append(found, location.Symbol)
+			}
+			assert.Equal(t, tc.expected, found)
+		})
+	}
+}
+
+func Test_DetectKotlin_Location(t *testing.T) {
+	t.Parallel()
+	detector, err := NewKotlinReachableDetector()
+	require.NoError(t, err)
+	defer detector.Close()
+
+	advisoriesToCheck := []models.AdvisoryToCheck{
+		{
+			Purl:       "pkg:maven/org.yaml/snakeyaml@1.33",
+			AdvisoryID: "GHSA-mjmj-j48q-9wg2",
+			Symbols:    []models.Symbols{{Type: "class", Value: "org.yaml.snakeyaml", Name: "Yaml"}},
+		},
+	}
+	detectionResults := models.DetectionResults{}
+	err = detector.Detect("testdata/kotlin", "testdata/kotlin/App.kt", detectionResults, advisoriesToCheck)
+	require.NoError(t, err)
+
+	assert.Equal(t, models.DetectionResults{
+		"pkg:maven/org.yaml/snakeyaml@1.33": {
+			"GHSA-mjmj-j48q-9wg2": {
+				{
+					Symbol: "SnakeYaml",
+					PackageLocation: models.PackageLocation{
+						Filename:    "testdata/kotlin/App.kt",
+						LineStart:   19,
+						LineEnd:     19,
+						ColumnStart: 16,
+						ColumnEnd:   25,
+					},
+				},
+			},
+		},
+	}, detectionResults)
+}
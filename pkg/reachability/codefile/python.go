@@ -0,0 +1,276 @@
+package codefile
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+	tree_sitter_python "github.com/tree-sitter/tree-sitter-python/bindings/go"
+)
+
+var tsQueryForPythonImport = `
+[
+	(import_statement)
+	(import_from_statement)
+] @import`
+
+var tsQueryForPythonReference = `
+[
+	(identifier)
+	(attribute)
+] @reference`
+
+// ReachabilityPython detects the usages of the vulnerable symbols of PyPI packages in Python files.
+// The Value of symbols is the module defining them (e.g. "yaml") and their Name is the name of the function,
+// class or attribute (e.g. "load"); for "method" symbols, Value is the qualified name of the class instead.
+type ReachabilityPython struct {
+	tsParser  *treesitter.Parser
+	tsQueries map[string]*treesitter.Query
+}
+
+// NewPythonReachableDetector creates a detector for Python files, Close should be called once all the files are parsed
+func NewPythonReachableDetector() (*ReachabilityPython, error) {
+	tsLanguage := treesitter.NewLanguage(tree_sitter_python.Language())
+
+	tsParser := treesitter.NewParser()
+
+	err := tsParser.SetLanguage(tsLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set tree-sitter Python language on parser: %w", err)
+	}
+
+	tsQueries, err := newQueries(tsLanguage, map[string]string{
+		"imports":    tsQueryForPythonImport,
+		"references": tsQueryForPythonReference,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReachabilityPython{
+		tsParser:  tsParser,
+		tsQueries: tsQueries,
+	}, nil
+}
+
+// Close closes all hanging tree-sitter related resources.
+func (r *ReachabilityPython) Close() {
+	r.tsParser.Close()
+	closeQueries(r.tsQueries)
+}
+
+// pythonReference is an identifier or an attribute of the code, along with the qualified names it can refer to
+type pythonReference struct {
+	node           *treesitter.Node
+	qualifiedNames []string
+	called         bool
+}
+
+func (r *ReachabilityPython) Detect(dir string, path string, detectionResults models.DetectionResults, advisoriesToCheck []models.AdvisoryToCheck) error {
+	source, err := NewSourceContext(r.tsParser, dir, path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	scope := newPythonScope(source, source.Captures(r.tsQueries["imports"]))
+	if scope.isEmpty() {
+		// Nothing can refer to the symbols of packages which are not imported
+		return nil
+	}
+
+	var references []pythonReference
+	for _, node := range source.Captures(r.tsQueries["references"]) {
+		if isPythonDeclaration(node) {
+			continue
+		}
+		if qualifiedNames := scope.resolve(source, node); len(qualifiedNames) > 0 {
+			references = append(references, pythonReference{node: node, qualifiedNames: qualifiedNames, called: isPythonCallee(node)})
+		}
+	}
+
+	for _, advisoryToCheck := range advisoriesToCheck {
+		for _, s := range advisoryToCheck.Symbols {
+			for _, reference := range references {
+				node, ok := matchPythonSymbol(source, references, reference, s)
+				if !ok {
+					continue
+				}
+				if err := source.Report(detectionResults, advisoryToCheck, node, node); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchPythonSymbol returns the node to report when a reference uses a symbol
+func matchPythonSymbol(source *SourceContext, references []pythonReference, reference pythonReference, s models.Symbols) (*treesitter.Node, bool) {
+	qualifiedName := strings.TrimPrefix(s.Value+"."+s.Name, ".")
+
+	switch s.Type {
+	case "function", "class":
+		return reference.node, reference.called && slices.Contains(reference.qualifiedNames, qualifiedName)
+	case "attribute":
+		return reference.node, slices.Contains(reference.qualifiedNames, qualifiedName)
+	case "method":
+		// Objects are not typed, so methods are matched by name in the files using their class
+		attribute := reference.node.ChildByFieldName("attribute")
+		if !reference.called || reference.node.Kind() != "attribute" || source.Text(attribute) != s.Name {
+			return nil, false
+		}
+		usesClass := slices.ContainsFunc(references, func(other pythonReference) bool {
+			return slices.Contains(other.qualifiedNames, s.Value)
+		})
+
+		return attribute, usesClass
+	default:
+		log.Printf("No query found for symbol type %s\n", s.Type)
+	}
+
+	return nil, false
+}
+
+// isPythonCallee returns whether the node is the function of a call
+func isPythonCallee(node *treesitter.Node) bool {
+	parent := node.Parent()
+	if parent == nil || parent.Kind() != "call" {
+		return false
+	}
+	function := parent.ChildByFieldName("function")
+
+	return function != nil && function.Id() == node.Id()
+}
+
+// isPythonDeclaration returns whether the node is not a reference, but a name declared by the code
+// such as an import, a parameter or an attribute name, which cannot be resolved on its own
+func isPythonDeclaration(node *treesitter.Node) bool {
+	parent := node.Parent()
+	if parent == nil {
+		return false
+	}
+
+	switch parent.Kind() {
+	case "attribute":
+		attribute := parent.ChildByFieldName("attribute")
+		return attribute != nil && attribute.Id() == node.Id()
+	case "keyword_argument", "function_definition", "class_definition", "default_parameter", "typed_parameter", "typed_default_parameter":
+		name := parent.ChildByFieldName("name")
+		return (name != nil && name.Id() == node.Id()) || parent.Kind() == "typed_parameter"
+	case "parameters", "lambda_parameters", "dotted_name", "aliased_import", "import_statement", "import_from_statement":
+		return true
+	}
+
+	return false
+}
+
+// pythonScope maps the names bound by the imports of a Python file to the qualified names they refer to
+type pythonScope struct {
+	// bindings maps the names bound by imports to the qualified names they refer to
+	bindings map[string]string
+	// starModules lists the modules imported with `from module import *`
+	starModules []string
+}
+
+func newPythonScope(source *SourceContext, imports []*treesitter.Node) *pythonScope {
+	scope := &pythonScope{bindings: make(map[string]string)}
+
+	for _, node := range imports {
+		switch node.Kind() {
+		case "import_statement":
+			for i := range node.NamedChildCount() {
+				scope.addImport(source, node.NamedChild(i), "")
+			}
+		case "import_from_statement":
+			module := node.ChildByFieldName("module_name")
+			// Relative imports refer to the code of the project itself
+			if module == nil || module.Kind() != "dotted_name" {
+				continue
+			}
+			for i := range node.NamedChildCount() {
+				child := node.NamedChild(i)
+				switch {
+				case child.Id() == module.Id():
+					continue
+				case child.Kind() == "wildcard_import":
+					scope.starModules = append(scope.starModules, source.Text(module))
+				default:
+					scope.addImport(source, child, source.Text(module))
+				}
+			}
+		}
+	}
+
+	return scope
+}
+
+// addImport binds the name imported by a `dotted_name` or an `aliased_import` node, from a module when it is given
+func (s *pythonScope) addImport(source *SourceContext, node *treesitter.Node, module string) {
+	name, alias := node, node
+	if node.Kind() == "aliased_import" {
+		name, alias = node.ChildByFieldName("name"), node.ChildByFieldName("alias")
+	}
+	if name == nil || alias == nil || name.Kind() != "dotted_name" {
+		return
+	}
+
+	qualifiedName := source.Text(name)
+	if module != "" {
+		qualifiedName = module + "." + qualifiedName
+	}
+
+	switch {
+	case alias != name:
+		s.bindings[source.Text(alias)] = qualifiedName
+	case module != "":
+		s.bindings[source.Text(name)] = qualifiedName
+	default:
+		// `import a.b.c` binds `a`, and the code refers to `a.b.c` through it
+		root, _, _ := strings.Cut(qualifiedName, ".")
+		s.bindings[root] = root
+	}
+}
+
+func (s *pythonScope) isEmpty() bool {
+	return len(s.bindings) == 0 && len(s.starModules) == 0
+}
+
+// resolve returns the qualified names an identifier or an attribute can refer to, or nothing for local names
+func (s *pythonScope) resolve(source *SourceContext, node *treesitter.Node) []string {
+	switch node.Kind() {
+	case "identifier":
+		name := source.Text(node)
+		if qualifiedName, ok := s.bindings[name]; ok {
+			return []string{qualifiedName}
+		}
+
+		qualifiedNames := make([]string, 0, len(s.starModules))
+		for _, module := range s.starModules {
+			qualifiedNames = append(qualifiedNames, module+"."+name)
+		}
+
+		return qualifiedNames
+	case "attribute":
+		object, attribute := node.ChildByFieldName("object"), node.ChildByFieldName("attribute")
+		if object == nil || attribute == nil {
+			return nil
+		}
+
+		qualifiedNames := s.resolve(source, object)
+		for i := range qualifiedNames {
+			qualifiedNames[i] += "." + source.Text(attribute)
+		}
+
+		return qualifiedNames
+	}
+
+	return nil
+}
+
+var _ Detector = &ReachabilityPython{}
@@ -0,0 +1,88 @@
+package codefile
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewPythonReachableDetector(t *testing.T) {
+	t.Parallel()
+	detector, err := NewPythonReachableDetector()
+	require.NoError(t, err)
+	defer detector.Close()
+
+	assert.NotNil(t, detector)
+}
+
+func Test_DetectPython(t *testing.T) {
+	t.Parallel()
+	detector, err := NewPythonReachableDetector()
+	require.NoError(t, err)
+	defer detector.Close()
+
+	symbols := map[models.Symbols][]string{
+		{Type: "function", Value: "yaml", Name: "load"}:                                     {"yaml.load"},
+		{Type: "attribute", Value: "yaml", Name: "FullLoader"}:                              {"yaml.FullLoader"},
+		{Type: "function", Value: "xml.etree.ElementTree", Name: "parse"}:                   {"xml.etree.ElementTree.parse"},
+		{Type: "function", Value: "requests", Name: "get"}:                                  {"http.get"},
+		{Type: "class", Value: "jinja2", Name: "Template"}:                                  {"T"},
+		{Type: "function", Value: "django.utils.html", Name: "format_html"}:                 {"format_html"},
+		{Type: "method", Value: "jinja2.Environment", Name: "from_string"}:                  {"from_string"},
+		{Type: "function", Value: "yaml", Name: "safe_load"}:                                nil,
+		{Type: "method", Value: "jinja2.sandbox.SandboxedEnvironment", Name: "from_string"}: nil,
+		// The attribute is not called
+		{Type: "function", Value: "yaml", Name: "FullLoader"}: nil,
+	}
+
+	for symbol, expected := range symbols {
+		advisoriesToCheck := []models.AdvisoryToCheck{{Purl: "pkg:pypi/example@1.0.0", AdvisoryID: "GHSA-python", Symbols: []models.Symbols{symbol}}}
+		detectionResults := models.DetectionResults{}
+		err = detector.Detect(".", "testdata/python/app.py", detectionResults, advisoriesToCheck)
+		require.NoError(t, err)
+
+		var found []string
+		for _, location := range detectionResults["pkg:pypi/example@1.0.0"]["GHSA-python"] {
+			found = append(found, location.Symbol)
+		}
+		assert.Equal(t, expected, found, symbol)
+	}
+}
+
+func Test_DetectPython_Location(t *testing.T) {
+	t.Parallel()
+	detector, err := NewPythonReachableDetector()
+	require.NoError(t, err)
+	defer detector.Close()
+
+	advisoriesToCheck := []models.AdvisoryToCheck{
+		{
+			Purl:       "pkg:pypi/pyyaml@5.3",
+			AdvisoryID: "GHSA-8q59-q68h-6hv4",
+			Symbols:    []models.Symbols{{Type: "function", Value: "yaml", Name: "load"}},
+		},
+	}
+	detectionResults := models.DetectionResults{}
+	err = detector.Detect(".", "testdata/python/app.py", detectionResults, advisoriesToCheck)
+	require.NoError(t, err)
+
+	assert.Equal(t, models.DetectionResults{
+		"pkg:pypi/pyyaml@5.3": {
+			"GHSA-8q59-q68h-6hv4": {
+				{
+					Symbol: "yaml.load",
+					PackageLocation: models.PackageLocation{
+						Filename:    "testdata/python/app.py",
+						LineStart:   8,
+						LineEnd:     8,
+						ColumnStart: 10,
+						ColumnEnd:   19,
+					},
+				},
+			},
+		},
+	}, detectionResults)
+}
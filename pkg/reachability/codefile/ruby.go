@@ -0,0 +1,443 @@
+package codefile
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+	tree_sitter_ruby "github.com/tree-sitter/tree-sitter-ruby/bindings/go"
+)
+
+var tsQueryForRubyRequire = `
+(call
+	method: (identifier) @method
+	(#any-of? @method "require" "require_relative")
+) @call`
+
+var tsQueryForRubyConstant = `
+[
+	(constant)
+	(scope_resolution)
+] @constant`
+
+var tsQueryForRubyDefinition = `
+[
+	(class name: (_) @name)
+	(module name: (_) @name)
+]`
+
+var tsQueryForRubyCall = `
+(call
+	receiver: (_) @receiver
+	method: (_) @method
+)`
+
+// ReachabilityRuby detects the usages of the vulnerable constants and methods of gems in Ruby files.
+// For "constant" symbols, Value is the namespace of the constant (e.g. "Nokogiri::XML") and Name its name
+// (e.g. "Document"); for "method" symbols, Value is the qualified name of the class or module and Name the method.
+// Usages are only reported in the files loading the gem, through their requires or the local files they require.
+type ReachabilityRuby struct {
+	tsParser  *treesitter.Parser
+	tsQueries map[string]*treesitter.Query
+
+	// requires caches the requires of the files parsed so far, by path
+	requires map[string]*rubyRequires
+}
+
+// rubyRequires lists what a Ruby file requires
+type rubyRequires struct {
+	// features are the features required from the load path, such as the ones of gems
+	features []string
+	// files are the local files required
+	files []string
+	// bundler is set when the file requires all the gems of the Gemfile with `Bundler.require`
+	bundler bool
+}
+
+// NewRubyReachableDetector creates a detector for Ruby files, Close should be called once all the files are parsed
+func NewRubyReachableDetector() (*ReachabilityRuby, error) {
+	tsLanguage := treesitter.NewLanguage(tree_sitter_ruby.Language())
+
+	tsParser := treesitter.NewParser()
+
+	err := tsParser.SetLanguage(tsLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set tree-sitter Ruby language on parser: %w", err)
+	}
+
+	tsQueries, err := newQueries(tsLanguage, map[string]string{
+		"requires":    tsQueryForRubyRequire,
+		"constants":   tsQueryForRubyConstant,
+		"definitions": tsQueryForRubyDefinition,
+		"calls":       tsQueryForRubyCall,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReachabilityRuby{
+		tsParser:  tsParser,
+		tsQueries: tsQueries,
+		requires:  make(map[string]*rubyRequires),
+	}, nil
+}
+
+// Close closes all hanging tree-sitter related resources.
+func (r *ReachabilityRuby) Close() {
+	r.tsParser.Close()
+	closeQueries(r.tsQueries)
+}
+
+// rubyConstant is a reference to a constant, along with the qualified name it resolves to
+type rubyConstant struct {
+	node          *treesitter.Node
+	qualifiedName string
+}
+
+func (r *ReachabilityRuby) Detect(dir string, path string, detectionResults models.DetectionResults, advisoriesToCheck []models.AdvisoryToCheck) error {
+	source, err := NewSourceContext(r.tsParser, dir, path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	r.requires[path] = r.parseRequires(source)
+	features, bundler := r.loadedFeatures(dir, path)
+
+	var loadedAdvisories []models.AdvisoryToCheck
+	for _, advisoryToCheck := range advisoriesToCheck {
+		if bundler || rubyLoadsGem(features, rubyGemOfPurl(advisoryToCheck.Purl)) {
+			loadedAdvisories = append(loadedAdvisories, advisoryToCheck)
+		}
+	}
+	if len(loadedAdvisories) == 0 {
+		// The constants of gems which are not loaded cannot be used
+		return nil
+	}
+
+	definitions := make(map[string]bool)
+	for _, name := range source.Captures(r.tsQueries["definitions"]) {
+		if qualifiedName, ok := resolveRubyDefinition(source, name); ok {
+			definitions[qualifiedName] = true
+		}
+	}
+
+	var constants []rubyConstant
+	for _, node := range source.Captures(r.tsQueries["constants"]) {
+		if !isRubyConstantReference(node) {
+			continue
+		}
+		if qualifiedName, ok := resolveRubyConstant(source, definitions, node); ok {
+			constants = append(constants, rubyConstant{node: node, qualifiedName: qualifiedName})
+		}
+	}
+
+	for _, advisoryToCheck := range loadedAdvisories {
+		for _, s := range advisoryToCheck.Symbols {
+			switch s.Type {
+			case "constant":
+				qualifiedName := strings.TrimPrefix(s.Value+"::"+s.Name, "::")
+				for _, constant := range constants {
+					if constant.qualifiedName != qualifiedName {
+						continue
+					}
+					if err := source.Report(detectionResults, advisoryToCheck, constant.node, constant.node); err != nil {
+						return err
+					}
+				}
+			case "method":
+				var reportErr error
+				source.Matches(r.tsQueries["calls"], func(captures map[string]*treesitter.Node) {
+					from, to, ok := matchRubyMethod(source, definitions, constants, captures, s)
+					if ok && reportErr == nil {
+						reportErr = source.Report(detectionResults, advisoryToCheck, from, to)
+					}
+				})
+				if reportErr != nil {
+					return reportErr
+				}
+			default:
+				log.Printf("No query found for symbol type %s\n", s.Type)
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchRubyMethod checks whether a call uses a method symbol, and returns the first and last nodes to report
+func matchRubyMethod(source *SourceContext, definitions map[string]bool, constants []rubyConstant, captures map[string]*treesitter.Node, s models.Symbols) (*treesitter.Node, *treesitter.Node, bool) {
+	receiver, method := captures["receiver"], captures["method"]
+	if receiver == nil || method == nil || source.Text(method) != s.Name {
+		return nil, nil, false
+	}
+
+	if receiver.Kind() == "constant" || receiver.Kind() == "scope_resolution" {
+		qualifiedName, ok := resolveRubyConstant(source, definitions, receiver)
+
+		return receiver, method, ok && qualifiedName == s.Value
+	}
+
+	// Objects are not typed, so instance methods are matched by name in the files using their class
+	usesClass := false
+	for _, constant := range constants {
+		if constant.qualifiedName == s.Value {
+			usesClass = true
+			break
+		}
+	}
+
+	return method, method, usesClass
+}
+
+// parseRequires returns the features and local files required by a file
+func (r *ReachabilityRuby) parseRequires(source *SourceContext) *rubyRequires {
+	requires := &rubyRequires{}
+
+	source.Matches(r.tsQueries["requires"], func(captures map[string]*treesitter.Node) {
+		call := captures["call"]
+		if receiver := call.ChildByFieldName("receiver"); receiver != nil {
+			requires.bundler = requires.bundler || (source.Text(receiver) == "Bundler" && source.Text(captures["method"]) == "require")
+			return
+		}
+
+		feature, ok := rubyStringArgument(source, call)
+		if !ok {
+			return
+		}
+
+		if source.Text(captures["method"]) == "require_relative" || strings.HasPrefix(feature, "./") || strings.HasPrefix(feature, "../") {
+			requires.files = append(requires.files, rubyFilePath(filepath.Join(filepath.Dir(source.Path), feature)))
+			return
+		}
+
+		// Features found in the lib directory of the project are part of its code
+		if local := rubyFilePath(filepath.Join(source.Dir, "lib", feature)); isFile(local) {
+			requires.files = append(requires.files, local)
+			return
+		}
+		requires.features = append(requires.features, feature)
+	})
+
+	return requires
+}
+
+// loadedFeatures returns the features required by a file, directly or through the local files it requires,
+// and whether all the gems are required with `Bundler.require`
+func (r *ReachabilityRuby) loadedFeatures(dir string, path string) ([]string, bool) {
+	var features []string
+	bundler := false
+
+	visited := map[string]bool{}
+	pending := []string{path}
+	for len(pending) > 0 {
+		current := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+
+		requires, ok := r.requires[current]
+		if !ok {
+			requires = r.parseRequiresOf(dir, current)
+			r.requires[current] = requires
+		}
+
+		features = append(features, requires.features...)
+		bundler = bundler || requires.bundler
+		pending = append(pending, requires.files...)
+	}
+
+	return features, bundler
+}
+
+// parseRequiresOf parses a local file required by another one, to follow its own requires
+func (r *ReachabilityRuby) parseRequiresOf(dir string, path string) *rubyRequires {
+	if !isFile(path) {
+		return &rubyRequires{}
+	}
+
+	source, err := NewSourceContext(r.tsParser, dir, path)
+	if err != nil {
+		log.Printf("failed to follow the requires of %s: %v\n", path, err)
+		return &rubyRequires{}
+	}
+	defer source.Close()
+
+	return r.parseRequires(source)
+}
+
+// rubyStringArgument returns the first argument of a call when it is a string without interpolation
+func rubyStringArgument(source *SourceContext, call *treesitter.Node) (string, bool) {
+	arguments := call.ChildByFieldName("arguments")
+	if arguments == nil || arguments.NamedChildCount() == 0 {
+		return "", false
+	}
+
+	argument := arguments.NamedChild(0)
+	if argument.Kind() != "string" || argument.NamedChildCount() != 1 || argument.NamedChild(0).Kind() != "string_content" {
+		return "", false
+	}
+
+	return source.Text(argument.NamedChild(0)), true
+}
+
+// rubyFilePath returns the path of the file of a required feature, which can omit the extension
+func rubyFilePath(path string) string {
+	if filepath.Ext(path) == ".rb" {
+		return path
+	}
+
+	return path + ".rb"
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+
+	return err == nil && !info.IsDir()
+}
+
+// rubyLoadsGem returns whether one of the features belongs to a gem, whose features are usually named after it:
+// "active_support/core_ext" belongs to activesupport, and "net/http" to net-http
+func rubyLoadsGem(features []string, gem string) bool {
+	normalizer := strings.NewReplacer("_", "", "-", "")
+	normalizedGem := strings.ToLower(normalizer.Replace(gem))
+
+	for _, feature := range features {
+		root, _, _ := strings.Cut(feature, "/")
+		dashed := strings.ReplaceAll(feature, "/", "-")
+		if strings.ToLower(normalizer.Replace(root)) == normalizedGem || dashed == gem || strings.HasPrefix(dashed, gem+"-") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rubyGemOfPurl returns the name of the gem of a PURL
+func rubyGemOfPurl(purl string) string {
+	parsed, err := packageurl.FromString(purl)
+	if err != nil {
+		return ""
+	}
+
+	return parsed.Name
+}
+
+// isRubyConstantReference returns whether a constant node refers to a constant on its own: the names of the
+// scope resolutions, of the methods and of the classes and modules being defined are not references
+func isRubyConstantReference(node *treesitter.Node) bool {
+	parent := node.Parent()
+	if parent == nil {
+		return true
+	}
+
+	switch parent.Kind() {
+	case "scope_resolution":
+		name := parent.ChildByFieldName("name")
+		return name == nil || name.Id() != node.Id()
+	case "call":
+		method := parent.ChildByFieldName("method")
+		return method == nil || method.Id() != node.Id()
+	case "class", "module":
+		name := parent.ChildByFieldName("name")
+		return name == nil || name.Id() != node.Id()
+	}
+
+	return true
+}
+
+// rubyConstantPath returns the path of a constant or a scope resolution, and whether it is absolute (`::Nokogiri`)
+func rubyConstantPath(source *SourceContext, node *treesitter.Node) (string, bool, bool) {
+	switch node.Kind() {
+	case "constant":
+		return source.Text(node), false, true
+	case "scope_resolution":
+		scope, name := node.ChildByFieldName("scope"), node.ChildByFieldName("name")
+		if name == nil {
+			return "", false, false
+		}
+		if scope == nil {
+			return source.Text(name), true, true
+		}
+		path, absolute, ok := rubyConstantPath(source, scope)
+
+		return path + "::" + source.Text(name), absolute, ok
+	}
+
+	// Scopes can be any expression, such as `self.class::LIMIT`
+	return "", false, false
+}
+
+// rubyNesting returns the qualified names of the classes and modules enclosing a node, from the innermost
+func rubyNesting(source *SourceContext, node *treesitter.Node) []string {
+	var names []*treesitter.Node
+	for child, parent := node, node.Parent(); parent != nil; child, parent = parent, parent.Parent() {
+		if parent.Kind() != "class" && parent.Kind() != "module" {
+			continue
+		}
+		// The name of a class does not belong to its own nesting
+		if name := parent.ChildByFieldName("name"); name != nil && name.Id() != child.Id() {
+			names = append(names, name)
+		}
+	}
+
+	nesting := make([]string, 0, len(names))
+	qualifiedName := ""
+	for i := len(names) - 1; i >= 0; i-- {
+		path, absolute, ok := rubyConstantPath(source, names[i])
+		if !ok {
+			return nil
+		}
+		if absolute || qualifiedName == "" {
+			qualifiedName = path
+		} else {
+			qualifiedName += "::" + path
+		}
+		nesting = append([]string{qualifiedName}, nesting...)
+	}
+
+	return nesting
+}
+
+// resolveRubyDefinition returns the qualified name of a class or module defined in a file, from its name node
+func resolveRubyDefinition(source *SourceContext, name *treesitter.Node) (string, bool) {
+	path, absolute, ok := rubyConstantPath(source, name)
+	if !ok {
+		return "", false
+	}
+
+	if nesting := rubyNesting(source, name); !absolute && len(nesting) > 0 {
+		return nesting[0] + "::" + path, true
+	}
+
+	return path, true
+}
+
+// resolveRubyConstant returns the qualified name of a constant: a relative constant refers to a class or module of
+// its nesting when the file defines one with its name, and to a top-level constant otherwise
+func resolveRubyConstant(source *SourceContext, definitions map[string]bool, node *treesitter.Node) (string, bool) {
+	path, absolute, ok := rubyConstantPath(source, node)
+	if !ok || absolute {
+		return path, ok
+	}
+
+	first, _, _ := strings.Cut(path, "::")
+	for _, enclosing := range rubyNesting(source, node) {
+		if definitions[enclosing+"::"+first] {
+			return enclosing + "::" + path, true
+		}
+	}
+
+	return path, true
+}
+
+var _ Detector = &ReachabilityRuby{}
@@ -0,0 +1,157 @@
+package codefile
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewRubyReachableDetector(t *testing.T) {
+	t.Parallel()
+	detector, err := NewRubyReachableDetector()
+	require.NoError(t, err)
+	defer detector.Close()
+
+	assert.NotNil(t, detector)
+}
+
+func Test_DetectRuby(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		path     string
+		purl     string
+		symbol   models.Symbols
+		expected []string
+	}{
+		{
+			name:     "class method of a scoped constant",
+			path:     "testdata/ruby/app.rb",
+			purl:     "pkg:gem/nokogiri@1.10.0",
+			symbol:   models.Symbols{Type: "method", Value: "Nokogiri::XML::Document", Name: "parse"},
+			expected: []string{"Nokogiri::XML::Document.parse"},
+		},
+		{
+			name:     "instance method in a file using the class",
+			path:     "testdata/ruby/app.rb",
+			purl:     "pkg:gem/nokogiri@1.10.0",
+			symbol:   models.Symbols{Type: "method", Value: "Nokogiri::XML::Document", Name: "search"},
+			expected: []string{"search"},
+		},
+		{
+			name:     "method of an absolute constant",
+			path:     "testdata/ruby/app.rb",
+			purl:     "pkg:gem/nokogiri@1.10.0",
+			symbol:   models.Symbols{Type: "method", Value: "Nokogiri", Name: "HTML"},
+			expected: []string{"::Nokogiri::HTML"},
+		},
+		{
+			name:     "constant",
+			path:     "testdata/ruby/app.rb",
+			purl:     "pkg:gem/nokogiri@1.10.0",
+			symbol:   models.Symbols{Type: "constant", Value: "Nokogiri", Name: "XML"},
+			expected: []string{"Nokogiri::XML"},
+		},
+		{
+			name:   "constant shadowed by a class of the file",
+			path:   "testdata/ruby/app.rb",
+			purl:   "pkg:gem/nokogiri@1.10.0",
+			symbol: models.Symbols{Type: "constant", Value: "", Name: "Document"},
+			// Document refers to App::Document in the nesting of the Importer class
+			expected: nil,
+		},
+		{
+			name:     "gem whose features are named differently",
+			path:     "testdata/ruby/app.rb",
+			purl:     "pkg:gem/activesupport@6.0.0",
+			symbol:   models.Symbols{Type: "method", Value: "ActiveSupport::Inflector", Name: "pluralize"},
+			expected: []string{"ActiveSupport::Inflector.pluralize"},
+		},
+		{
+			name:     "gem required by a local file",
+			path:     "testdata/ruby/app.rb",
+			purl:     "pkg:gem/psych@3.0.0",
+			symbol:   models.Symbols{Type: "method", Value: "Psych", Name: "safe_load"},
+			expected: []string{"Psych.safe_load"},
+		},
+		{
+			name:     "gem not required",
+			path:     "testdata/ruby/no_require.rb",
+			purl:     "pkg:gem/nokogiri@1.10.0",
+			symbol:   models.Symbols{Type: "method", Value: "Nokogiri::XML::Document", Name: "parse"},
+			expected: nil,
+		},
+		{
+			name:     "gems required by bundler",
+			path:     "testdata/ruby/bundler.rb",
+			purl:     "pkg:gem/nokogiri@1.10.0",
+			symbol:   models.Symbols{Type: "method", Value: "Nokogiri::XML::Document", Name: "parse"},
+			expected: []string{"Nokogiri::XML::Document.parse"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// A detector is not safe for concurrent use
+			detector, err := NewRubyReachableDetector()
+			require.NoError(t, err)
+			defer detector.Close()
+
+			advisoriesToCheck := []models.AdvisoryToCheck{{Purl: tc.purl, AdvisoryID: "GHSA-ruby", Symbols: []models.Symbols{tc.symbol}}}
+			detectionResults := models.DetectionResults{}
+			err = detector.Detect("testdata/ruby", tc.path, detectionResults, advisoriesToCheck)
+			require.NoError(t, err)
+
+			var found []string
+			for _, location := range detectionResults[tc.purl]["GHSA-ruby"] {
+				found = append(found, location.Symbol)
+			}
+			assert.Equal(t, tc.expected, found)
+		})
+	}
+}
+
+func Test_DetectRuby_NestedConstant(t *testing.T) {
+	t.Parallel()
+	detector, err := NewRubyReachableDetector()
+	require.NoError(t, err)
+	defer detector.Close()
+
+	// Document refers to the class of the file, and not to a top-level constant
+	advisoriesToCheck := []models.AdvisoryToCheck{
+		{
+			Purl:       "pkg:gem/nokogiri@1.10.0",
+			AdvisoryID: "GHSA-ruby",
+			Symbols: []models.Symbols{
+				{Type: "method", Value: "Document", Name: "parse"},
+				{Type: "method", Value: "App::Document", Name: "parse"},
+			},
+		},
+	}
+	detectionResults := models.DetectionResults{}
+	err = detector.Detect("testdata/ruby", "testdata/ruby/app.rb", detectionResults, advisoriesToCheck)
+	require.NoError(t, err)
+
+	assert.Equal(t, models.DetectionResults{
+		"pkg:gem/nokogiri@1.10.0": {
+			"GHSA-ruby": {
+				{
+					Symbol: "Document.parse",
+					PackageLocation: models.PackageLocation{
+						Filename:    "testdata/ruby/app.rb",
+						LineStart:   17,
+						LineEnd:     17,
+						ColumnStart: 15,
+						ColumnEnd:   29,
+					},
+				},
+			},
+		},
+	}, detectionResults)
+}
@@ -0,0 +1,273 @@
+package codefile
+
+import (
+	"strings"
+
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+	tree_sitter_scala "github.com/tree-sitter/tree-sitter-scala/bindings/go"
+)
+
+// Objects are created with the new keyword, or by calling their class as case classes and Scala 3 allow it
+var tsQueryForScalaClass = `
+[
+	(instance_expression
+		[
+			(type_identifier)
+			(stable_type_identifier)
+		] @class
+	)
+	(instance_expression
+		(generic_type type: (_) @class)
+	)
+	(call_expression
+		function: [
+			(identifier)
+			(field_expression)
+		] @class
+	)
+	(call_expression
+		function: (generic_function function: (_) @class)
+	)
+]`
+
+// Methods can be called without object when they are inherited or imported
+var tsQueryForScalaMethod = `
+(call_expression
+	function: [
+		(identifier) @name
+		(field_expression
+			value: (_) @object
+			field: (identifier) @name
+		)
+		(generic_function
+			function: [
+				(identifier) @name
+				(field_expression
+					value: (_) @object
+					field: (identifier) @name
+				)
+			]
+		)
+	] @function
+)`
+
+var tsQueryForScalaName = `
+[
+	(identifier)
+	(type_identifier)
+	(stable_identifier)
+	(stable_type_identifier)
+	(field_expression)
+] @name`
+
+var tsQueryForScalaPackage = `
+(package_clause
+	name: (package_identifier) @package
+)`
+
+var tsQueryForScalaImport = `(import_declaration) @import`
+
+var tsQueryForScalaDeclaration = `
+[
+	(class_definition name: (_) @name)
+	(trait_definition name: (_) @name)
+	(object_definition name: (_) @name)
+	(enum_definition name: (_) @name)
+	(type_definition name: (_) @name)
+]`
+
+// scalaDefaultImports lists the packages and objects whose members are imported in every Scala file
+var scalaDefaultImports = []string{"java.lang", "scala", "scala.Predef"}
+
+var scalaDialect = &jvmDialect{
+	name:     "Scala",
+	language: tree_sitter_scala.Language,
+	symbolTypeToTSQuery: map[string]string{
+		"class":          tsQueryForScalaClass,
+		"method":         tsQueryForScalaMethod,
+		"static_method":  tsQueryForScalaMethod,
+		"type_reference": tsQueryForScalaName,
+	},
+	queries: map[string]string{
+		"names":        tsQueryForScalaName,
+		"packages":     tsQueryForScalaPackage,
+		"imports":      tsQueryForScalaImport,
+		"declarations": tsQueryForScalaDeclaration,
+	},
+	nameOf:     scalaNameOf,
+	isNameRoot: isScalaNameRoot,
+	newScope:   newScalaScope,
+}
+
+// ReachabilityScala detects the usages of the vulnerable symbols of Maven packages in Scala files, with the symbol
+// types of Java. Objects can be created with or without the new keyword, as case classes and Scala 3 allow it.
+type ReachabilityScala struct {
+	jvmSourceDetector
+}
+
+// NewScalaReachableDetector creates a detector for Scala files, Close should be called once all the files are parsed
+func NewScalaReachableDetector() (*ReachabilityScala, error) {
+	detector, err := newJVMSourceDetector(scalaDialect)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReachabilityScala{jvmSourceDetector: detector}, nil
+}
+
+// scalaNameOf reads the dotted name of an identifier, a type or a chain of field accesses, which is not a name
+// when it involves anything else than identifiers, such as a call or `this`
+func scalaNameOf(source *SourceContext, node *treesitter.Node) (jvmName, bool) {
+	if node == nil {
+		return jvmName{}, false
+	}
+
+	switch node.Kind() {
+	case "identifier", "type_identifier":
+		if text := source.Text(node); text != "this" && text != "super" {
+			return jvmName{segments: []string{text}, nodes: []*treesitter.Node{node}}, true
+		}
+	case "stable_identifier", "stable_type_identifier":
+		var name jvmName
+		for i := range node.NamedChildCount() {
+			segment, ok := scalaNameOf(source, node.NamedChild(i))
+			if !ok {
+				return jvmName{}, false
+			}
+			name.segments = append(name.segments, segment.segments...)
+			name.nodes = append(name.nodes, segment.nodes...)
+		}
+
+		return name, len(name.segments) > 0
+	case "field_expression":
+		name, ok := scalaNameOf(source, node.ChildByFieldName("value"))
+		field := node.ChildByFieldName("field")
+		if !ok || field == nil {
+			return jvmName{}, false
+		}
+		name.segments = append(name.segments, source.Text(field))
+		name.nodes = append(name.nodes, field)
+
+		return name, true
+	}
+
+	return jvmName{}, false
+}
+
+// isScalaNameRoot returns whether a node is a whole dotted name, outside of the packages, imports, annotations
+// and names of the declarations
+func isScalaNameRoot(node *treesitter.Node) bool {
+	parent := node.Parent()
+	if parent == nil {
+		return true
+	}
+
+	switch parent.Kind() {
+	case "stable_identifier", "stable_type_identifier", "field_expression", "package_identifier", "import_declaration",
+		"namespace_selectors", "arrow_renamed_identifier", "as_renamed_identifier", "annotation":
+		return false
+	case "class_definition", "trait_definition", "object_definition", "enum_definition", "type_definition",
+		"function_definition":
+		return !isDeclarationName(node, "name")
+	}
+
+	return true
+}
+
+// newScalaScope reads the packages, the imports and the declarations of a file.
+// Chained package clauses (package a; package b) put the file in a.b while bringing the members of a in scope.
+func newScalaScope(source *SourceContext, tsQueries map[string]*treesitter.Query) *jvmScope {
+	scope := newJVMScope(scalaDefaultImports)
+
+	for _, node := range source.Captures(tsQueries["packages"]) {
+		pkg := strings.Join(identifierTexts(source, node), ".")
+		if len(scope.packages) > 0 {
+			pkg = scope.packages[len(scope.packages)-1] + "." + pkg
+		}
+		scope.packages = append(scope.packages, pkg)
+	}
+
+	for _, node := range source.Captures(tsQueries["imports"]) {
+		addScalaImports(source, scope, node)
+	}
+
+	for _, node := range source.Captures(tsQueries["declarations"]) {
+		scope.declared[source.Text(node)] = true
+	}
+
+	return scope
+}
+
+// addScalaImports reads the import clauses of an import declaration, separated by commas,
+// such as `import a.b.C, a.b.{D => E, _}`
+func addScalaImports(source *SourceContext, scope *jvmScope, node *treesitter.Node) {
+	var path []string
+	// selected is set once the path of the clause is followed by a wildcard, selectors or an alias
+	selected := false
+	flush := func() {
+		if !selected {
+			scope.addImport(path, "")
+		}
+		path, selected = nil, false
+	}
+
+	for i := range node.ChildCount() {
+		child := node.Child(i)
+		switch child.Kind() {
+		case "identifier":
+			path = append(path, source.Text(child))
+		case ",":
+			flush()
+		case "namespace_wildcard":
+			addScalaWildcard(scope, path, child)
+			selected = true
+		case "as_renamed_identifier":
+			addScalaRenamedImport(source, scope, path, child)
+			selected = true
+		case "namespace_selectors":
+			for j := range child.NamedChildCount() {
+				selector := child.NamedChild(j)
+				switch selector.Kind() {
+				case "identifier":
+					scope.addImport(append(path[:len(path):len(path)], source.Text(selector)), "")
+				case "arrow_renamed_identifier", "as_renamed_identifier":
+					addScalaRenamedImport(source, scope, path, selector)
+				case "namespace_wildcard":
+					addScalaWildcard(scope, path, selector)
+				}
+			}
+			selected = true
+		}
+	}
+	flush()
+}
+
+// addScalaWildcard imports all the members of a package or an object, while given instances (import a.b.given)
+// do not bring classes in scope
+func addScalaWildcard(scope *jvmScope, path []string, wildcard *treesitter.Node) {
+	for i := range wildcard.ChildCount() {
+		if wildcard.Child(i).Kind() == "given" {
+			return
+		}
+	}
+
+	scope.wildcards = append(scope.wildcards, strings.Join(path, "."))
+}
+
+// addScalaRenamedImport imports a member under an alias, such as {D => E} or D as E.
+// Renaming a member to _ hides it from the wildcard of the clause, as an import to nothing.
+func addScalaRenamedImport(source *SourceContext, scope *jvmScope, path []string, renamed *treesitter.Node) {
+	name, alias := renamed.ChildByFieldName("name"), renamed.ChildByFieldName("alias")
+	if name == nil || alias == nil {
+		return
+	}
+
+	if alias.Kind() == "wildcard" {
+		scope.imports[source.Text(name)] = ""
+
+		return
+	}
+	scope.addImport(append(path[:len(path):len(path)], source.Text(name)), source.Text(alias))
+}
+
+var _ Detector = &ReachabilityScala{}
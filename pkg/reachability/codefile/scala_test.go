@@ -0,0 +1,106 @@
+package codefile
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewScalaReachableDetector(t *testing.T) {
+	t.Parallel()
+	detector, err := NewScalaReachableDetector()
+	require.NoError(t, err)
+	defer detector.Close()
+
+	assert.NotNil(t, detector)
+}
+
+func Test_DetectScala(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		symbol   models.Symbols
+		expected []string
+	}{
+		{
+			name:     "object creation with new and without parentheses",
+			symbol:   models.Symbols{Type: "class", Value: "com.fasterxml.jackson.databind", Name: "ObjectMapper"},
+			expected: []string{"ObjectMapper"},
+		},
+		{
+			name:     "object creation without new through an import alias",
+			symbol:   models.Symbols{Type: "class", Value: "org.yaml.snakeyaml", Name: "Yaml"},
+			expected: []string{"SnakeYaml"},
+		},
+		{
+			name:     "object creation with a fully qualified name",
+			symbol:   models.Symbols{Type: "class", Value: "com.google.gson", Name: "Gson"},
+			expected: []string{"com.google.gson.Gson"},
+		},
+		{
+			name:     "object creation of a class of a parent package",
+			symbol:   models.Symbols{Type: "class", Value: "com.example", Name: "Helper"},
+			expected: []string{"Helper"},
+		},
+		{
+			name:     "type reference through a renaming selector",
+			symbol:   models.Symbols{Type: "type_reference", Value: "com.fasterxml.jackson.databind", Name: "JsonNode"},
+			expected: []string{"Node"},
+		},
+		{
+			name:     "type shadowed by a class declared by the file",
+			symbol:   models.Symbols{Type: "type_reference", Value: "org.apache.commons.io", Name: "Settings"},
+			expected: nil,
+		},
+		{
+			name:     "instance method call in a file using the class",
+			symbol:   models.Symbols{Type: "method", Value: "com.fasterxml.jackson.databind.ObjectMapper", Name: "readTree"},
+			expected: []string{"readTree"},
+		},
+		{
+			name:     "instance method call with type arguments",
+			symbol:   models.Symbols{Type: "method", Value: "org.yaml.snakeyaml.Yaml", Name: "loadAs"},
+			expected: []string{"loadAs[Settings]"},
+		},
+		{
+			name:     "static method call of an object whose members are imported",
+			symbol:   models.Symbols{Type: "static_method", Value: "org.apache.commons.text.StringEscapeUtils", Name: "escapeJson"},
+			expected: []string{"escapeJson"},
+		},
+		{
+			name:     "static method call of a class of a wildcard selector",
+			symbol:   models.Symbols{Type: "static_method", Value: "org.apache.commons.io.IOUtils", Name: "readLines"},
+			expected: []string{"IOUtils.readLines"},
+		},
+		{
+			name:     "static method call of a class hidden by a selector",
+			symbol:   models.Symbols{Type: "static_method", Value: "org.apache.commons.io.FileUtils", Name: "listFiles"},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			detector, err := NewScalaReachableDetector()
+			require.NoError(t, err)
+			defer detector.Close()
+
+			advisoriesToCheck := []models.AdvisoryToCheck{{Purl: "pkg:maven/com.example/example@1.0.0", AdvisoryID: "GHSA-scala", Symbols: []models.Symbols{tc.symbol}}}
+			detectionResults := models.DetectionResults{}
+			err = detector.Detect("testdata/scala", "testdata/scala/App.scala", detectionResults, advisoriesToCheck)
+			require.NoError(t, err)
+
+			var found []string
+			for _, location := range detectionResults["pkg:maven/com.example/example@1.0.0"]["GHSA-scala"] {
+				found = append(found, location.Symbol)
+			}
+			assert.Equal(t, tc.expected, found)
+		})
+	}
+}
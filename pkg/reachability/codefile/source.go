@@ -0,0 +1,117 @@
+package codefile
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// Detector reports the usages of vulnerable symbols in the source files of a language
+type Detector interface {
+	// Detect adds the usages of the symbols of the advisories found in the file at path to detectionResults,
+	// with locations relative to dir
+	Detect(dir string, path string, detectionResults models.DetectionResults, advisoriesToCheck []models.AdvisoryToCheck) error
+	// Close releases the resources of the detector, it should only be called once all the files are analyzed
+	Close()
+}
+
+// SourceContext is a source file parsed with tree-sitter, along with the helpers shared by the detectors
+// to query it and report the usages they find
+type SourceContext struct {
+	Dir     string
+	Path    string
+	Content []byte
+
+	tree   *treesitter.Tree
+	cursor *treesitter.QueryCursor
+}
+
+// NewSourceContext reads and parses the file at path, Close should be called once it is analyzed
+func NewSourceContext(parser *treesitter.Parser, dir string, path string) (*SourceContext, error) {
+	content, err := readFileContent(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SourceContext{
+		Dir:     dir,
+		Path:    path,
+		Content: content,
+		tree:    parser.Parse(content, nil),
+		cursor:  treesitter.NewQueryCursor(),
+	}, nil
+}
+
+// Close releases the tree-sitter resources of the file
+func (c *SourceContext) Close() {
+	c.cursor.Close()
+	c.tree.Close()
+}
+
+// Root returns the root node of the file
+func (c *SourceContext) Root() *treesitter.Node {
+	return c.tree.RootNode()
+}
+
+// Text returns the source code of a node
+func (c *SourceContext) Text(node *treesitter.Node) string {
+	return node.Utf8Text(c.Content)
+}
+
+// Matches runs a query against the file, and calls match with the captures of each match, by name.
+// The captured nodes are copied, as the memory of the matches is reused by tree-sitter.
+func (c *SourceContext) Matches(query *treesitter.Query, match func(captures map[string]*treesitter.Node)) {
+	matches := c.cursor.Matches(query, c.Root(), c.Content)
+	for m := matches.Next(); m != nil; m = matches.Next() {
+		captures := make(map[string]*treesitter.Node, len(m.Captures))
+		for _, capture := range m.Captures {
+			captures[query.CaptureNames()[capture.Index]] = &capture.Node
+		}
+		match(captures)
+	}
+}
+
+// Captures runs a query against the file, and returns all the captured nodes
+func (c *SourceContext) Captures(query *treesitter.Query) []*treesitter.Node {
+	var nodes []*treesitter.Node
+	captures := c.cursor.Captures(query, c.Root(), c.Content)
+	for match, index := captures.Next(); match != nil; match, index = captures.Next() {
+		node := match.Captures[index].Node
+		nodes = append(nodes, &node)
+	}
+
+	return nodes
+}
+
+// Report records that a symbol of an advisory is used by the code spanning from the start of a node
+// to the end of another one, which can be the same
+func (c *SourceContext) Report(detectionResults models.DetectionResults, advisoryToCheck models.AdvisoryToCheck, from *treesitter.Node, to *treesitter.Node) error {
+	symbol := string(c.Content[from.StartByte():to.EndByte()])
+
+	return addDetection(detectionResults, advisoryToCheck, c.Dir, c.Path, from.StartPosition(), to.EndPosition(), symbol)
+}
+
+// newQueries creates the queries of a language, by name
+func newQueries(language *treesitter.Language, queries map[string]string) (map[string]*treesitter.Query, error) {
+	compiled := make(map[string]*treesitter.Query, len(queries))
+	for name, source := range queries {
+		// NewQuery returns a *QueryError, which must not be assigned to an error before being checked
+		query, queryErr := treesitter.NewQuery(language, source)
+		if queryErr != nil {
+			closeQueries(compiled)
+
+			return nil, fmt.Errorf("failed to create tree-sitter query for %s: %w", name, queryErr)
+		}
+		compiled[name] = query
+	}
+
+	return compiled, nil
+}
+
+func closeQueries(queries map[string]*treesitter.Query) {
+	for _, query := range queries {
+		query.Close()
+	}
+}
@@ -0,0 +1,14 @@
+package com.sample;
+
+import org.example.*;
+
+public class ExampleApp {
+  // The local class shadows org.example.Greeter, even though its package is imported
+  static class Greeter {
+    Greeter(String name) {}
+  }
+
+  public static void main(String[] args) {
+    Greeter greeter = new Greeter("Daniel");
+  }
+}
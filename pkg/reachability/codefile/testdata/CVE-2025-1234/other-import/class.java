@@ -0,0 +1,11 @@
+package com.sample;
+
+import com.other.Greeter;
+import org.example.*;
+
+public class ExampleApp {
+  public static void main(String[] args) {
+    Greeter greeter = new Greeter("Daniel");
+    greeter.sayHello();
+  }
+}
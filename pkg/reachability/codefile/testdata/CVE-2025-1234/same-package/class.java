@@ -0,0 +1,8 @@
+package org.example;
+
+public class ExampleApp {
+  public static void main(String[] args) {
+    Greeter greeter = new Greeter("Daniel");
+    greeter.sayHello();
+  }
+}
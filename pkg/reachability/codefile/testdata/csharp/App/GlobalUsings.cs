@@ -0,0 +1,2 @@
+global using Newtonsoft.Json;
+global using Yaml = YamlDotNet.Serialization;
@@ -0,0 +1,26 @@
+using System;
+using System.Collections.Generic;
+using static Newtonsoft.Json.JsonConvert;
+
+namespace Example.App
+{
+    // new JsonSerializerSettings() in a comment is ignored
+    public class Program
+    {
+        public static void Main(string[] args)
+        {
+            var settings = new JsonSerializerSettings();
+            var value = JsonConvert.DeserializeObject<List<string>>(args[0], settings);
+            var again = DeserializeObject("[]");
+            var deserializer = new Yaml.DeserializerBuilder().Build();
+            List<JToken> tokens = new List<JToken>();
+            var text = @"JsonConvert.DeserializeObject(""x"")";
+            Console.WriteLine($"{value} {text}", Config.Name);
+        }
+    }
+
+    public class Config
+    {
+        public const string Name = "config";
+    }
+}
@@ -0,0 +1,4 @@
+public class Standalone
+{
+    public object Parse(string json) => JsonConvert.DeserializeObject(json);
+}
@@ -0,0 +1,8 @@
+module example.com/app
+
+go 1.22
+
+require (
+	example.com/vulnerable v1.2.3
+	gopkg.in/yaml.v2 v2.2.1
+)
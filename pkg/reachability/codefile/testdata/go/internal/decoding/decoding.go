@@ -0,0 +1,9 @@
+package decoding
+
+import (
+	. "example.com/vulnerable/parser"
+)
+
+func NewDecoder() *Decoder {
+	return NewStrictDecoder()
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"example.com/app/internal/decoding"
+	p "example.com/vulnerable/parser"
+	"gopkg.in/yaml.v2"
+)
+
+func handler(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseMultipartForm(1024)
+	fmt.Fprintln(w, "ok")
+}
+
+func main() {
+	document, err := p.Parse(os.Stdin)
+	if err != nil {
+		panic(err)
+	}
+
+	var config map[string]string
+	_ = yaml.Unmarshal(document, &config)
+
+	decoding.NewDecoder().Decode()
+
+	http.HandleFunc("/", handler)
+}
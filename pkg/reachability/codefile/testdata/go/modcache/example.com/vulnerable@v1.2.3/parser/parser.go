@@ -0,0 +1,17 @@
+package parser
+
+import "io"
+
+type Decoder struct {
+	strict bool
+}
+
+func NewStrictDecoder() *Decoder {
+	return &Decoder{strict: true}
+}
+
+func (d *Decoder) Decode() {}
+
+func Parse(r io.Reader) ([]byte, error) {
+	return io.ReadAll(r)
+}
@@ -0,0 +1,7 @@
+package main
+
+import "example.com/vulnerable/parser"
+
+func main() {
+	_, _ = parser.Parse(nil)
+}
@@ -0,0 +1,12 @@
+package com.sample;
+
+import static org.example.Settings.load;
+import static org.example.Defaults.*;
+
+public class App {
+  public static void main(String[] args) throws Exception {
+    Process process = new ProcessBuilder(args).start();
+    load(args[0]);
+    reset();
+  }
+}
@@ -0,0 +1,10 @@
+const _ = require("lodash");
+const { merge, template: tpl } = require("lodash");
+const set = require("lodash").set;
+const qs = require("qs");
+
+_.template("<%= user %>")({ user: "fred" });
+merge({}, JSON.parse(process.argv[2]));
+tpl("<%= user %>");
+set({}, "a.b", 1);
+qs.parse("a=1");
@@ -0,0 +1,10 @@
+import _, { merge as deepMerge } from "lodash";
+import * as qs from "qs";
+import { pick } from "./utils/index.js";
+import { parse } from "./reexport";
+
+deepMerge({}, {});
+qs.parse("a=1");
+pick({ a: 1 }, "a");
+parse("a: 1");
+new _.Template();
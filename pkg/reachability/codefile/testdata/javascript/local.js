@@ -0,0 +1,5 @@
+function merge(a, b) {
+  return Object.assign(a, b);
+}
+
+merge({}, {});
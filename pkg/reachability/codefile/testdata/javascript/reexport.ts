@@ -0,0 +1 @@
+export { parse } from "yaml";
@@ -0,0 +1,9 @@
+import { stringify } from "./utils";
+
+interface Query {
+  page: number;
+}
+
+export function toQueryString(query: Query): string {
+  return stringify(query) as string;
+}
@@ -0,0 +1,16 @@
+import type { IStringifyOptions } from "qs";
+import qs = require("qs");
+import { merge } from "lodash";
+
+export class Store<T extends object> {
+  private state: Map<string, T> = new Map<string, T>();
+
+  update(key: string, patch: Partial<T>): T {
+    const next = merge<T, Partial<T>>(this.state.get(key)!, patch);
+    this.state.set(key, next);
+    return next;
+  }
+}
+
+export const encode = (value: Record<string, unknown>, options?: IStringifyOptions): string =>
+  (qs as typeof import("qs")).stringify(value, options);
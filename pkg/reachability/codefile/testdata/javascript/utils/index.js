@@ -0,0 +1,2 @@
+export { pick } from "lodash";
+export * from "./more";
@@ -0,0 +1,9 @@
+import * as React from "react";
+import { template } from "lodash";
+
+type Props = { name: string };
+
+export function Greeting<P extends Props>({ name }: P): JSX.Element {
+  const render = template("Hello <%= name %>");
+  return <div title={name}>{render({ name })}</div>;
+}
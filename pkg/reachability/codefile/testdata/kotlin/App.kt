@@ -0,0 +1,26 @@
+@file:JvmName("App")
+
+package com.example.app
+
+import com.fasterxml.jackson.databind.ObjectMapper
+import org.yaml.snakeyaml.Yaml as SnakeYaml
+import org.apache.commons.text.StringSubstitutor.replaceSystemProperties
+import org.apache.commons.collections4.*
+
+/* A /* nested */ comment mentioning ObjectMapper() */
+class Config(val name: String)
+
+fun Config.describe(): String = "Config $name: ${name.length}"
+
+fun main(args: Array<String>) {
+    val mapper = ObjectMapper()
+    val config = mapper.readValue(args[0], Config::class.java)
+    val fallback = Config("default")
+    val yaml = SnakeYaml()
+    yaml.load<Map<String, Any>>(args[1])
+    val text = replaceSystemProperties("\${user.home}")
+    val empty = CollectionUtils.emptyCollection<String>()
+    val json = com.google.gson.Gson().toJson(config)
+    println(StringSubstitutor.createInterpolator().replace(text))
+    mapper?.writeValueAsString(fallback)
+}
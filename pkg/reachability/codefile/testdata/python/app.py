@@ -0,0 +1,17 @@
+import yaml
+import xml.etree.ElementTree
+import requests as http
+from jinja2 import Template as T, Environment
+from django.utils.html import *
+from . import load
+
+config = yaml.load(open("config.yml"), Loader=yaml.FullLoader)
+tree = xml.etree.ElementTree.parse("data.xml")
+response = http.get("https://example.com", verify=False)
+template = T("{{ name }}")
+html = format_html("<b>{}</b>", "name")
+load("local")
+
+
+def render(environment: Environment, loader):
+    return environment.from_string(loader.read())
@@ -0,0 +1,22 @@
+require "nokogiri"
+require "active_support/core_ext/string"
+require "app/config"
+
+module App
+  class Document
+    def self.parse(xml)
+      xml
+    end
+  end
+
+  class Importer
+    def import(xml)
+      document = Nokogiri::XML::Document.parse(xml)
+      document.search("//item")
+      html = ::Nokogiri::HTML(xml)
+      local = Document.parse(xml)
+      ActiveSupport::Inflector.pluralize("item")
+      Psych.safe_load(xml)
+    end
+  end
+end
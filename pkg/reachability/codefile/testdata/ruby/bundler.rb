@@ -0,0 +1,5 @@
+require "bundler"
+
+Bundler.require(:default)
+
+Nokogiri::XML::Document.parse(xml)
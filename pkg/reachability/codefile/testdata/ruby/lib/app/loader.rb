@@ -0,0 +1 @@
+require "psych"
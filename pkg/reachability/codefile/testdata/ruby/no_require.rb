@@ -0,0 +1 @@
+document = Nokogiri::XML::Document.parse(xml)
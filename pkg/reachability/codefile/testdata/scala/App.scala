@@ -0,0 +1,23 @@
+package com.example
+package app
+
+import com.fasterxml.jackson.databind.{ObjectMapper, JsonNode => Node}
+import org.yaml.snakeyaml.Yaml as SnakeYaml
+import org.apache.commons.text.StringEscapeUtils._
+import org.apache.commons.io.{FileUtils => _, _}
+
+case class Settings(name: String)
+
+object App {
+  def main(args: Array[String]): Unit = {
+    val mapper = new ObjectMapper
+    val node: Node = mapper.readTree(args(0))
+    val yaml = SnakeYaml()
+    val settings = yaml.loadAs[Settings](args(1), classOf[Settings])
+    println(escapeJson(settings.name))
+    val lines = IOUtils.readLines(System.in, "UTF-8")
+    val files = FileUtils.listFiles(null, null, true)
+    val helper = Helper()
+    println(new com.google.gson.Gson().toJson(node))
+  }
+}
@@ -0,0 +1,17 @@
+package com.sample;
+
+import com.fasterxml.jackson.databind.ObjectMapper;
+import org.example.Settings;
+import org.example.VulnerableBase;
+
+public class App extends VulnerableBase {
+  private final ObjectMapper mapper = new ObjectMapper();
+  private org.example.Cache cache;
+
+  public Data read(String json) throws Exception {
+    inherited();
+    Settings settings = Settings.load(json);
+    int limit = Settings.DEFAULT_LIMIT;
+    return mapper.readValue(json, Data.class);
+  }
+}
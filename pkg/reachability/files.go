@@ -0,0 +1,75 @@
+package reachability
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// SourceFile is a source file of a scanned directory, whose usages of vulnerable symbols are analyzed
+type SourceFile struct {
+	// Dir is the scanned directory the file has been found in
+	Dir string
+	// Path is the path of the file as walked from Dir
+	Path string
+}
+
+// SourceFileCollector collects the source files of the languages supported by the reachability analysis
+// among the files selected by a scan, leaving out the dependencies stored in the scanned directories
+type SourceFileCollector struct {
+	Files []SourceFile
+
+	// dependencyDir is the directory of dependencies being walked, if any
+	dependencyDir string
+}
+
+// Visit is called for each file and directory walked by a scan, in the lexical order of filepath.WalkDir
+func (c *SourceFileCollector) Visit(dir string, path string, d fs.DirEntry) {
+	if c.dependencyDir != "" && strings.HasPrefix(path, c.dependencyDir+string(filepath.Separator)) {
+		return
+	}
+	c.dependencyDir = ""
+
+	if d.IsDir() {
+		// Dependencies are not part of the code of the project
+		if d.Name() == "node_modules" || isGoVendorDir(path) {
+			c.dependencyDir = path
+		}
+
+		return
+	}
+
+	if _, ok := findLanguage(path); ok {
+		c.Files = append(c.Files, SourceFile{Dir: dir, Path: path})
+	}
+}
+
+// projectFileNames are the names of the files read by the detectors next to the source files: the modules of Go packages
+// and their vendored dependencies. The .gitignore files select the source files.
+var projectFileNames = []string{"go.mod", "modules.txt", ".gitignore"}
+
+// projectFileExtensions are the extensions of the files read by the detectors next to the source files: the projects
+// of C# files
+var projectFileExtensions = []string{".csproj"}
+
+// IsAnalyzedFile returns whether a file is read by the reachability analysis, either as a source file or as a file
+// describing the project of source files, such as a go.mod file
+func IsAnalyzedFile(path string) bool {
+	if _, ok := findLanguage(path); ok {
+		return true
+	}
+
+	return slices.Contains(projectFileNames, filepath.Base(path)) || slices.Contains(projectFileExtensions, filepath.Ext(path))
+}
+
+// isGoVendorDir returns whether a directory holds the vendored dependencies of a Go module
+func isGoVendorDir(path string) bool {
+	if filepath.Base(path) != "vendor" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(path, "modules.txt"))
+
+	return err == nil
+}
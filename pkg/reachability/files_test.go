@@ -0,0 +1,66 @@
+package reachability
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceFileCollector(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, path := range []string{
+		"README.md",
+		"main.go",
+		"node_modules/qs/index.js",
+		"src/app.py",
+		"src/lib/vendor/helpers.js",
+		"vendor/modules.txt",
+		"vendor/github.com/example/lib/lib.go",
+		"web/index.ts",
+		"web/node_modules/react/index.js",
+	} {
+		path = filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, nil, 0o600))
+	}
+
+	collector := &SourceFileCollector{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		collector.Visit(dir, path, d)
+
+		return err
+	})
+	require.NoError(t, err)
+
+	// Vendor directories without modules.txt are not Go dependencies
+	assert.Equal(t, []SourceFile{
+		{Dir: dir, Path: filepath.Join(dir, "main.go")},
+		{Dir: dir, Path: filepath.Join(dir, "src/app.py")},
+		{Dir: dir, Path: filepath.Join(dir, "src/lib/vendor/helpers.js")},
+		{Dir: dir, Path: filepath.Join(dir, "web/index.ts")},
+	}, collector.Files)
+}
+
+func TestIsAnalyzedFile(t *testing.T) {
+	t.Parallel()
+
+	for path, expected := range map[string]bool{
+		"src/main/java/App.java":   true,
+		"web/index.ts":             true,
+		"go.mod":                   true,
+		"vendor/modules.txt":       true,
+		"src/App/App.csproj":       true,
+		".gitignore":               true,
+		"README.md":                false,
+		"assets/logo.png":          false,
+		"src/main/resources/a.xml": false,
+	} {
+		assert.Equal(t, expected, IsAnalyzedFile(path), path)
+	}
+}
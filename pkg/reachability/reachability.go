@@ -1,69 +1,234 @@
 package reachability
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"sync"
 
 	"github.com/DataDog/datadog-sbom-generator/internal/http"
 	"github.com/DataDog/datadog-sbom-generator/pkg/models"
 	"github.com/DataDog/datadog-sbom-generator/pkg/reachability/codefile"
 )
 
-// PerformReachabilityAnalysis performs a reachability analysis on the given PURLs.
-func PerformReachabilityAnalysis(purls []string, directoryPaths []string, enabled bool, ddBaseURL string, ddJwtToken string) models.ReachabilityAnalysis {
+// languages lists the languages supported by the reachability analysis,
+// along with the extensions of their source files and their detectors.
+// Kotlin and Scala use the Maven packages like Java, so their files are checked against the advisories of Java,
+// as well as the compiled classes of all of them.
+// The detections in the files of self-contained languages only depend on their content, so they can be cached.
+var languages = []struct {
+	name          string
+	extensions    []string
+	newDetector   func() (codefile.Detector, error)
+	selfContained bool
+}{
+	{name: "java", extensions: []string{".java"}, newDetector: func() (codefile.Detector, error) { return codefile.NewJavaReachableDetector() }, selfContained: true},
+	{name: "java", extensions: []string{".kt", ".kts"}, newDetector: func() (codefile.Detector, error) { return codefile.NewKotlinReachableDetector() }, selfContained: true},
+	{name: "java", extensions: []string{".scala", ".sc"}, newDetector: func() (codefile.Detector, error) { return codefile.NewScalaReachableDetector() }, selfContained: true},
+	{name: "java", extensions: []string{".class", ".jar"}, newDetector: func() (codefile.Detector, error) { return codefile.NewBytecodeReachableDetector() }, selfContained: true},
+	{name: "python", extensions: []string{".py"}, newDetector: func() (codefile.Detector, error) { return codefile.NewPythonReachableDetector() }, selfContained: true},
+	{
+		// Local modules are followed through their re-exports
+		name:        "javascript",
+		extensions:  []string{".js", ".mjs", ".cjs", ".jsx", ".ts", ".tsx", ".mts", ".cts"},
+		newDetector: func() (codefile.Detector, error) { return codefile.NewJavaScriptReachableDetector() },
+	},
+	// Go files are type checked along with their package and module, Ruby files follow their requires,
+	// and C# files use the global usings of their project
+	{name: "go", extensions: []string{".go"}, newDetector: func() (codefile.Detector, error) { return codefile.NewGoReachableDetector() }},
+	{name: "ruby", extensions: []string{".rb"}, newDetector: func() (codefile.Detector, error) { return codefile.NewRubyReachableDetector() }},
+	{name: "csharp", extensions: []string{".cs"}, newDetector: func() (codefile.Detector, error) { return codefile.NewCSharpReachableDetector() }},
+}
+
+// findLanguage returns the index in languages of the language of a source file, if it is supported
+func findLanguage(path string) (int, bool) {
+	extension := filepath.Ext(path)
+	for index, language := range languages {
+		if slices.Contains(language.extensions, extension) {
+			return index, true
+		}
+	}
+
+	return 0, false
+}
+
+// PerformReachabilityAnalysis performs a reachability analysis on the given PURLs, looking for their usages in the
+// given source files (see SourceFileCollector).
+// The vulnerable symbols are fetched from the Datadog API, unless vulnerableSymbols is given (see LoadVulnerableSymbols).
+// The detection results of the files are cached in cacheDir, unless it is empty.
+// The locations are tagged as test or production code, the test files being the ones matching the conventions
+// of their ecosystem or the given .gitignore patterns.
+func PerformReachabilityAnalysis(purls []string, sourceFiles []SourceFile, enabled bool, vulnerableSymbols *http.ResolveVulnerableSymbolsResponse, cacheDir string, testSourcePatterns []string, ddBaseURL string, ddJwtToken string) models.ReachabilityAnalysis {
 	if !enabled {
 		log.Println("reachability analysis is disabled")
 		return models.ReachabilityAnalysis{}
 	}
 
-	log.Println("fetching symbols to perform a reachability analysis")
-	resp, err := http.PostResolveVulnerableSymbols(purls, ddBaseURL, ddJwtToken)
-	if err != nil {
-		log.Printf("failed to fetch symbols for reachability analysis: %v\n", err)
-		log.Println("continuing without reachability information")
+	var resp http.ResolveVulnerableSymbolsResponse
+	if vulnerableSymbols != nil {
+		resp = filterVulnerableSymbols(*vulnerableSymbols, purls)
+	} else {
+		log.Println("fetching symbols to perform a reachability analysis")
+		var err error
+		resp, err = http.PostResolveVulnerableSymbols(purls, ddBaseURL, ddJwtToken)
+		if err != nil {
+			log.Printf("failed to fetch symbols for reachability analysis: %v\n", err)
+			log.Println("continuing without reachability information")
 
-		return models.ReachabilityAnalysis{}
+			return models.ReachabilityAnalysis{}
+		}
 	}
 
 	advisoriesToCheckPerLanguage := getAdvisoriesToCheckPerLanguage(resp)
+	cache := newDetectionCache(cacheDir, advisoriesToCheckPerLanguage)
 
-	javaReachabilityDetector, err := codefile.NewJavaReachableDetector()
+	detectionResults, err := detectSourceFiles(sourceFiles, advisoriesToCheckPerLanguage, cache, newTestSourceMatcher(testSourcePatterns))
 	if err != nil {
-		log.Fatalf("failed to create Java reachability detector: %v", err)
+		log.Printf("error analyzing the source files: %v\n", err)
+		return models.ReachabilityAnalysis{}
 	}
-	defer javaReachabilityDetector.Close()
 
-	detectionResults := make(models.DetectionResults)
+	purlToReachabilityAnalysisResults := getPurlsToReachabilityAnalysisResults(advisoriesToCheckPerLanguage, detectionResults)
 
-	for _, dir := range directoryPaths {
-		err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
-			if err != nil {
-				return err
-			}
-			if d.IsDir() {
-				return nil
+	return models.ReachabilityAnalysis{
+		PurlToReachabilityAnalysisResults: purlToReachabilityAnalysisResults,
+	}
+}
+
+// sourceFileJob is a file to analyze, along with the index of its language
+type sourceFileJob struct {
+	SourceFile
+
+	language int
+}
+
+// detectSourceFiles detects the usages of the symbols of the advisories in the source files with a pool of workers,
+// each one having its own detectors as they are not safe for concurrent use.
+// The files of a directory are analyzed by the same worker, as the detectors of some languages share their work
+// between the files of a package or a project, and the results are merged in the order of the files.
+func detectSourceFiles(sourceFiles []SourceFile, advisoriesToCheckPerLanguage map[string][]models.AdvisoryToCheck, cache *detectionCache, tests *testSourceMatcher) (models.DetectionResults, error) {
+	var groups [][]int
+	var jobs []sourceFileJob
+	groupOfDir := make(map[string]int)
+	for _, file := range sourceFiles {
+		language, ok := findLanguage(file.Path)
+		// Only the files of the languages having advisories to check are analyzed
+		if !ok || len(advisoriesToCheckPerLanguage[languages[language].name]) == 0 {
+			continue
+		}
+
+		dir := filepath.Dir(file.Path)
+		group, ok := groupOfDir[dir]
+		if !ok {
+			group = len(groups)
+			groupOfDir[dir] = group
+			groups = append(groups, nil)
+		}
+		groups[group] = append(groups[group], len(jobs))
+		jobs = append(jobs, sourceFileJob{SourceFile: file, language: language})
+	}
+
+	results := make([]models.DetectionResults, len(jobs))
+	errs := make([]error, runtime.GOMAXPROCS(0))
+	pending := make(chan []int)
+	var wg sync.WaitGroup
+	for worker := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			detectors := make(map[int]codefile.Detector)
+			defer func() {
+				for _, detector := range detectors {
+					detector.Close()
+				}
+			}()
+
+			for group := range pending {
+				for _, index := range group {
+					if errs[worker] != nil {
+						// The remaining groups are drained so that the other workers are not blocked
+						break
+					}
+					results[index], errs[worker] = detectSourceFile(jobs[index], detectors, advisoriesToCheckPerLanguage, cache)
+					tagSourceType(results[index], tests.sourceType(jobs[index].SourceFile))
+				}
 			}
+		}()
+	}
+	for _, group := range groups {
+		pending <- group
+	}
+	close(pending)
+	wg.Wait()
 
-			switch filepath.Ext(d.Name()) {
-			case ".java":
-				err = javaReachabilityDetector.Detect(dir, path, detectionResults, advisoriesToCheckPerLanguage["java"])
-			default:
-				return nil
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	detectionResults := make(models.DetectionResults)
+	for _, fileResults := range results {
+		for purl, advisories := range fileResults {
+			if _, ok := detectionResults[purl]; !ok {
+				detectionResults[purl] = make(map[string]models.ReachableSymbolLocations)
 			}
+			for advisoryID, locations := range advisories {
+				detectionResults[purl][advisoryID] = append(detectionResults[purl][advisoryID], locations...)
+			}
+		}
+	}
 
-			return err
-		})
+	return detectionResults, nil
+}
 
+// detectSourceFile detects the usages of the symbols of the advisories in a file, or loads them from the cache
+func detectSourceFile(job sourceFileJob, detectors map[int]codefile.Detector, advisoriesToCheckPerLanguage map[string][]models.AdvisoryToCheck, cache *detectionCache) (models.DetectionResults, error) {
+	language := languages[job.language]
+
+	var key string
+	if cache != nil && language.selfContained {
+		content, err := os.ReadFile(job.Path)
 		if err != nil {
-			log.Printf("error walking the path: %v\n", err)
-			return models.ReachabilityAnalysis{}
+			return nil, err
+		}
+		key = cache.key(language.name, filepath.Ext(job.Path), content)
+		if detectionResults, ok := cache.load(key, job.SourceFile); ok {
+			return detectionResults, nil
 		}
 	}
 
-	purlToReachabilityAnalysisResults := getPurlsToReachabilityAnalysisResults(advisoriesToCheckPerLanguage, detectionResults)
+	detector, ok := detectors[job.language]
+	if !ok {
+		var err error
+		detector, err = language.newDetector()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s reachability detector: %w", language.name, err)
+		}
+		detectors[job.language] = detector
+	}
 
-	return models.ReachabilityAnalysis{
-		PurlToReachabilityAnalysisResults: purlToReachabilityAnalysisResults,
+	detectionResults := make(models.DetectionResults)
+	if err := detector.Detect(job.Dir, job.Path, detectionResults, advisoriesToCheckPerLanguage[language.name]); err != nil {
+		return nil, err
+	}
+	if key != "" {
+		cache.store(key, job.SourceFile, detectionResults)
+	}
+
+	return detectionResults, nil
+}
+
+// tagSourceType records whether the locations of a file are in the production code or in the tests
+func tagSourceType(detectionResults models.DetectionResults, sourceType models.SourceType) {
+	for _, advisories := range detectionResults {
+		for _, locations := range advisories {
+			for i := range locations {
+				locations[i].SourceType = sourceType
+			}
+		}
 	}
 }
@@ -0,0 +1,130 @@
+package reachability
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+	"github.com/DataDog/datadog-sbom-generator/pkg/reachability/codefile"
+)
+
+var greeterAdvisories = map[string][]models.AdvisoryToCheck{
+	"java": {
+		{
+			Purl:       "pkg:maven/org.example/greeter@1.2.3",
+			AdvisoryID: "GHSA-aaaa-bbbb-cccc",
+			Symbols:    []models.Symbols{{Type: "class", Value: "org.example", Name: "Greeter"}},
+		},
+	},
+}
+
+// writeGreeterFiles copies a Java file using the Greeter class to the given paths of a temporary directory
+func writeGreeterFiles(t *testing.T, paths ...string) []SourceFile {
+	t.Helper()
+
+	content, err := os.ReadFile("codefile/testdata/CVE-2025-1234/explicit-import/class.java")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	files := make([]SourceFile, 0, len(paths))
+	for _, path := range paths {
+		path = filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, content, 0o600))
+		files = append(files, SourceFile{Dir: dir, Path: path})
+	}
+
+	return files
+}
+
+func locationFilenames(locations models.ReachableSymbolLocations) []string {
+	filenames := make([]string, 0, len(locations))
+	for _, location := range locations {
+		filenames = append(filenames, location.PackageLocation.Filename)
+	}
+
+	return filenames
+}
+
+func Test_detectSourceFiles_MergedInOrder(t *testing.T) {
+	t.Parallel()
+
+	paths := []string{"d/Example.java", "a/Example.java", "c/Example.java", "a/Other.java", "b/Example.java", "a/README.md"}
+	files := writeGreeterFiles(t, paths...)
+
+	detectionResults, err := detectSourceFiles(files, greeterAdvisories, nil, newTestSourceMatcher(nil))
+	require.NoError(t, err)
+
+	locations := detectionResults["pkg:maven/org.example/greeter@1.2.3"]["GHSA-aaaa-bbbb-cccc"]
+	assert.Equal(t, paths[:5], locationFilenames(locations))
+}
+
+func Test_detectSourceFiles_Cache(t *testing.T) {
+	t.Parallel()
+
+	files := writeGreeterFiles(t, "a/Example.java", "b/Example.java")
+	cacheDir := t.TempDir()
+
+	cache := newDetectionCache(cacheDir, greeterAdvisories)
+	detectionResults, err := detectSourceFiles(files, greeterAdvisories, cache, newTestSourceMatcher(nil))
+	require.NoError(t, err)
+	locations := detectionResults["pkg:maven/org.example/greeter@1.2.3"]["GHSA-aaaa-bbbb-cccc"]
+	require.Len(t, locations, 2)
+
+	// Both files have the same content, so they share their entry
+	entries, err := filepath.Glob(filepath.Join(cacheDir, "*"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// The files are not parsed again, the locations of the entry being moved to each file
+	cached := models.DetectionResults{
+		"pkg:maven/org.example/greeter@1.2.3": {
+			"GHSA-aaaa-bbbb-cccc": {{Symbol: "Greeter", PackageLocation: models.PackageLocation{Filename: "a/Example.java", LineStart: 99}}},
+		},
+	}
+	cache.store(strings.TrimSuffix(filepath.Base(entries[0]), ".json"), files[0], cached)
+
+	detectionResults, err = detectSourceFiles(files, greeterAdvisories, newDetectionCache(cacheDir, greeterAdvisories), newTestSourceMatcher(nil))
+	require.NoError(t, err)
+	assert.Equal(t, models.ReachableSymbolLocations{
+		{Symbol: "Greeter", PackageLocation: models.PackageLocation{Filename: "a/Example.java", LineStart: 99}, SourceType: models.ProductionSource},
+		{Symbol: "Greeter", PackageLocation: models.PackageLocation{Filename: "b/Example.java", LineStart: 99}, SourceType: models.ProductionSource},
+	}, detectionResults["pkg:maven/org.example/greeter@1.2.3"]["GHSA-aaaa-bbbb-cccc"])
+
+	// Checking other advisories parses the files again
+	otherAdvisories := map[string][]models.AdvisoryToCheck{
+		"java": append(greeterAdvisories["java"], models.AdvisoryToCheck{
+			Purl:       "pkg:maven/org.example/unused@7.8.9",
+			AdvisoryID: "GHSA-gggg-hhhh-iiii",
+			Symbols:    []models.Symbols{{Type: "class", Value: "org.example", Name: "Unused"}},
+		}),
+	}
+	detectionResults, err = detectSourceFiles(files, otherAdvisories, newDetectionCache(cacheDir, otherAdvisories), newTestSourceMatcher(nil))
+	require.NoError(t, err)
+	assert.Equal(t, locations, detectionResults["pkg:maven/org.example/greeter@1.2.3"]["GHSA-aaaa-bbbb-cccc"])
+
+	entries, err = filepath.Glob(filepath.Join(cacheDir, "*"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+//nolint:paralleltest // Replaces the detector of Java, so it cannot run along with the other tests
+func Test_detectSourceFiles_DetectorError(t *testing.T) {
+	files := writeGreeterFiles(t, "a/Example.java", "b/Example.java")
+
+	index, _ := findLanguage("Example.java")
+	newDetector := languages[index].newDetector
+	t.Cleanup(func() { languages[index].newDetector = newDetector })
+	languages[index].newDetector = func() (codefile.Detector, error) {
+		return nil, errors.New("unsupported grammar")
+	}
+
+	_, err := detectSourceFiles(files, greeterAdvisories, nil, newTestSourceMatcher(nil))
+	require.ErrorContains(t, err, "failed to create java reachability detector: unsupported grammar")
+}
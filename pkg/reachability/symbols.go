@@ -0,0 +1,38 @@
+package reachability
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/http"
+)
+
+// LoadVulnerableSymbols reads the vulnerable symbols to look for from a file instead of the Datadog API.
+// The file holds a response of the resolve-vulnerable-symbols endpoint, either as JSON:API or as plain JSON.
+func LoadVulnerableSymbols(path string) (*http.ResolveVulnerableSymbolsResponse, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vulnerable symbols: %w", err)
+	}
+
+	resp, err := http.ParseResolveVulnerableSymbolsResponse(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse vulnerable symbols from %s: %w", path, err)
+	}
+
+	return &resp, nil
+}
+
+// filterVulnerableSymbols keeps the symbols of the given PURLs only, like the API which only returns the symbols
+// of the PURLs it is queried for
+func filterVulnerableSymbols(resp http.ResolveVulnerableSymbolsResponse, purls []string) http.ResolveVulnerableSymbolsResponse {
+	filtered := http.ResolveVulnerableSymbolsResponse{ID: resp.ID}
+	for _, result := range resp.Results {
+		if slices.Contains(purls, result.Purl) {
+			filtered.Results = append(filtered.Results, result)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,60 @@
+package reachability
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+)
+
+func TestLoadVulnerableSymbols(t *testing.T) {
+	t.Parallel()
+
+	resp, err := LoadVulnerableSymbols("testdata/vulnerable-symbols.json")
+	require.NoError(t, err)
+	assert.Equal(t, "captured-response", resp.ID)
+	assert.Len(t, resp.Results, 2)
+
+	_, err = LoadVulnerableSymbols("testdata/does-not-exist.json")
+	require.Error(t, err)
+}
+
+func TestPerformReachabilityAnalysis_VulnerableSymbolsFile(t *testing.T) {
+	t.Parallel()
+
+	resp, err := LoadVulnerableSymbols("testdata/vulnerable-symbols.json")
+	require.NoError(t, err)
+
+	analysis := PerformReachabilityAnalysis(
+		[]string{"pkg:maven/org.example/greeter@1.2.3"},
+		[]SourceFile{{Dir: "codefile/testdata/CVE-2025-1234/explicit-import", Path: "codefile/testdata/CVE-2025-1234/explicit-import/class.java"}},
+		true,
+		resp,
+		"",
+		nil,
+		"",
+		"",
+	)
+
+	// Symbols of packages which were not scanned are not checked
+	require.Len(t, analysis.PurlToReachabilityAnalysisResults, 1)
+	results := analysis.PurlToReachabilityAnalysisResults["pkg:maven/org.example/greeter@1.2.3"]
+	require.NotNil(t, results)
+	assert.Equal(t, []string{"GHSA-aaaa-bbbb-cccc"}, results.AdvisoryIdsChecked)
+	require.Len(t, results.ReachableVulnerabilities, 1)
+	assert.Equal(t, models.ReachableSymbolLocations{
+		{
+			Symbol: "Greeter",
+			PackageLocation: models.PackageLocation{
+				Filename:    "codefile/testdata/CVE-2025-1234/explicit-import/class.java",
+				LineStart:   8,
+				LineEnd:     8,
+				ColumnStart: 29,
+				ColumnEnd:   36,
+			},
+			SourceType: models.ProductionSource,
+		},
+	}, results.ReachableVulnerabilities[0].ReachableSymbolLocations)
+}
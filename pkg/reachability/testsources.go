@@ -0,0 +1,73 @@
+package reachability
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// defaultTestSourcePatterns lists the conventional locations of the tests of each ecosystem, as .gitignore patterns
+var defaultTestSourcePatterns = []string{
+	// Maven and Gradle source sets, such as src/test/java or src/integrationTest/kotlin
+	"**/src/test/",
+	"**/src/it/",
+	"**/src/testFixtures/",
+	"**/src/*Test/",
+	// Jest and the other JavaScript test runners
+	"__tests__/",
+	"__mocks__/",
+	"*.test.*",
+	"*.spec.*",
+	// RSpec and Minitest, whose directories are also the ones of the tests of Python and JavaScript projects
+	"spec/",
+	"test/",
+	"tests/",
+	"*_spec.rb",
+	"*_test.rb",
+	// pytest
+	"test_*.py",
+	"*_test.py",
+	"conftest.py",
+	// go test
+	"*_test.go",
+	"testdata/",
+	// .NET test projects
+	"*.Tests/",
+	"*.Test/",
+	"*.UnitTests/",
+	"*.IntegrationTests/",
+}
+
+// testSourceMatcher tells the test source files apart from the production ones, from their path in the scanned directory
+type testSourceMatcher struct {
+	matcher gitignore.Matcher
+}
+
+// newTestSourceMatcher returns a matcher of the conventional test locations along with the given .gitignore patterns,
+// which take precedence over the conventional ones and can exclude some of them with a leading "!"
+func newTestSourceMatcher(patterns []string) *testSourceMatcher {
+	parsed := make([]gitignore.Pattern, 0, len(defaultTestSourcePatterns)+len(patterns))
+	for _, pattern := range append(defaultTestSourcePatterns, patterns...) {
+		parsed = append(parsed, gitignore.ParsePattern(pattern, nil))
+	}
+
+	return &testSourceMatcher{matcher: gitignore.NewMatcher(parsed)}
+}
+
+// sourceType returns whether a file holds tests or production code
+func (m *testSourceMatcher) sourceType(file SourceFile) models.SourceType {
+	path, err := filepath.Rel(file.Dir, file.Path)
+	if err != nil {
+		path = file.Path
+	}
+
+	// The directories above the scanned one are not part of the project, so they are not checked
+	if m.matcher.Match(strings.Split(filepath.ToSlash(path), "/"), false) {
+		return models.TestSource
+	}
+
+	return models.ProductionSource
+}
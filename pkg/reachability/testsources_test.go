@@ -0,0 +1,50 @@
+package reachability
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+)
+
+func Test_testSourceMatcher_sourceType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path     string
+		expected models.SourceType
+	}{
+		{path: "src/main/java/com/example/App.java", expected: models.ProductionSource},
+		{path: "src/test/java/com/example/AppTest.java", expected: models.TestSource},
+		{path: "service/src/integrationTest/kotlin/AppIT.kt", expected: models.TestSource},
+		{path: "service/src/testFixtures/scala/Fixtures.scala", expected: models.TestSource},
+		{path: "src/components/__tests__/Button.jsx", expected: models.TestSource},
+		{path: "src/components/Button.test.tsx", expected: models.TestSource},
+		{path: "src/components/Button.tsx", expected: models.ProductionSource},
+		{path: "spec/models/user_spec.rb", expected: models.TestSource},
+		{path: "app/models/user.rb", expected: models.ProductionSource},
+		{path: "tests/test_app.py", expected: models.TestSource},
+		{path: "app/conftest.py", expected: models.TestSource},
+		{path: "app/testing.py", expected: models.ProductionSource},
+		{path: "internal/parser/parser_test.go", expected: models.TestSource},
+		{path: "MyApp.Tests/ParserTests.cs", expected: models.TestSource},
+		{path: "MyApp/Parser.cs", expected: models.ProductionSource},
+		// Patterns given by the user are checked after the conventional ones
+		{path: "qa/smoke.py", expected: models.TestSource},
+		{path: "tests/fixtures/server.py", expected: models.ProductionSource},
+	}
+
+	matcher := newTestSourceMatcher([]string{"qa/", "!tests/fixtures/"})
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			// The directories above the scanned one are ignored, even when they are named like test directories
+			dir := filepath.Join("test", "repository")
+			file := SourceFile{Dir: dir, Path: filepath.Join(dir, filepath.FromSlash(tt.path))}
+			assert.Equal(t, tt.expected, matcher.sourceType(file))
+		})
+	}
+}
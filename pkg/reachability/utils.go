@@ -1,16 +1,40 @@
 package reachability
 
 import (
+	"log"
+
+	"github.com/package-url/packageurl-go"
+
 	"github.com/DataDog/datadog-sbom-generator/internal/http"
 	"github.com/DataDog/datadog-sbom-generator/pkg/models"
 )
 
+// purlTypeToLanguage maps the types of the PURLs of packages to the language of the source files using them
+var purlTypeToLanguage = map[string]string{
+	packageurl.TypeMaven:  "java",
+	packageurl.TypePyPi:   "python",
+	packageurl.TypeNPM:    "javascript",
+	packageurl.TypeGolang: "go",
+	packageurl.TypeGem:    "ruby",
+	packageurl.TypeNuget:  "csharp",
+}
+
 // getAdvisoriesToCheckPerLanguage returns a map of language to advisories with symbols to check.
+// Advisories are routed to a language based on the type of the PURL of their package.
 func getAdvisoriesToCheckPerLanguage(resp http.ResolveVulnerableSymbolsResponse) models.AdvisoriesToCheckPerLanguage {
 	output := models.AdvisoriesToCheckPerLanguage{}
 
 	for _, result := range resp.Results {
-		language := "java"
+		purl, err := packageurl.FromString(result.Purl)
+		if err != nil {
+			log.Printf("skipping vulnerable symbols of invalid PURL %s: %v\n", result.Purl, err)
+			continue
+		}
+		language, ok := purlTypeToLanguage[purl.Type]
+		if !ok {
+			log.Printf("skipping vulnerable symbols of %s: reachability is not supported for %s packages\n", result.Purl, purl.Type)
+			continue
+		}
 
 		// Initialize a slice for the language if it doesn't exist
 		if _, languageExists := output[language]; !languageExists {
@@ -126,6 +126,32 @@ func Test_getAdvisoriesToCheckPerLanguage_HasAdvisoriesToCheck(t *testing.T) {
 	assert.Equal(t, expected, advisoriesToCheckPerLanguage)
 }
 
+func Test_getAdvisoriesToCheckPerLanguage_RoutedByPurlType(t *testing.T) {
+	t.Parallel()
+
+	symbolDetails := []http.SymbolDetails{{AdvisoryID: "GHSA-1234", Symbols: []http.Symbol{{Type: "function", Value: "yaml", Name: "load"}}}}
+	resolveVulnerableSymbolsResponse := http.ResolveVulnerableSymbolsResponse{
+		Results: []http.SymbolsForPurl{
+			{Purl: "pkg:pypi/pyyaml@5.3", VulnerableSymbols: symbolDetails},
+			{Purl: "pkg:maven/org.example/foo@1.2.3", VulnerableSymbols: symbolDetails},
+			{Purl: "pkg:golang/golang.org/x/net@0.17.0", VulnerableSymbols: symbolDetails},
+			// Unsupported or invalid PURLs are skipped
+			{Purl: "pkg:hex/foo@1.0.0", VulnerableSymbols: symbolDetails},
+			{Purl: "not-a-purl", VulnerableSymbols: symbolDetails},
+		},
+	}
+
+	advisoriesToCheckPerLanguage := getAdvisoriesToCheckPerLanguage(resolveVulnerableSymbolsResponse)
+
+	assert.Len(t, advisoriesToCheckPerLanguage, 3)
+	assert.Len(t, advisoriesToCheckPerLanguage["python"], 1)
+	assert.Equal(t, "pkg:pypi/pyyaml@5.3", advisoriesToCheckPerLanguage["python"][0].Purl)
+	assert.Len(t, advisoriesToCheckPerLanguage["java"], 1)
+	assert.Equal(t, "pkg:maven/org.example/foo@1.2.3", advisoriesToCheckPerLanguage["java"][0].Purl)
+	assert.Len(t, advisoriesToCheckPerLanguage["go"], 1)
+	assert.Equal(t, "pkg:golang/golang.org/x/net@0.17.0", advisoriesToCheckPerLanguage["go"][0].Purl)
+}
+
 func Test_getPurlsToReachabilityAnalysisResults_Empty(t *testing.T) {
 	t.Parallel()
 
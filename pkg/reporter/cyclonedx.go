@@ -13,18 +13,26 @@ import (
 )
 
 type CycloneDXReporter struct {
-	hasErrored bool
-	stdout     io.Writer
-	stderr     io.Writer
-	level      VerbosityLevel
+	hasErrored  bool
+	stdout      io.Writer
+	stderr      io.Writer
+	specVersion cyclonedx.SpecVersion
+	level       VerbosityLevel
 }
 
+// NewCycloneDXReporter creates a reporter printing CycloneDX 1.5 SBOMs
 func NewCycloneDXReporter(stdout, stderr io.Writer, level VerbosityLevel) *CycloneDXReporter {
+	return NewCycloneDXReporterWithVersion(stdout, stderr, cyclonedx.SpecVersion1_5, level)
+}
+
+// NewCycloneDXReporterWithVersion is the same as NewCycloneDXReporter, but allows to choose the version of the CycloneDX specification (1.5 or 1.6)
+func NewCycloneDXReporterWithVersion(stdout, stderr io.Writer, specVersion cyclonedx.SpecVersion, level VerbosityLevel) *CycloneDXReporter {
 	return &CycloneDXReporter{
-		stdout:     stdout,
-		stderr:     stderr,
-		hasErrored: false,
-		level:      level,
+		stdout:      stdout,
+		stderr:      stderr,
+		hasErrored:  false,
+		specVersion: specVersion,
+		level:       level,
 	}
 }
 
@@ -56,7 +64,7 @@ func (r *CycloneDXReporter) Verbosef(format string, a ...any) {
 }
 
 func (r *CycloneDXReporter) PrintResult(vulnerabilityResults *models.VulnerabilityResults) error {
-	errs := output.PrintCycloneDXResults(vulnerabilityResults, r.stdout)
+	errs := output.PrintCycloneDXResults(vulnerabilityResults, r.specVersion, r.stdout)
 	if errs != nil {
 		for _, err := range strings.Split(errs.Error(), "\n") {
 			r.Warnf("Failed to parse package URL: %v", err)
@@ -69,5 +77,10 @@ func (r *CycloneDXReporter) PrintResult(vulnerabilityResults *models.Vulnerabili
 // BuildCycloneDXBOM is only intended to be used when datadog-sbom-generator is used as a library as opposed to the CLI,
 // it has been written here to avoid being in an internal package which triggers linting issues
 func BuildCycloneDXBOM(vulnerabilityResults *models.VulnerabilityResults) (*cyclonedx.BOM, error) {
-	return output.CreateCycloneDXBOM(vulnerabilityResults)
+	return BuildCycloneDXBOMWithVersion(vulnerabilityResults, cyclonedx.SpecVersion1_5)
+}
+
+// BuildCycloneDXBOMWithVersion is the same as BuildCycloneDXBOM, but allows to choose the version of the CycloneDX specification (1.5 or 1.6)
+func BuildCycloneDXBOMWithVersion(vulnerabilityResults *models.VulnerabilityResults, specVersion cyclonedx.SpecVersion) (*cyclonedx.BOM, error) {
+	return output.CreateCycloneDXBOM(vulnerabilityResults, specVersion)
 }
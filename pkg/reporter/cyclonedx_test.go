@@ -3,8 +3,12 @@ package reporter_test
 import (
 	"bytes"
 	"io"
+	"strings"
 	"testing"
 
+	"github.com/CycloneDX/cyclonedx-go"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
 	"github.com/DataDog/datadog-sbom-generator/pkg/reporter"
 )
 
@@ -101,3 +105,43 @@ func TestCycloneDXReporter_Verbosef(t *testing.T) {
 		}
 	}
 }
+
+func TestCycloneDXReporter_PrintResult_SpecVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		reporter func(stdout io.Writer) *reporter.CycloneDXReporter
+		expected string
+	}{
+		{
+			name: "default",
+			reporter: func(stdout io.Writer) *reporter.CycloneDXReporter {
+				return reporter.NewCycloneDXReporter(stdout, io.Discard, reporter.ErrorLevel)
+			},
+			expected: `"specVersion": "1.5"`,
+		},
+		{
+			name: "1.6",
+			reporter: func(stdout io.Writer) *reporter.CycloneDXReporter {
+				return reporter.NewCycloneDXReporterWithVersion(stdout, io.Discard, cyclonedx.SpecVersion1_6, reporter.ErrorLevel)
+			},
+			expected: `"specVersion": "1.6"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			writer := &bytes.Buffer{}
+			if err := test.reporter(writer).PrintResult(&models.VulnerabilityResults{}); err != nil {
+				t.Fatalf("PrintResult() error = %v", err)
+			}
+
+			if !strings.Contains(writer.String(), test.expected) {
+				t.Errorf("expected the SBOM to contain %s, got %s", test.expected, writer.String())
+			}
+		})
+	}
+}
@@ -3,9 +3,11 @@ package reporter
 import (
 	"fmt"
 	"io"
+
+	"github.com/CycloneDX/cyclonedx-go"
 )
 
-var format = []string{"json", "cyclonedx-1-5"}
+var format = []string{"json", "cyclonedx-1-5", "cyclonedx-1-6", "spdx-2-3"}
 
 func Format() []string {
 	return format
@@ -19,6 +21,10 @@ func New(format string, stdout, stderr io.Writer, level VerbosityLevel) (Reporte
 		return NewJSONReporter(stdout, stderr, level), nil
 	case "cyclonedx-1-5":
 		return NewCycloneDXReporter(stdout, stderr, level), nil
+	case "cyclonedx-1-6":
+		return NewCycloneDXReporterWithVersion(stdout, stderr, cyclonedx.SpecVersion1_6, level), nil
+	case "spdx-2-3":
+		return NewSPDXReporter(stdout, stderr, level), nil
 	default:
 		return nil, fmt.Errorf("%v is not a valid format", format)
 	}
@@ -0,0 +1,73 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spdx/tools-golang/spdx/v2/v2_3"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/output"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+)
+
+type SPDXReporter struct {
+	hasErrored bool
+	stdout     io.Writer
+	stderr     io.Writer
+	level      VerbosityLevel
+}
+
+func NewSPDXReporter(stdout, stderr io.Writer, level VerbosityLevel) *SPDXReporter {
+	return &SPDXReporter{
+		stdout:     stdout,
+		stderr:     stderr,
+		hasErrored: false,
+		level:      level,
+	}
+}
+
+func (r *SPDXReporter) Errorf(format string, a ...any) {
+	fmt.Fprintf(r.stderr, format, a...)
+	r.hasErrored = true
+}
+
+func (r *SPDXReporter) HasErrored() bool {
+	return r.hasErrored
+}
+
+func (r *SPDXReporter) Warnf(format string, a ...any) {
+	if WarnLevel <= r.level {
+		fmt.Fprintf(r.stderr, format, a...)
+	}
+}
+
+func (r *SPDXReporter) Infof(format string, a ...any) {
+	if InfoLevel <= r.level {
+		fmt.Fprintf(r.stderr, format, a...)
+	}
+}
+
+func (r *SPDXReporter) Verbosef(format string, a ...any) {
+	if VerboseLevel <= r.level {
+		fmt.Fprintf(r.stderr, format, a...)
+	}
+}
+
+func (r *SPDXReporter) PrintResult(vulnerabilityResults *models.VulnerabilityResults) error {
+	errs := output.PrintSPDXResults(vulnerabilityResults, r.stdout)
+	if errs != nil {
+		for _, err := range strings.Split(errs.Error(), "\n") {
+			r.Warnf("Failed to parse package URL: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// BuildSPDXDocument is only intended to be used when datadog-sbom-generator is used as a library as opposed to the CLI,
+// it has been written here to avoid being in an internal package which triggers linting issues
+func BuildSPDXDocument(vulnerabilityResults *models.VulnerabilityResults) (*v2_3.Document, error) {
+	return output.CreateSPDXDocument(vulnerabilityResults)
+}
@@ -3,13 +3,17 @@ package scanner
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/DataDog/datadog-sbom-generator/internal/customgitignore"
+	"github.com/DataDog/datadog-sbom-generator/internal/http"
+	"github.com/DataDog/datadog-sbom-generator/internal/local"
 	"github.com/DataDog/datadog-sbom-generator/internal/output"
+	"github.com/DataDog/datadog-sbom-generator/internal/policy"
 	"github.com/DataDog/datadog-sbom-generator/internal/utility/fileposition"
 	"github.com/DataDog/datadog-sbom-generator/internal/utility/purl"
 	"github.com/DataDog/datadog-sbom-generator/pkg/lockfile"
@@ -27,7 +31,24 @@ type ScannerActions struct {
 	Reachability   bool
 	Debug          bool
 	EnableParsers  []string
-	DDEnvVars      DDEnvVars
+	// OSVDatabasePath is a directory of OSV advisories used to match vulnerabilities without any network access
+	OSVDatabasePath string
+	// FailOn lists the policy rules (such as "severity>=high" or "reachable") making the scan return VulnerabilitiesFoundErr
+	FailOn []string
+	// RootFS makes DirectoryPaths be scanned as the root filesystems of container images, including OS packages
+	RootFS bool
+	// GitRef makes DirectoryPaths be scanned at the given git revision, read from the object database of their repository
+	GitRef string
+	// ImagePaths lists container images to scan, stored as `docker save` archives or OCI image layouts
+	ImagePaths []string
+	// VulnerableSymbolsPath is a file of vulnerable symbols used by the reachability analysis instead of the Datadog API
+	VulnerableSymbolsPath string
+	// ReachabilityCacheDir is a directory caching the detections of the reachability analysis across scans
+	ReachabilityCacheDir string
+	// TestSourcePatterns are .gitignore patterns of the test files of the reachability analysis, in addition to the
+	// conventional locations of the tests of each ecosystem
+	TestSourcePatterns []string
+	DDEnvVars          DDEnvVars
 }
 
 type DDEnvVars struct {
@@ -40,8 +61,8 @@ type DDEnvVars struct {
 //nolint:errname,stylecheck // Would require version major bump to change
 var NoPackagesFoundErr = errors.New("no packages found in scan")
 
-// VulnerabilitiesFoundErr includes both vulnerabilities being found or license violations being found,
-// however, will not be raised if only uncalled vulnerabilities are found.
+// VulnerabilitiesFoundErr is raised when vulnerabilities violating the policy given in ScannerActions.FailOn are found,
+// it is never raised when no policy is given.
 //
 //nolint:errname,stylecheck // Would require version major bump to change
 var VulnerabilitiesFoundErr = errors.New("vulnerabilities found")
@@ -52,7 +73,49 @@ var ErrAPIFailed = errors.New("API query failed")
 // scanDir walks through the given directory to try to find any relevant files
 // These include:
 //   - Any lockfiles with scanLockfile
-func scanDir(r reporter.Reporter, dir string, recursive bool, useGitIgnore bool, enabledParsers map[string]bool) ([]lockfile.PackageDetails, []models.ScannedArtifact, error) {
+//   - The source files of the reachability analysis, when sourceFiles is given
+//
+// When rootFS is set, the directory is handled as the root filesystem of an image: absolute paths are resolved inside it
+// and symbolic links are not followed.
+func scanDir(r reporter.Reporter, dir string, recursive bool, useGitIgnore bool, rootFS bool, enabledParsers map[string]bool, sourceFiles *reachability.SourceFileCollector) ([]lockfile.PackageDetails, []models.ScannedArtifact, error) {
+	openDepFile := lockfile.OpenLocalDepFile
+	if rootFS {
+		openDepFile = func(path string) (lockfile.NestedDepFile, error) {
+			return lockfile.OpenRootFSDepFile(dir, path)
+		}
+	}
+
+	var scannedPackages []lockfile.PackageDetails
+	var scannedArtifacts []models.ScannedArtifact
+
+	return scannedPackages, scannedArtifacts, walkDir(r, dir, recursive, useGitIgnore, rootFS, func(path string, absPath string, info os.DirEntry) {
+		if sourceFiles != nil {
+			sourceFiles.Visit(dir, path, info)
+		}
+
+		if !info.IsDir() {
+			if extractor, _ := lockfile.FindExtractor(absPath, enabledParsers); extractor != nil {
+				pkgs, artifact, err := scanLockfile(r, absPath, openDepFile, enabledParsers)
+				if errors.Is(err, lockfile.ErrExtractorNotFound) {
+					// The files matched by their path can be rejected once their content is checked, e.g. executables
+					// which are not Go binaries
+					return
+				}
+				if err != nil {
+					r.Warnf("Attempted to scan lockfile but failed: %s (%v)\n", absPath, err.Error())
+				}
+				scannedPackages = append(scannedPackages, pkgs...)
+				if artifact != nil {
+					scannedArtifacts = append(scannedArtifacts, *artifact)
+				}
+			}
+		}
+	})
+}
+
+// walkDir walks through the files and directories of the given directory which are not excluded by a .gitignore file,
+// nor by the recursive setting, calling visit with their path as walked from dir and their absolute path
+func walkDir(r reporter.Reporter, dir string, recursive bool, useGitIgnore bool, rootFS bool, visit func(path string, absPath string, info os.DirEntry)) error {
 	var ignoreMatcher *gitIgnoreMatcher
 	if useGitIgnore {
 		var err error
@@ -65,29 +128,26 @@ func scanDir(r reporter.Reporter, dir string, recursive bool, useGitIgnore bool,
 
 	root := true
 
-	var scannedPackages []lockfile.PackageDetails
-	var scannedArtifacts []models.ScannedArtifact
-
-	return scannedPackages, scannedArtifacts, filepath.WalkDir(dir, func(path string, info os.DirEntry, err error) error {
+	return filepath.WalkDir(dir, func(path string, info os.DirEntry, err error) error {
 		if err != nil {
 			r.Infof("Failed to walk %s: %v\n", path, err)
 			return err
 		}
 
-		path, err = filepath.Abs(path)
+		absPath, err := filepath.Abs(path)
 		if err != nil {
 			r.Errorf("Failed to walk path %s\n", err)
 			return err
 		}
 
 		if useGitIgnore {
-			match, err := ignoreMatcher.match(path, info.IsDir())
+			match, err := ignoreMatcher.match(absPath, info.IsDir())
 			if err != nil {
-				r.Infof("Failed to resolve gitignore for %s: %v\n", path, err)
+				r.Infof("Failed to resolve gitignore for %s: %v\n", absPath, err)
 				// Don't skip if we can't parse now - potentially noisy for directories with lots of items
 			} else if match {
 				if root { // Don't silently skip if the argument file was ignored.
-					r.Errorf("%s was not scanned because it is excluded by a .gitignore file. Use --no-ignore to scan it.\n", path)
+					r.Errorf("%s was not scanned because it is excluded by a .gitignore file. Use --no-ignore to scan it.\n", absPath)
 				}
 				if info.IsDir() {
 					return filepath.SkipDir
@@ -101,19 +161,13 @@ func scanDir(r reporter.Reporter, dir string, recursive bool, useGitIgnore bool,
 			return filepath.SkipDir
 		}
 
-		if !info.IsDir() {
-			if extractor, _ := lockfile.FindExtractor(path, enabledParsers); extractor != nil {
-				pkgs, artifact, err := scanLockfile(r, path, enabledParsers)
-				if err != nil {
-					r.Warnf("Attempted to scan lockfile but failed: %s (%v)\n", path, err.Error())
-				}
-				scannedPackages = append(scannedPackages, pkgs...)
-				if artifact != nil {
-					scannedArtifacts = append(scannedArtifacts, *artifact)
-				}
-			}
+		if rootFS && info.Type()&fs.ModeSymlink != 0 {
+			// Links of an image filesystem can target files of the host
+			return nil
 		}
 
+		visit(path, absPath, info)
+
 		if !root && !recursive && info.IsDir() {
 			return filepath.SkipDir
 		}
@@ -157,11 +211,11 @@ func (m *gitIgnoreMatcher) match(absPath string, isDir bool) (bool, error) {
 
 // scanLockfile will load, identify, and parse the lockfile path passed in, and add the dependencies specified
 // within to `query`
-func scanLockfile(r reporter.Reporter, path string, enabledParsers map[string]bool) (lockfile.Packages, *models.ScannedArtifact, error) {
+func scanLockfile(r reporter.Reporter, path string, openDepFile func(path string) (lockfile.NestedDepFile, error), enabledParsers map[string]bool) (lockfile.Packages, *models.ScannedArtifact, error) {
 	var err error
 	var parsedLockfile lockfile.Lockfile
 
-	f, err := lockfile.OpenLocalDepFile(path)
+	f, err := openDepFile(path)
 
 	if err == nil {
 		parsedLockfile, err = lockfile.ExtractDeps(f, enabledParsers)
@@ -204,6 +258,17 @@ func initializeEnabledParsers(enabledParsers []string) map[string]bool {
 	return result
 }
 
+// withoutOSPackageExtractors disables the extractors of the packages installed on an operating system, for the scans
+// of directories and git revisions which are not image filesystems
+func withoutOSPackageExtractors(enabledParsers map[string]bool) map[string]bool {
+	result := make(map[string]bool, len(enabledParsers))
+	for parser, enabled := range enabledParsers {
+		result[parser] = enabled && !lockfile.IsOSPackageExtractor(parser)
+	}
+
+	return result
+}
+
 // DoScan Perform datadog-sbom-generator scan action, with optional reporter to output information
 func DoScan(actions ScannerActions, r reporter.Reporter) (models.VulnerabilityResults, error) {
 	enabledParsers := initializeEnabledParsers(actions.EnableParsers)
@@ -219,32 +284,102 @@ func DoScan(actions ScannerActions, r reporter.Reporter) (models.VulnerabilityRe
 		os.Setenv("debug", "true")
 	}
 
-	for _, dir := range actions.DirectoryPaths {
-		r.Infof("Scanning dir %s\n", dir)
-		pkgs, artifacts, err := scanDir(r, dir, actions.Recursive, !actions.NoIgnore, enabledParsers)
+	failurePolicy, err := policy.Parse(actions.FailOn)
+	if err != nil {
+		return models.VulnerabilityResults{}, err
+	}
+
+	var vulnerabilityDB *local.DB
+	if actions.OSVDatabasePath != "" {
+		vulnerabilityDB, err = local.Load(r, actions.OSVDatabasePath)
 		if err != nil {
 			return models.VulnerabilityResults{}, err
 		}
+	}
 
-		// Transforming any path into a relative path to the scanned directory path
-		for index, pkg := range pkgs {
-			pkgs[index].Source.Path = fileposition.ToRelativePath(dir, pkg.Source.Path)
-			pkgs[index].BlockLocation.Filename = fileposition.ToRelativePath(dir, pkg.BlockLocation.Filename)
+	var vulnerableSymbols *http.ResolveVulnerableSymbolsResponse
+	if actions.VulnerableSymbolsPath != "" {
+		vulnerableSymbols, err = reachability.LoadVulnerableSymbols(actions.VulnerableSymbolsPath)
+		if err != nil {
+			return models.VulnerabilityResults{}, err
+		}
+	}
 
-			if pkgs[index].NameLocation != nil {
-				pkgs[index].NameLocation.Filename = fileposition.ToRelativePath(dir, pkg.NameLocation.Filename)
-			}
+	if actions.GitRef != "" && actions.RootFS {
+		return models.VulnerabilityResults{}, errors.New("git revisions cannot be scanned as root filesystems")
+	}
 
-			if pkgs[index].VersionLocation != nil {
-				pkgs[index].VersionLocation.Filename = fileposition.ToRelativePath(dir, pkg.VersionLocation.Filename)
-			}
+	// The reachability analysis checks the source files selected by the scan, rather than walking the directories again
+	var sourceFiles *reachability.SourceFileCollector
+	if actions.Reachability {
+		sourceFiles = &reachability.SourceFileCollector{}
+	}
+	// The source files of git revisions are exported until the end of the reachability analysis
+	var exportDirs []string
+	defer func() {
+		for _, exportDir := range exportDirs {
+			os.RemoveAll(exportDir)
 		}
-		for index, artifact := range artifacts {
-			artifacts[index].Filename = fileposition.ToRelativePath(dir, artifact.Filename)
-			if artifact.DependsOn != nil {
-				artifacts[index].DependsOn.Filename = fileposition.ToRelativePath(dir, artifact.DependsOn.Filename)
+	}()
+
+	// OS packages are only reported for image filesystems
+	dirParsers := enabledParsers
+	if !actions.RootFS {
+		dirParsers = withoutOSPackageExtractors(enabledParsers)
+	}
+
+	for _, dir := range actions.DirectoryPaths {
+		if actions.GitRef != "" {
+			r.Infof("Scanning %s of %s\n", actions.GitRef, dir)
+			pkgs, artifacts, err := scanGitRevision(r, dir, actions.GitRef, actions.Recursive, dirParsers)
+			if err != nil {
+				return models.VulnerabilityResults{}, err
 			}
+			if sourceFiles != nil {
+				exportDir, err := collectGitRevisionSourceFiles(r, dir, actions.GitRef, actions.Recursive, !actions.NoIgnore, sourceFiles)
+				if exportDir != "" {
+					exportDirs = append(exportDirs, exportDir)
+				}
+				if err != nil {
+					return models.VulnerabilityResults{}, err
+				}
+			}
+			scannedPackages = append(scannedPackages, pkgs...)
+			scannedArtifacts = append(scannedArtifacts, artifacts...)
+
+			continue
+		}
+
+		r.Infof("Scanning dir %s\n", dir)
+		// Image filesystems are not git repositories, their content should not be filtered by the .gitignore files of the host
+		useGitIgnore := !actions.NoIgnore && !actions.RootFS
+		pkgs, artifacts, err := scanDir(r, dir, actions.Recursive, useGitIgnore, actions.RootFS, dirParsers, sourceFiles)
+		if err != nil {
+			return models.VulnerabilityResults{}, err
+		}
+		if actions.RootFS {
+			applyDistribution(r, dir, rootFSOpener(dir), pkgs)
 		}
+
+		// Transforming any path into a relative path to the scanned directory path
+		relativizePaths(pkgs, artifacts, func(path string) string {
+			return fileposition.ToRelativePath(dir, path)
+		})
+		scannedPackages = append(scannedPackages, pkgs...)
+		scannedArtifacts = append(scannedArtifacts, artifacts...)
+	}
+
+	for _, imagePath := range actions.ImagePaths {
+		r.Infof("Scanning image %s\n", imagePath)
+		pkgs, artifacts, err := scanImage(r, imagePath, enabledParsers)
+		if err != nil {
+			return models.VulnerabilityResults{}, err
+		}
+
+		// Paths are reported relatively to the root of the image filesystem
+		relativizePaths(pkgs, artifacts, func(path string) string {
+			return strings.TrimPrefix(path, "/")
+		})
 		scannedPackages = append(scannedPackages, pkgs...)
 		scannedArtifacts = append(scannedArtifacts, artifacts...)
 	}
@@ -261,15 +396,48 @@ func DoScan(actions ScannerActions, r reporter.Reporter) (models.VulnerabilityRe
 		}
 	}
 
-	purlsForDirectPackages := getDirectPackagePurls(scannedPackages)
+	// Transitive packages are analyzed as well, as the code of the project can use them without declaring them
+	purlsForPackages := getPackagePurls(scannedPackages)
+
+	var reachabilityFiles []reachability.SourceFile
+	if sourceFiles != nil {
+		reachabilityFiles = sourceFiles.Files
+	}
+	reachabilityAnalysis := reachability.PerformReachabilityAnalysis(purlsForPackages, reachabilityFiles, actions.Reachability, vulnerableSymbols, actions.ReachabilityCacheDir, actions.TestSourcePatterns, actions.DDEnvVars.BaseURL, actions.DDEnvVars.JwtToken)
 
-	reachabilityAnalysis := reachability.PerformReachabilityAnalysis(purlsForDirectPackages, actions.DirectoryPaths, actions.Reachability, actions.DDEnvVars.BaseURL, actions.DDEnvVars.JwtToken)
+	vulnerabilityResults := groupBySource(r, scannedPackages, scannedArtifacts, reachabilityAnalysis, vulnerabilityDB)
 
-	vulnerabilityResults := groupBySource(r, scannedPackages, scannedArtifacts, reachabilityAnalysis)
+	if violations := failurePolicy.Evaluate(vulnerabilityResults); len(violations) > 0 {
+		r.Errorf("%s", policy.Summarize(violations))
+
+		return vulnerabilityResults, VulnerabilitiesFoundErr
+	}
 
 	return vulnerabilityResults, nil
 }
 
+// relativizePaths rewrites the paths of the files the packages and artifacts have been found in
+func relativizePaths(pkgs []lockfile.PackageDetails, artifacts []models.ScannedArtifact, toRelative func(path string) string) {
+	for index, pkg := range pkgs {
+		pkgs[index].Source.Path = toRelative(pkg.Source.Path)
+		pkgs[index].BlockLocation.Filename = toRelative(pkg.BlockLocation.Filename)
+
+		if pkgs[index].NameLocation != nil {
+			pkgs[index].NameLocation.Filename = toRelative(pkg.NameLocation.Filename)
+		}
+
+		if pkgs[index].VersionLocation != nil {
+			pkgs[index].VersionLocation.Filename = toRelative(pkg.VersionLocation.Filename)
+		}
+	}
+	for index, artifact := range artifacts {
+		artifacts[index].Filename = toRelative(artifact.Filename)
+		if artifact.DependsOn != nil {
+			artifacts[index].DependsOn.Filename = toRelative(artifact.DependsOn.Filename)
+		}
+	}
+}
+
 // packageHasRangedVersion checks if the package version is a ranged version
 // which we do not support for now.
 func packageHasRangedVersion(scannedPackage lockfile.PackageDetails) bool {
@@ -301,11 +469,11 @@ func sanitizeScannedPackages(scannedPackages []lockfile.PackageDetails) ([]lockf
 	return finalPackages, droppedReasons
 }
 
-// getDirectPackagePurls returns a list of PURLs for packages that are directly imported.
-func getDirectPackagePurls(scannedPackages []lockfile.PackageDetails) []string {
+// getPackagePurls returns the unique PURLs of the scanned packages, whether they are direct or transitive dependencies.
+func getPackagePurls(scannedPackages []lockfile.PackageDetails) []string {
 	uniquePurls := make(map[string]struct{})
 	for _, scannedPackage := range scannedPackages {
-		if scannedPackage.IsDirect {
+		if scannedPackage.PURL != "" {
 			uniquePurls[scannedPackage.PURL] = struct{}{}
 		}
 	}
@@ -4,11 +4,12 @@ import (
 	"testing"
 
 	"github.com/DataDog/datadog-sbom-generator/pkg/lockfile"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func Test_getDirectPackagePurls(t *testing.T) {
+func Test_getPackagePurls(t *testing.T) {
 	t.Parallel()
 
 	scannedPackages := []lockfile.PackageDetails{
@@ -29,14 +30,18 @@ func Test_getDirectPackagePurls(t *testing.T) {
 			PURL:     "pkg:maven/org.example/pkg3@3.0.0",
 			IsDirect: false,
 		},
+		{
+			// packages without PURL cannot be analyzed
+			Name: "pkg4",
+		},
 	}
 
-	directPurls := getDirectPackagePurls(scannedPackages)
+	purls := getPackagePurls(scannedPackages)
 
-	assert.Len(t, directPurls, 2)
-	assert.Contains(t, directPurls, "pkg:maven/org.example/pkg1@1.0.0")
-	assert.Contains(t, directPurls, "pkg:maven/org.example/pkg2@2.0.0")
-	assert.NotContains(t, directPurls, "pkg:maven/org.example/pkg3@3.0.0")
+	assert.Len(t, purls, 3)
+	assert.Contains(t, purls, "pkg:maven/org.example/pkg1@1.0.0")
+	assert.Contains(t, purls, "pkg:maven/org.example/pkg2@2.0.0")
+	assert.Contains(t, purls, "pkg:maven/org.example/pkg3@3.0.0")
 }
 
 const (
@@ -108,3 +113,85 @@ func Test_sanitizeScannedPackages_RangedVersionAreFiltered(t *testing.T) {
 	assert.Empty(t, sanitizedPackages)
 	assert.Len(t, errors, 3)
 }
+
+func Test_withoutOSPackageExtractors(t *testing.T) {
+	t.Parallel()
+
+	enabledParsers := withoutOSPackageExtractors(initializeEnabledParsers(nil))
+
+	assert.False(t, enabledParsers["var/lib/dpkg/status"])
+	assert.False(t, enabledParsers["lib/apk/db/installed"])
+	assert.True(t, enabledParsers["package-lock.json"])
+}
+
+func Test_exportDependencies(t *testing.T) {
+	t.Parallel()
+
+	child := lockfile.PackageDetails{Name: "child", Version: "1.0.0", Ecosystem: models.EcosystemNPM}
+	ranged := lockfile.PackageDetails{Name: "ranged", Version: InvalidRangedVersion2, Ecosystem: models.EcosystemNPM}
+	other := lockfile.PackageDetails{Name: "@scope/other", Version: "2.0.0", Ecosystem: models.EcosystemNPM}
+
+	parent := lockfile.PackageDetails{
+		Name:         "parent",
+		Version:      "1.0.0",
+		Ecosystem:    models.EcosystemNPM,
+		Dependencies: []*lockfile.PackageDetails{&child, &ranged, &other, &child},
+	}
+
+	assert.Equal(t, []string{"pkg:npm/%40scope%2Fother@2.0.0", "pkg:npm/child@1.0.0"}, exportDependencies(parent))
+	assert.Nil(t, exportDependencies(child))
+}
+
+func Test_findDependencyPaths(t *testing.T) {
+	t.Parallel()
+
+	source := models.SourceInfo{Path: "package-lock.json"}
+	newPackage := func(name string, isDirect bool) *lockfile.PackageDetails {
+		return &lockfile.PackageDetails{
+			Source:    source,
+			Name:      name,
+			Version:   "1.0.0",
+			Ecosystem: models.EcosystemNPM,
+			PURL:      "pkg:npm/" + name + "@1.0.0",
+			IsDirect:  isDirect,
+		}
+	}
+
+	// a -> b -> c and d -> c, while orphan is not introduced by any direct dependency
+	a, b, c, d, orphan := newPackage("a", true), newPackage("b", false), newPackage("c", false), newPackage("d", true), newPackage("orphan", false)
+	a.Dependencies = []*lockfile.PackageDetails{b}
+	b.Dependencies = []*lockfile.PackageDetails{c}
+	d.Dependencies = []*lockfile.PackageDetails{c}
+
+	paths := findDependencyPaths([]lockfile.PackageDetails{*a, *b, *c, *d, *orphan})
+
+	assert.Equal(t, map[models.SourceInfo]map[string][]string{
+		source: {
+			"pkg:npm/b@1.0.0": {"pkg:npm/a@1.0.0"},
+			"pkg:npm/c@1.0.0": {"pkg:npm/d@1.0.0"},
+		},
+	}, paths)
+}
+
+func Test_exportMetadata_TransitiveReachablePackage(t *testing.T) {
+	t.Parallel()
+
+	rawPkg := lockfile.PackageDetails{PURL: "pkg:npm/qs@6.7.0", PackageManager: models.NPM}
+	results := &models.ReachabilityAnalysisResults{
+		ReachableVulnerabilities: []models.ReachableVulnerability{
+			{
+				AdvisoryID: "GHSA-hrpp-h998-j3pp",
+				ReachableSymbolLocations: models.ReachableSymbolLocations{
+					{Symbol: "qs.parse", PackageLocation: models.PackageLocation{Filename: "src/query.js", LineStart: 4, LineEnd: 4, ColumnStart: 10, ColumnEnd: 18}},
+				},
+			},
+		},
+		AdvisoryIdsChecked: []string{"GHSA-hrpp-h998-j3pp"},
+	}
+
+	metadata := exportMetadata(rawPkg, results, []string{"pkg:npm/express@4.16.0", "pkg:npm/body-parser@1.19.0"})
+
+	assert.Contains(t, metadata, models.ReachableSymbolLocationMetadata.WithValue("GHSA-hrpp-h998-j3pp"))
+	assert.Equal(t, `["pkg:npm/express@4.16.0","pkg:npm/body-parser@1.19.0"]`, metadata[models.DependencyPathMetadata])
+	assert.NotContains(t, metadata, models.IsDirectDependencyMetadata)
+}
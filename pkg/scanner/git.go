@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"os"
+	"strings"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/gitrev"
+	"github.com/DataDog/datadog-sbom-generator/pkg/lockfile"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+	"github.com/DataDog/datadog-sbom-generator/pkg/reachability"
+	"github.com/DataDog/datadog-sbom-generator/pkg/reporter"
+)
+
+// scanGitRevision extracts the packages of the given revision of the repository containing dir, reading files
+// from the git object database rather than from the working tree. Only the files of dir are scanned, and paths
+// are reported relatively to dir.
+func scanGitRevision(r reporter.Reporter, dir string, revision string, recursive bool, enabledParsers map[string]bool) ([]lockfile.PackageDetails, []models.ScannedArtifact, error) {
+	tree, err := gitrev.Open(dir, revision)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files, err := tree.Files(recursive)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var scannedPackages []lockfile.PackageDetails
+	var scannedArtifacts []models.ScannedArtifact
+
+	for _, filePath := range files {
+		f, err := tree.Open(filePath)
+		if err != nil {
+			r.Infof("Failed to open %s: %v\n", filePath, err)
+			continue
+		}
+		extractor, _ := lockfile.FindExtractorForFile(f, enabledParsers)
+		f.Close()
+		if extractor == nil {
+			continue
+		}
+
+		pkgs, artifact, err := scanLockfile(r, filePath, tree.Open, enabledParsers)
+		if err != nil {
+			r.Warnf("Attempted to scan lockfile but failed: %s (%v)\n", filePath, err.Error())
+		}
+		scannedPackages = append(scannedPackages, pkgs...)
+		if artifact != nil {
+			scannedArtifacts = append(scannedArtifacts, *artifact)
+		}
+	}
+
+	relativizePaths(scannedPackages, scannedArtifacts, func(path string) string {
+		return strings.TrimPrefix(strings.TrimPrefix(path, tree.Dir), "/")
+	})
+
+	return scannedPackages, scannedArtifacts, nil
+}
+
+// collectGitRevisionSourceFiles collects the source files of the given revision for the reachability analysis.
+// As detectors read the other files of their project from the filesystem, the files of the revision read by the analysis
+// are exported to a temporary directory, which is returned so that it is removed once the analysis is done.
+// The other files, such as assets or documentation, are not written, the disk space taken being the size of the source
+// files and of their project files.
+func collectGitRevisionSourceFiles(r reporter.Reporter, dir string, revision string, recursive bool, useGitIgnore bool, sourceFiles *reachability.SourceFileCollector) (string, error) {
+	tree, err := gitrev.Open(dir, revision)
+	if err != nil {
+		return "", err
+	}
+
+	exportDir, err := os.MkdirTemp("", "datadog-sbom-generator-git-*")
+	if err != nil {
+		return "", err
+	}
+	if err := tree.Export(exportDir, recursive, reachability.IsAnalyzedFile); err != nil {
+		return exportDir, err
+	}
+
+	return exportDir, walkDir(r, exportDir, recursive, useGitIgnore, false, func(path string, _ string, info os.DirEntry) {
+		sourceFiles.Visit(exportDir, path, info)
+	})
+}
@@ -0,0 +1,101 @@
+package scanner
+
+import (
+	"io"
+	"path"
+
+	"github.com/DataDog/datadog-sbom-generator/internal/image"
+	"github.com/DataDog/datadog-sbom-generator/pkg/lockfile"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+	"github.com/DataDog/datadog-sbom-generator/pkg/reporter"
+)
+
+// scanImage extracts the packages of the container image stored at the given path, either a `docker save` archive
+// or an OCI image layout. Each package records the digest of the layer which introduced it.
+func scanImage(r reporter.Reporter, imagePath string, enabledParsers map[string]bool) ([]lockfile.PackageDetails, []models.ScannedArtifact, error) {
+	img, err := image.Load(imagePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer img.Close()
+
+	fsys := img.FileSystem
+
+	var scannedPackages []lockfile.PackageDetails
+	var scannedArtifacts []models.ScannedArtifact
+
+	for _, filePath := range fsys.Files() {
+		f, err := fsys.Open(filePath)
+		if err != nil {
+			r.Infof("Failed to open %s: %v\n", filePath, err)
+			continue
+		}
+		extractor, _ := lockfile.FindExtractorForFile(f, enabledParsers)
+		f.Close()
+		if extractor == nil {
+			continue
+		}
+
+		pkgs, artifact, err := scanLockfile(r, filePath, fsys.Open, enabledParsers)
+		if err != nil {
+			r.Warnf("Attempted to scan lockfile but failed: %s (%v)\n", filePath, err.Error())
+		}
+		setLayerDigests(fsys, filePath, extractor, pkgs)
+
+		scannedPackages = append(scannedPackages, pkgs...)
+		if artifact != nil {
+			scannedArtifacts = append(scannedArtifacts, *artifact)
+		}
+	}
+
+	applyDistribution(r, imagePath, func(name string) (io.ReadCloser, error) {
+		return fsys.Open(path.Join("/", name))
+	}, scannedPackages)
+
+	return scannedPackages, scannedArtifacts, nil
+}
+
+// setLayerDigests records in each package the layer which introduced it. As a layer rewriting a lockfile replaces
+// it entirely, the previous versions of the file are extracted to find the oldest layer since which the package
+// has always been present.
+func setLayerDigests(fsys *image.FileSystem, filePath string, extractor lockfile.Extractor, packages []lockfile.PackageDetails) {
+	versions, err := fsys.History(filePath)
+	if err != nil || len(versions) == 0 {
+		return
+	}
+
+	introducedBy := make(map[string]string, len(packages))
+	// pending lists the packages present in all the versions walked so far, from the newest one
+	pending := make(map[string]bool, len(packages))
+	for _, pkg := range packages {
+		introducedBy[packageKey(pkg)] = versions[len(versions)-1].Layer()
+		pending[packageKey(pkg)] = true
+	}
+
+	for index := len(versions) - 2; index >= 0 && len(pending) > 0; index-- {
+		pkgs, err := extractor.Extract(versions[index])
+		if err != nil {
+			break
+		}
+
+		present := make(map[string]bool, len(pkgs))
+		for _, pkg := range pkgs {
+			present[packageKey(pkg)] = true
+		}
+		for key := range pending {
+			if present[key] {
+				introducedBy[key] = versions[index].Layer()
+			} else {
+				delete(pending, key)
+			}
+		}
+	}
+
+	for index, pkg := range packages {
+		packages[index].LayerDigest = introducedBy[packageKey(pkg)]
+	}
+}
+
+func packageKey(pkg lockfile.PackageDetails) string {
+	return pkg.Name + "@" + pkg.Version
+}
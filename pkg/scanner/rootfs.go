@@ -0,0 +1,148 @@
+package scanner
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/lockfile"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+	"github.com/DataDog/datadog-sbom-generator/pkg/reporter"
+)
+
+// osReleasePaths are the locations of the os-release file, relative to the root filesystem
+// See: https://www.freedesktop.org/software/systemd/man/latest/os-release.html
+var osReleasePaths = []string{"etc/os-release", "usr/lib/os-release"}
+
+var errOSReleaseNotFound = errors.New("no os-release file found")
+
+// osRelease identifies the distribution of a root filesystem
+type osRelease struct {
+	ID        string
+	IDLike    []string
+	VersionID string
+}
+
+// fileOpener opens a file of a root filesystem, from its path relative to the root
+type fileOpener func(path string) (io.ReadCloser, error)
+
+// rootFSOpener opens the regular files of the root filesystem extracted in the given directory
+func rootFSOpener(root string) fileOpener {
+	return func(path string) (io.ReadCloser, error) {
+		path = filepath.Join(root, path)
+
+		// Absolute links, such as /etc/os-release -> /usr/lib/os-release, would be resolved against the host
+		info, err := os.Lstat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.Mode().IsRegular() {
+			return nil, fmt.Errorf("%s is not a regular file", path)
+		}
+
+		return os.Open(path)
+	}
+}
+
+// readOSRelease reads the os-release file of a root filesystem
+func readOSRelease(open fileOpener) (osRelease, error) {
+	for _, path := range osReleasePaths {
+		file, err := open(path)
+		if err != nil {
+			continue
+		}
+		defer file.Close()
+
+		return parseOSRelease(bufio.NewScanner(file))
+	}
+
+	return osRelease{}, errOSReleaseNotFound
+}
+
+func parseOSRelease(scanner *bufio.Scanner) (osRelease, error) {
+	var release osRelease
+
+	for scanner.Scan() {
+		key, value, found := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !found || strings.HasPrefix(key, "#") {
+			continue
+		}
+		value = strings.Trim(value, `"'`)
+
+		switch key {
+		case "ID":
+			release.ID = strings.ToLower(value)
+		case "ID_LIKE":
+			release.IDLike = strings.Fields(strings.ToLower(value))
+		case "VERSION_ID":
+			release.VersionID = value
+		}
+	}
+
+	return release, scanner.Err()
+}
+
+// ecosystem returns the ecosystem, including the release, of the packages installed by the distribution.
+// An empty ecosystem is returned if the distribution is not supported or its version is unknown.
+func (release osRelease) ecosystem() models.Ecosystem {
+	if release.VersionID == "" {
+		return ""
+	}
+
+	for _, id := range append([]string{release.ID}, release.IDLike...) {
+		switch id {
+		case "debian":
+			// Debian advisories are published against the major version
+			major, _, _ := strings.Cut(release.VersionID, ".")
+			return models.Ecosystem(fmt.Sprintf("%s:%s", models.EcosystemDebian, major))
+		case "ubuntu":
+			return models.Ecosystem(fmt.Sprintf("%s:%s", models.EcosystemUbuntu, release.VersionID))
+		case "alpine":
+			// Alpine advisories are published against the major and minor version, e.g. 3.20.0 -> v3.20
+			parts := strings.SplitN(release.VersionID, ".", 3)
+			return models.Ecosystem(fmt.Sprintf("%s:v%s", models.EcosystemAlpine, strings.Join(parts[:min(len(parts), 2)], ".")))
+		}
+	}
+
+	return ""
+}
+
+// applyDistribution sets the ecosystem of the OS packages found in the root filesystem named root, using the
+// distribution described by its os-release file rather than the release guessed by the extractors
+func applyDistribution(r reporter.Reporter, root string, open fileOpener, packages []lockfile.PackageDetails) {
+	release, err := readOSRelease(open)
+	if err != nil {
+		r.Warnf("Unable to detect the distribution of %s: %v\n", root, err)
+		return
+	}
+
+	ecosystem := release.ecosystem()
+	if ecosystem == "" {
+		r.Infof("Unsupported distribution %q (version %q) in %s\n", release.ID, release.VersionID, root)
+		return
+	}
+
+	for index, pkg := range packages {
+		if osPackageFamily(pkg.Ecosystem) != "" && osPackageFamily(pkg.Ecosystem) == osPackageFamily(ecosystem) {
+			packages[index].Ecosystem = ecosystem
+		}
+	}
+}
+
+// osPackageFamily returns the package manager installing the packages of the given ecosystem,
+// or an empty string for ecosystems which are not the one of a distribution
+func osPackageFamily(ecosystem models.Ecosystem) string {
+	base, _, _ := strings.Cut(string(ecosystem), ":")
+	switch models.Ecosystem(base) {
+	case models.EcosystemDebian, models.EcosystemUbuntu:
+		return "dpkg"
+	case models.EcosystemAlpine:
+		return "apk"
+	}
+
+	return ""
+}
@@ -0,0 +1,68 @@
+package scanner
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/datadog-sbom-generator/pkg/lockfile"
+	"github.com/DataDog/datadog-sbom-generator/pkg/models"
+	"github.com/DataDog/datadog-sbom-generator/pkg/reporter"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseOSRelease(t *testing.T) {
+	t.Parallel()
+
+	release, err := parseOSRelease(bufio.NewScanner(strings.NewReader(`# comment
+NAME="Ubuntu"
+VERSION_ID="22.04"
+ID=ubuntu
+ID_LIKE=debian
+`)))
+	require.NoError(t, err)
+	assert.Equal(t, osRelease{ID: "ubuntu", IDLike: []string{"debian"}, VersionID: "22.04"}, release)
+}
+
+func Test_osRelease_ecosystem(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		release  osRelease
+		expected models.Ecosystem
+	}{
+		{release: osRelease{ID: "debian", VersionID: "12"}, expected: "Debian:12"},
+		{release: osRelease{ID: "debian", VersionID: "11.9"}, expected: "Debian:11"},
+		{release: osRelease{ID: "ubuntu", IDLike: []string{"debian"}, VersionID: "22.04"}, expected: "Ubuntu:22.04"},
+		{release: osRelease{ID: "raspbian", IDLike: []string{"debian"}, VersionID: "12"}, expected: "Debian:12"},
+		{release: osRelease{ID: "alpine", VersionID: "3.19.1"}, expected: "Alpine:v3.19"},
+		{release: osRelease{ID: "alpine", VersionID: "3"}, expected: "Alpine:v3"},
+		{release: osRelease{ID: "debian"}, expected: ""},
+		{release: osRelease{ID: "fedora", VersionID: "40"}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tt.release.ecosystem(), tt.release)
+	}
+}
+
+func Test_applyDistribution(t *testing.T) {
+	t.Parallel()
+
+	packages := []lockfile.PackageDetails{
+		{Name: "bash", Ecosystem: "Debian:12ubuntu4"},
+		{Name: "busybox", Ecosystem: models.EcosystemAlpine},
+		{Name: "lodash", Ecosystem: models.EcosystemNPM},
+	}
+
+	root := "../../cmd/datadog-sbom-generator/fixtures/rootfs-debian"
+	applyDistribution(&reporter.VoidReporter{}, root, rootFSOpener(root), packages)
+
+	assert.Equal(t, []lockfile.PackageDetails{
+		{Name: "bash", Ecosystem: "Debian:12"},
+		{Name: "busybox", Ecosystem: models.EcosystemAlpine},
+		{Name: "lodash", Ecosystem: models.EcosystemNPM},
+	}, packages)
+}
@@ -1,18 +1,26 @@
 package scanner
 
 import (
+	"encoding/json"
 	"log"
+	"maps"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/DataDog/datadog-sbom-generator/pkg/lockfile"
 
+	"github.com/DataDog/datadog-sbom-generator/internal/local"
 	"github.com/DataDog/datadog-sbom-generator/internal/utility/fileposition"
 	"github.com/DataDog/datadog-sbom-generator/internal/utility/location"
+	"github.com/DataDog/datadog-sbom-generator/internal/utility/purl"
 	"github.com/DataDog/datadog-sbom-generator/pkg/models"
 	"github.com/DataDog/datadog-sbom-generator/pkg/reporter"
 )
 
-func exportMetadata(rawPkg lockfile.PackageDetails, reachabilityAnalysisResults *models.ReachabilityAnalysisResults) map[models.PackageMetadataType]string {
+// exportMetadata returns the metadata of a package. The reachable symbols of transitive packages are reported
+// along with the path of dependencies introducing them, when the lockfile records it.
+func exportMetadata(rawPkg lockfile.PackageDetails, reachabilityAnalysisResults *models.ReachabilityAnalysisResults, dependencyPath []string) map[models.PackageMetadataType]string {
 	metadata := make(map[models.PackageMetadataType]string)
 
 	if len(rawPkg.PackageManager) > 0 && rawPkg.PackageManager != models.Unknown {
@@ -24,32 +32,129 @@ func exportMetadata(rawPkg lockfile.PackageDetails, reachabilityAnalysisResults
 	if rawPkg.Ecosystem.IsDevGroup(rawPkg.DepGroups) {
 		metadata[models.IsDevDependencyMetadata] = strconv.FormatBool(true)
 	}
+	if rawPkg.LayerDigest != "" {
+		metadata[models.LayerDigestMetadata] = rawPkg.LayerDigest
+	}
 	if reachabilityAnalysisResults != nil {
-		if !rawPkg.IsDirect && len(reachabilityAnalysisResults.ReachableVulnerabilities) > 0 {
-			log.Printf("transitive package (%s) with reachable symbols was dropped\n", rawPkg.PURL)
-		} else {
-			for _, vuln := range reachabilityAnalysisResults.ReachableVulnerabilities {
-				key := models.ReachableSymbolLocationMetadata.WithValue(vuln.AdvisoryID)
-				val, err := vuln.ReachableSymbolLocations.MarshalToJSONString()
-				if err != nil {
-					log.Printf("failed to marshal reachable symbol locations into a JSON string: %s\n", err)
+		for _, vuln := range reachabilityAnalysisResults.ReachableVulnerabilities {
+			key := models.ReachableSymbolLocationMetadata.WithValue(vuln.AdvisoryID)
+			val, err := vuln.ReachableSymbolLocations.MarshalToJSONString()
+			if err != nil {
+				log.Printf("failed to marshal reachable symbol locations into a JSON string: %s\n", err)
+				continue
+			}
+			metadata[key] = val
+		}
+
+		if !rawPkg.IsDirect && len(reachabilityAnalysisResults.ReachableVulnerabilities) > 0 && len(dependencyPath) > 0 {
+			val, err := json.Marshal(dependencyPath)
+			if err != nil {
+				log.Printf("failed to marshal the dependency path of %s into a JSON string: %s\n", rawPkg.PURL, err)
+			} else {
+				metadata[models.DependencyPathMetadata] = string(val)
+			}
+		}
+	}
+
+	return metadata
+}
+
+// exportDependencies returns the sorted PURLs of the packages the given package directly depends on.
+// Dependencies which would have been dropped by sanitizeScannedPackages are ignored.
+func exportDependencies(rawPkg lockfile.PackageDetails) []string {
+	if len(rawPkg.Dependencies) == 0 {
+		return nil
+	}
+
+	dependencies := make([]string, 0, len(rawPkg.Dependencies))
+	for _, dependency := range rawPkg.Dependencies {
+		if dependency == nil || packageHasRangedVersion(*dependency) {
+			continue
+		}
+		packageURL, err := purl.FromNameVersionEcosystem(dependency.Name, dependency.Version, string(dependency.Ecosystem))
+		if err != nil {
+			continue
+		}
+		dependencies = append(dependencies, packageURL.ToString())
+	}
+	slices.Sort(dependencies)
+
+	return slices.Compact(dependencies)
+}
+
+// findDependencyPaths returns the shortest path of dependencies leading to each transitive package of each source,
+// from a direct dependency to the parent of the package. Paths are made of PURLs, and their ties are broken by the order
+// of the PURLs, so that they are stable between scans. Transitive packages no direct dependency leads to are omitted.
+func findDependencyPaths(packages []lockfile.PackageDetails) map[models.SourceInfo]map[string][]string {
+	type graph struct {
+		direct   map[string]bool
+		children map[string][]string
+	}
+	graphs := make(map[models.SourceInfo]*graph)
+	for _, p := range packages {
+		if p.PURL == "" {
+			continue
+		}
+		g, ok := graphs[p.Source]
+		if !ok {
+			g = &graph{direct: make(map[string]bool), children: make(map[string][]string)}
+			graphs[p.Source] = g
+		}
+		g.direct[p.PURL] = g.direct[p.PURL] || p.IsDirect
+		g.children[p.PURL] = append(g.children[p.PURL], exportDependencies(p)...)
+	}
+
+	paths := make(map[models.SourceInfo]map[string][]string, len(graphs))
+	for source, g := range graphs {
+		parents := make(map[string]string)
+		var queue []string
+		for _, purl := range slices.Sorted(maps.Keys(g.children)) {
+			if g.direct[purl] {
+				parents[purl] = ""
+				queue = append(queue, purl)
+			}
+		}
+
+		// Breadth-first search from all the direct dependencies at once
+		for len(queue) > 0 {
+			parent := queue[0]
+			queue = queue[1:]
+
+			children := slices.Clone(g.children[parent])
+			slices.Sort(children)
+			for _, child := range slices.Compact(children) {
+				if _, visited := parents[child]; visited {
 					continue
 				}
-				metadata[key] = val
+				parents[child] = parent
+				queue = append(queue, child)
+			}
+		}
+
+		paths[source] = make(map[string][]string)
+		for purl, parent := range parents {
+			var path []string
+			for ; parent != ""; parent = parents[parent] {
+				path = append(path, parent)
+			}
+			if len(path) > 0 {
+				slices.Reverse(path)
+				paths[source][purl] = path
 			}
 		}
 	}
 
-	return metadata
+	return paths
 }
 
 // grouped by source location.
-func groupBySource(r reporter.Reporter, packages []lockfile.PackageDetails, artifacts []models.ScannedArtifact, reachabilityAnalysis models.ReachabilityAnalysis) models.VulnerabilityResults {
+func groupBySource(r reporter.Reporter, packages []lockfile.PackageDetails, artifacts []models.ScannedArtifact, reachabilityAnalysis models.ReachabilityAnalysis, vulnerabilityDB *local.DB) models.VulnerabilityResults {
 	output := models.VulnerabilityResults{
 		Results:   []models.PackageSource{},
 		Artifacts: artifacts,
 	}
 	groupedBySource := map[models.SourceInfo][]models.PackageVulns{}
+	dependencyPaths := findDependencyPaths(packages)
 
 	for _, p := range packages {
 		var pkg models.PackageVulns
@@ -60,9 +165,12 @@ func groupBySource(r reporter.Reporter, packages []lockfile.PackageDetails, arti
 					Name:      p.Name,
 					Version:   p.Version,
 					Ecosystem: string(p.Ecosystem),
+					Commit:    p.Commit,
 					Purl:      p.PURL,
+					Hashes:    p.Hashes,
 				},
-				Metadata: exportMetadata(p, reachabilityAnalysis.PurlToReachabilityAnalysisResults[p.PURL]),
+				Metadata:     exportMetadata(p, reachabilityAnalysis.PurlToReachabilityAnalysisResults[p.PURL], dependencyPaths[p.Source][p.PURL]),
+				Dependencies: exportDependencies(p),
 			}
 		case p.Commit != "":
 			pkg.Package.Version = p.Commit
@@ -72,7 +180,7 @@ func groupBySource(r reporter.Reporter, packages []lockfile.PackageDetails, arti
 			continue
 		}
 
-		pkg.Vulnerabilities = nil
+		pkg.Vulnerabilities = vulnerabilityDB.VulnerabilitiesAffecting(p)
 		if fileposition.IsFilePositionExtractedSuccessfully(p.BlockLocation) {
 			pkg.Locations = make([]models.PackageLocations, 1)
 			pkg.Locations[0] = location.NewPackageLocations(p.BlockLocation, p.NameLocation, p.VersionLocation)
@@ -92,6 +200,9 @@ func groupBySource(r reporter.Reporter, packages []lockfile.PackageDetails, arti
 			Packages: packages,
 		})
 	}
+	slices.SortFunc(output.Results, func(a, b models.PackageSource) int {
+		return strings.Compare(a.Source.Path, b.Source.Path)
+	})
 
 	return output
 }